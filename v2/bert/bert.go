@@ -0,0 +1,346 @@
+// Package bert provides BERT (Binary ERlang Term) and BERT-RPC support
+package bert
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+const (
+	atomBert  = "bert"
+	atomNil   = "nil"
+	atomTrue  = "true"
+	atomFalse = "false"
+	atomDict  = "dict"
+	atomTime  = "time"
+	atomRegex = "regex"
+)
+
+// ErrComplex is returned when a {bert, ...} tuple is invalid
+var ErrComplex = errors.New("invalid BERT complex type")
+
+// Dict is a BERT dictionary, encoded as {bert, dict, [{Key, Value}, ...]}
+type Dict []DictEntry
+
+// DictEntry is a single Dict key/value pair
+type DictEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Regex is a BERT regular expression, encoded as
+// {bert, regex, Source, Options} for use with the Erlang re module
+type Regex struct {
+	Source  string
+	Options []interface{}
+}
+
+// Marshal encodes a Go value as BERT
+func Marshal(term interface{}) ([]byte, error) {
+	return erlang.TermToBinary(Encode(term), -1)
+}
+
+// Unmarshal decodes BERT as a Go value
+func Unmarshal(data []byte) (interface{}, error) {
+	term, err := erlang.BinaryToTerm(data)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(term)
+}
+
+// Encode converts the BERT complex types of a Go value into
+// {bert, ...} tuples (nil, bool, Dict, time.Time and Regex),
+// within tuples, lists (including an improper tail) and maps
+// (including keys, though erlang.BinaryToTerm rejects tuple map keys)
+func Encode(termI interface{}) interface{} {
+	switch term := termI.(type) {
+	case nil:
+		return complexType(atomNil)
+	case bool:
+		if term {
+			return complexType(atomTrue)
+		}
+		return complexType(atomFalse)
+	case Dict:
+		entries := make([]interface{}, len(term))
+		for i, entry := range term {
+			entries[i] = erlang.OtpErlangTuple{
+				Encode(entry.Key), Encode(entry.Value)}
+		}
+		return complexType(atomDict, erlang.OtpErlangList{Value: entries})
+	case time.Time:
+		// floor division keeps Sec and Micro non-negative before 1970
+		seconds := term.Unix()
+		megaSeconds := seconds / 1000000
+		if seconds%1000000 < 0 {
+			megaSeconds -= 1
+		}
+		return complexType(atomTime, int(megaSeconds),
+			int(seconds-megaSeconds*1000000), term.Nanosecond()/1000)
+	case Regex:
+		options := make([]interface{}, len(term.Options))
+		for i, option := range term.Options {
+			options[i] = Encode(option)
+		}
+		return complexType(atomRegex,
+			erlang.OtpErlangBinary{Value: []byte(term.Source), Bits: 8},
+			erlang.OtpErlangList{Value: options})
+	case erlang.OtpErlangTuple:
+		tuple := make(erlang.OtpErlangTuple, len(term))
+		for i, element := range term {
+			tuple[i] = Encode(element)
+		}
+		return tuple
+	case []interface{}:
+		list := make([]interface{}, len(term))
+		for i, element := range term {
+			list[i] = Encode(element)
+		}
+		return list
+	case erlang.OtpErlangList:
+		list := make([]interface{}, len(term.Value))
+		for i, element := range term.Value {
+			list[i] = Encode(element)
+		}
+		return erlang.OtpErlangList{Value: list, Improper: term.Improper}
+	case erlang.OtpErlangMap:
+		return encodeMap(term)
+	case map[interface{}]interface{}:
+		return encodeMap(term)
+	default:
+		return termI
+	}
+}
+
+// Decode converts {bert, ...} tuples into Go values
+// (nil, bool, Dict, time.Time and Regex), within tuples, lists and maps
+// (including keys), returning ErrComplex for a key that is not comparable
+// (the undefined atom is decoded as nil by erlang.BinaryToTerm)
+func Decode(termI interface{}) (interface{}, error) {
+	switch term := termI.(type) {
+	case erlang.OtpErlangTuple:
+		if len(term) >= 2 && isAtom(term[0], atomBert) {
+			return decodeComplex(term)
+		}
+		tuple := make(erlang.OtpErlangTuple, len(term))
+		for i, element := range term {
+			value, err := Decode(element)
+			if err != nil {
+				return nil, err
+			}
+			tuple[i] = value
+		}
+		return tuple, nil
+	case erlang.OtpErlangList:
+		list := make([]interface{}, len(term.Value))
+		for i, element := range term.Value {
+			value, err := Decode(element)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return erlang.OtpErlangList{Value: list, Improper: term.Improper}, nil
+	case erlang.OtpErlangMap:
+		value := make(erlang.OtpErlangMap, len(term))
+		for key, element := range term {
+			key, err := Decode(key)
+			if err != nil {
+				return nil, err
+			}
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				return nil, ErrComplex
+			}
+			element, err := Decode(element)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = element
+		}
+		return value, nil
+	case complexKey:
+		return term.key, nil
+	default:
+		return termI, nil
+	}
+}
+
+// complexKey is a map key that encodes as a {bert, ...} tuple
+// (a tuple is not comparable, so it is unable to be a Go map key)
+type complexKey struct {
+	key interface{}
+}
+
+// MarshalErlang provides the {bert, ...} tuple of the key
+func (k complexKey) MarshalErlang() (interface{}, error) {
+	return Encode(k.key), nil
+}
+
+func encodeMap(term map[interface{}]interface{}) erlang.OtpErlangMap {
+	value := make(erlang.OtpErlangMap, len(term))
+	for key, element := range term {
+		keyEncoded := Encode(key)
+		if keyEncoded != nil && !reflect.TypeOf(keyEncoded).Comparable() {
+			keyEncoded = complexKey{key}
+		}
+		value[keyEncoded] = Encode(element)
+	}
+	return value
+}
+
+func decodeComplex(term erlang.OtpErlangTuple) (interface{}, error) {
+	switch {
+	case len(term) == 2 && isAtom(term[1], atomNil):
+		return nil, nil
+	case len(term) == 2 && isAtom(term[1], atomTrue):
+		return true, nil
+	case len(term) == 2 && isAtom(term[1], atomFalse):
+		return false, nil
+	case len(term) == 3 && isAtom(term[1], atomDict):
+		entries, ok := listElements(term[2])
+		if !ok {
+			return nil, ErrComplex
+		}
+		dict := make(Dict, len(entries))
+		for i, entryI := range entries {
+			entry, ok := entryI.(erlang.OtpErlangTuple)
+			if !ok || len(entry) != 2 {
+				return nil, ErrComplex
+			}
+			key, err := Decode(entry[0])
+			if err != nil {
+				return nil, err
+			}
+			value, err := Decode(entry[1])
+			if err != nil {
+				return nil, err
+			}
+			dict[i] = DictEntry{Key: key, Value: value}
+		}
+		return dict, nil
+	case len(term) == 5 && isAtom(term[1], atomTime):
+		megaSeconds, ok1 := integer(term[2])
+		seconds, ok2 := integer(term[3])
+		microSeconds, ok3 := integer(term[4])
+		if !ok1 || !ok2 || !ok3 {
+			return nil, ErrComplex
+		}
+		return time.Unix(megaSeconds*1000000+seconds,
+			microSeconds*1000).UTC(), nil
+	case len(term) == 4 && isAtom(term[1], atomRegex):
+		var source string
+		switch value := term[2].(type) {
+		case erlang.OtpErlangBinary:
+			source = string(value.Value)
+		case string:
+			source = value
+		default:
+			return nil, ErrComplex
+		}
+		options, ok := listElements(term[3])
+		if !ok {
+			return nil, ErrComplex
+		}
+		for i, option := range options {
+			value, err := Decode(option)
+			if err != nil {
+				return nil, err
+			}
+			options[i] = value
+		}
+		return Regex{Source: source, Options: options}, nil
+	default:
+		return nil, ErrComplex
+	}
+}
+
+func complexType(name string, values ...interface{}) erlang.OtpErlangTuple {
+	tuple := erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom(atomBert), erlang.OtpErlangAtom(name)}
+	return append(tuple, values...)
+}
+
+func isAtom(term interface{}, name string) bool {
+	switch value := term.(type) {
+	case erlang.OtpErlangAtom:
+		return string(value) == name
+	case erlang.OtpErlangAtomUTF8:
+		return string(value) == name
+	case bool:
+		return (value && name == atomTrue) || (!value && name == atomFalse)
+	case nil:
+		// the nil atom may be the undefined atom (erlang.SetUndefined)
+		return name == atomNil
+	default:
+		return false
+	}
+}
+
+// listElements provides a copy of the elements of a proper list
+// (a string is a list of bytes)
+func listElements(term interface{}) ([]interface{}, bool) {
+	switch value := term.(type) {
+	case erlang.OtpErlangList:
+		if value.Improper {
+			return nil, false
+		}
+		return append([]interface{}{}, value.Value...), true
+	case string:
+		elements := make([]interface{}, len(value))
+		for i := 0; i < len(value); i++ {
+			elements[i] = value[i]
+		}
+		return elements, true
+	default:
+		return nil, false
+	}
+}
+
+func integer(term interface{}) (int64, bool) {
+	switch value := term.(type) {
+	case uint8:
+		return int64(value), true
+	case int32:
+		return int64(value), true
+	case int64:
+		return value, true
+	case int:
+		return int64(value), true
+	case *big.Int:
+		if !value.IsInt64() {
+			return 0, false
+		}
+		return value.Int64(), true
+	default:
+		return 0, false
+	}
+}
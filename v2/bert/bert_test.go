@@ -0,0 +1,127 @@
+package bert
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	// {bert, nil}
+	data, err := Marshal(nil)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x83h\x02s\x04berts\x03nil", string(data), "")
+	// [{bert, true}, {bert, false}]
+	data, err = Marshal(erlang.OtpErlangList{Value: []interface{}{true, false}})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x83l\x00\x00\x00\x02h\x02s\x04berts\x04true"+
+		"h\x02s\x04berts\x05falsej", string(data), "")
+
+	// {bert, time, 1255, 295581, 446228}
+	now := time.Unix(1255295581, 446228000).UTC()
+	dict := Dict{
+		{Key: erlang.OtpErlangAtom("name"),
+			Value: erlang.OtpErlangBinary{Value: []byte("Tom"), Bits: 8}},
+		{Key: erlang.OtpErlangAtom("time"), Value: now},
+	}
+	regex := Regex{Source: "^c(a*)t$",
+		Options: []interface{}{erlang.OtpErlangAtom("caseless")}}
+	data, err = Marshal(erlang.OtpErlangTuple{dict, regex})
+	assertEqual(t, nil, err, "")
+	term, err := erlang.BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	tuple := term.(erlang.OtpErlangTuple)
+	assertEqual(t, erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("bert"), erlang.OtpErlangAtom("time"),
+		int32(1255), int32(295581), int32(446228)},
+		tuple[0].(erlang.OtpErlangTuple)[2].(erlang.OtpErlangList).Value[1].(erlang.OtpErlangTuple)[1], "")
+	result, err := Unmarshal(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, erlang.OtpErlangTuple{dict, regex}, result, "")
+
+	// {bert, time, -1, 999999, 500000} before 1970
+	before := time.Unix(-1, 500000000).UTC()
+	assertEqual(t, erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("bert"), erlang.OtpErlangAtom("time"),
+		-1, 999999, 500000}, Encode(before), "")
+	data, err = Marshal(before)
+	assertEqual(t, nil, err, "")
+	result, err = Unmarshal(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, before, result, "")
+
+	// [1 | {bert, true}]
+	improper := erlang.OtpErlangList{Value: []interface{}{uint8(1), true},
+		Improper: true}
+	data, err = Marshal(improper)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x83l\x00\x00\x00\x01a\x01h\x02s\x04berts\x04true",
+		string(data), "")
+	result, err = Unmarshal(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, improper, result, "")
+
+	// #{{bert, true} => 1}
+	keys := erlang.OtpErlangMap{true: uint8(1)}
+	data, err = Marshal(keys)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x83t\x00\x00\x00\x01h\x02s\x04berts\x04truea\x01",
+		string(data), "")
+	result, err = Decode(Encode(erlang.OtpErlangMap{true: uint8(1), nil: now}))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, erlang.OtpErlangMap{true: uint8(1), nil: now}, result, "")
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	invalid := []interface{}{
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("bert"), erlang.OtpErlangAtom("unknown")},
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("bert"), erlang.OtpErlangAtom("dict"), uint8(1)},
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("bert"), erlang.OtpErlangAtom("time"),
+			uint8(1), uint8(2), erlang.OtpErlangAtom("now")},
+		erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangTuple{erlang.OtpErlangAtom("bert"), erlang.OtpErlangAtom("regex"),
+				uint8(1), erlang.OtpErlangList{}}}},
+	}
+	for _, term := range invalid {
+		_, err := Decode(term)
+		assertEqual(t, ErrComplex, err, "")
+	}
+}
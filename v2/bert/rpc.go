@@ -0,0 +1,368 @@
+package bert
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+	"github.com/okeuday/erlang_go/v2/port"
+)
+
+// BERT-RPC error types
+const (
+	ErrorProtocol = "protocol"
+	ErrorServer   = "server"
+	ErrorUser     = "user"
+	ErrorProxy    = "proxy"
+)
+
+// BERT-RPC protocol and server error codes
+const (
+	CodeUndesignated   = 0
+	CodeReadHeader     = 1
+	CodeReadData       = 2
+	CodeNoSuchModule   = 1
+	CodeNoSuchFunction = 2
+)
+
+const (
+	packet               = 4
+	atomCall             = "call"
+	atomCast             = "cast"
+	atomReply            = "reply"
+	atomNoReply          = "noreply"
+	atomError            = "error"
+	atomInfo             = "info"
+	classBERTError       = "BERTError"
+	classUserError       = "UserError"
+	detailNoSuchModule   = "no such module"
+	detailNoSuchFunction = "no such function"
+)
+
+// ErrResponse is returned when a BERT-RPC response is invalid
+var ErrResponse = errors.New("invalid BERT-RPC response")
+
+// Error is a BERT-RPC error response
+// {error, {Type, Code, Class, Detail, Backtrace}}
+type Error struct {
+	Type      string
+	Code      int
+	Class     string
+	Detail    string
+	Backtrace []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("BERT-RPC %s error %d: %s: %s",
+		e.Type, e.Code, e.Class, e.Detail)
+}
+
+func (e *Error) term() erlang.OtpErlangTuple {
+	backtrace := make([]interface{}, len(e.Backtrace))
+	for i, line := range e.Backtrace {
+		backtrace[i] = erlang.OtpErlangBinary{Value: []byte(line), Bits: 8}
+	}
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom(atomError),
+		erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom(e.Type),
+			e.Code,
+			erlang.OtpErlangBinary{Value: []byte(e.Class), Bits: 8},
+			erlang.OtpErlangBinary{Value: []byte(e.Detail), Bits: 8},
+			erlang.OtpErlangList{Value: backtrace},
+		}}
+}
+
+// Client is a BERT-RPC client
+type Client struct {
+	mutex  sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	info   []interface{}
+}
+
+// NewClient creates a BERT-RPC client on an existing connection
+func NewClient(conn net.Conn) *Client {
+	return &Client{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// Dial connects a BERT-RPC client to a TCP address
+func Dial(address string) (*Client, error) {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// Info sends {info, Command, Options} before the next call or cast
+func (c *Client) Info(command string, options []interface{}) {
+	c.mutex.Lock()
+	c.info = append(c.info, erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom(atomInfo), erlang.OtpErlangAtom(command),
+		Encode(erlang.OtpErlangList{Value: options})})
+	c.mutex.Unlock()
+}
+
+// Call sends {call, Module, Function, Arguments} and provides the
+// Result of the {reply, Result} response
+func (c *Client) Call(module, function string, args ...interface{}) (interface{}, error) {
+	return c.request(atomCall, atomReply, module, function, args)
+}
+
+// Cast sends {cast, Module, Function, Arguments} and waits for the
+// {noreply} response
+func (c *Client) Cast(module, function string, args ...interface{}) error {
+	_, err := c.request(atomCast, atomNoReply, module, function, args)
+	return err
+}
+
+// Close closes the client connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) request(name, response, module, function string,
+	args []interface{}) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	info := c.info
+	c.info = nil
+	for _, term := range info {
+		err := c.send(term)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := c.send(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom(name), erlang.OtpErlangAtom(module),
+		erlang.OtpErlangAtom(function),
+		Encode(erlang.OtpErlangList{Value: args})})
+	if err != nil {
+		return nil, err
+	}
+	data, err := port.ReadPacket(c.reader, packet)
+	if err != nil {
+		return nil, err
+	}
+	term, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	tuple, ok := term.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) == 0 {
+		return nil, ErrResponse
+	}
+	switch {
+	case isAtom(tuple[0], response) && response == atomReply && len(tuple) == 2:
+		return tuple[1], nil
+	case isAtom(tuple[0], response) && response == atomNoReply && len(tuple) == 1:
+		return nil, nil
+	case isAtom(tuple[0], atomError) && len(tuple) == 2:
+		return nil, parseError(tuple[1])
+	default:
+		return nil, ErrResponse
+	}
+}
+
+func (c *Client) send(term interface{}) error {
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return err
+	}
+	return port.WritePacket(c.conn, packet, data)
+}
+
+func parseError(term interface{}) error {
+	tuple, ok := term.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) != 5 {
+		return ErrResponse
+	}
+	e := &Error{}
+	e.Type, ok = atomString(tuple[0])
+	if !ok {
+		return ErrResponse
+	}
+	code, ok := integer(tuple[1])
+	if !ok {
+		return ErrResponse
+	}
+	e.Code = int(code)
+	e.Class, _ = text(tuple[2])
+	e.Detail, _ = text(tuple[3])
+	backtrace, _ := listElements(tuple[4])
+	for _, line := range backtrace {
+		value, ok := text(line)
+		if ok {
+			e.Backtrace = append(e.Backtrace, value)
+		}
+	}
+	return e
+}
+
+// Handler processes the arguments of a BERT-RPC call or cast,
+// returning an *Error to provide a specific error response
+type Handler func(args []interface{}) (interface{}, error)
+
+// Server is a BERT-RPC server
+type Server struct {
+	mutex    sync.RWMutex
+	handlers map[string]map[string]Handler
+}
+
+// NewServer creates a BERT-RPC server
+func NewServer() *Server {
+	return &Server{handlers: make(map[string]map[string]Handler)}
+}
+
+// Register sets the handler of Module:Function
+func (s *Server) Register(module, function string, handler Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	functions, ok := s.handlers[module]
+	if !ok {
+		functions = make(map[string]Handler)
+		s.handlers[module] = functions
+	}
+	functions[function] = handler
+}
+
+// Serve handles BERT-RPC requests from the listener connections
+// until the listener fails
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn handles BERT-RPC requests from a single connection
+// until it is closed (info requests are ignored)
+func (s *Server) ServeConn(conn net.Conn) error {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		data, err := port.ReadPacket(reader, packet)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		var reply interface{}
+		term, err := Unmarshal(data)
+		tuple, ok := term.(erlang.OtpErlangTuple)
+		if err != nil || !ok || len(tuple) == 0 {
+			reply = (&Error{Type: ErrorProtocol, Code: CodeReadData,
+				Class: classBERTError, Detail: "invalid request"}).term()
+		} else if isAtom(tuple[0], atomInfo) {
+			continue
+		} else {
+			reply = s.request(tuple)
+		}
+		data, err = erlang.TermToBinary(Encode(reply), -1)
+		if err != nil {
+			return err
+		}
+		err = port.WritePacket(conn, packet, data)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) request(tuple erlang.OtpErlangTuple) interface{} {
+	cast := isAtom(tuple[0], atomCast)
+	if len(tuple) != 4 || !(cast || isAtom(tuple[0], atomCall)) {
+		return (&Error{Type: ErrorProtocol, Code: CodeUndesignated,
+			Class: classBERTError, Detail: "invalid request"}).term()
+	}
+	module, ok1 := atomString(tuple[1])
+	function, ok2 := atomString(tuple[2])
+	args, ok3 := listElements(tuple[3])
+	if !ok1 || !ok2 || !ok3 {
+		return (&Error{Type: ErrorProtocol, Code: CodeUndesignated,
+			Class: classBERTError, Detail: "invalid request"}).term()
+	}
+	s.mutex.RLock()
+	functions, ok := s.handlers[module]
+	var handler Handler
+	if ok {
+		handler, ok = functions[function]
+	}
+	s.mutex.RUnlock()
+	if functions == nil {
+		return (&Error{Type: ErrorServer, Code: CodeNoSuchModule,
+			Class: classBERTError, Detail: detailNoSuchModule}).term()
+	}
+	if !ok {
+		return (&Error{Type: ErrorServer, Code: CodeNoSuchFunction,
+			Class: classBERTError, Detail: detailNoSuchFunction}).term()
+	}
+	if cast {
+		go handler(args)
+		return erlang.OtpErlangTuple{erlang.OtpErlangAtom(atomNoReply)}
+	}
+	result, err := handler(args)
+	if err != nil {
+		e, ok := err.(*Error)
+		if !ok {
+			e = &Error{Type: ErrorUser, Code: CodeUndesignated,
+				Class: classUserError, Detail: err.Error()}
+		}
+		return e.term()
+	}
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom(atomReply), result}
+}
+
+func atomString(term interface{}) (string, bool) {
+	switch value := term.(type) {
+	case erlang.OtpErlangAtom:
+		return string(value), true
+	case erlang.OtpErlangAtomUTF8:
+		return string(value), true
+	default:
+		return "", false
+	}
+}
+
+func text(term interface{}) (string, bool) {
+	switch value := term.(type) {
+	case erlang.OtpErlangBinary:
+		return string(value.Value), true
+	case string:
+		return value, true
+	default:
+		return "", false
+	}
+}
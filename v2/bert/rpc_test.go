@@ -0,0 +1,84 @@
+package bert
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func TestRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assertEqual(t, nil, err, "")
+	defer listener.Close()
+	server := NewServer()
+	casts := make(chan []interface{}, 1)
+	server.Register("calc", "add", func(args []interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, &Error{Type: ErrorUser, Code: 1,
+				Class: "ArgumentError", Detail: "arity",
+				Backtrace: []string{"calc:add/2"}}
+		}
+		return int(args[0].(uint8)) + int(args[1].(uint8)), nil
+	})
+	server.Register("calc", "fail", func(args []interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	server.Register("calc", "log", func(args []interface{}) (interface{}, error) {
+		casts <- args
+		return nil, nil
+	})
+	go server.Serve(listener)
+
+	client, err := Dial(listener.Addr().String())
+	assertEqual(t, nil, err, "")
+	defer client.Close()
+	client.Info("cache", []interface{}{
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("access"), erlang.OtpErlangAtom("nil")}})
+	result, err := client.Call("calc", "add", 1, 2)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, uint8(3), result, "")
+
+	_, err = client.Call("calc", "add", 1)
+	assertEqual(t, &Error{Type: ErrorUser, Code: 1,
+		Class: "ArgumentError", Detail: "arity",
+		Backtrace: []string{"calc:add/2"}}, err, "")
+	_, err = client.Call("calc", "fail")
+	assertEqual(t, &Error{Type: ErrorUser, Code: CodeUndesignated,
+		Class: classUserError, Detail: "failed"}, err, "")
+	_, err = client.Call("calc", "unknown")
+	assertEqual(t, &Error{Type: ErrorServer, Code: CodeNoSuchFunction,
+		Class: classBERTError, Detail: detailNoSuchFunction}, err, "")
+	_, err = client.Call("unknown", "add")
+	assertEqual(t, &Error{Type: ErrorServer, Code: CodeNoSuchModule,
+		Class: classBERTError, Detail: detailNoSuchModule}, err, "")
+
+	err = client.Cast("calc", "log", nil, true)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, []interface{}{nil, true}, <-casts, "")
+}
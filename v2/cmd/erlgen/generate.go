@@ -0,0 +1,688 @@
+package main
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+const erlangImport = "github.com/okeuday/erlang_go/v2/erlang"
+
+// struct encodings
+const (
+	encodingMap = iota
+	encodingTuple
+	encodingRecord
+)
+
+// kinds of Go types (a fallback uses erlang.MarshalTerm
+// and erlang.UnmarshalTerm)
+const (
+	kindFallback = iota
+	kindInt
+	kindUint
+	kindFloat
+	kindBool
+	kindString
+	kindAtom
+	kindAtomUTF8
+	kindBytes
+	kindSlice
+	kindMap
+	kindPointer
+	kindStruct
+)
+
+type goType struct {
+	kind int
+	name string // Go type expression (empty if it refers to another package)
+	bits int    // integer or float bit size (0 for int and uint)
+	elem *goType
+	key  *goType
+}
+
+type structType struct {
+	name     string
+	encoding int
+	record   string
+	fields   []structField
+}
+
+type structField struct {
+	goName    string
+	name      string
+	omitEmpty bool
+	t         *goType
+}
+
+var integerTypes = map[string]struct {
+	kind int
+	bits int
+}{
+	"int": {kindInt, 0}, "int8": {kindInt, 8}, "int16": {kindInt, 16},
+	"int32": {kindInt, 32}, "rune": {kindInt, 32}, "int64": {kindInt, 64},
+	"uint": {kindUint, 0}, "uint8": {kindUint, 8}, "byte": {kindUint, 8},
+	"uint16": {kindUint, 16}, "uint32": {kindUint, 32},
+	"uint64":  {kindUint, 64},
+	"float32": {kindFloat, 32}, "float64": {kindFloat, 64},
+}
+
+// generator creates the Go source of the struct methods
+type generator struct {
+	erlangName string          // file name of the erlang package import
+	structs    map[string]bool // struct names with a directive
+	buffer     bytes.Buffer
+	depth      int
+}
+
+func generate(files []string, sources map[string][]byte) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	g := &generator{structs: make(map[string]bool)}
+	var packageName string
+	var parsed []*ast.File
+	for _, file := range files {
+		f, err := parser.ParseFile(fileSet, file, sources[file], parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if len(packageName) == 0 {
+			packageName = f.Name.Name
+		} else if packageName != f.Name.Name {
+			return nil, errors.New(file + ": package " + f.Name.Name +
+				" != " + packageName)
+		}
+		parsed = append(parsed, f)
+		for _, spec := range structSpecs(f) {
+			g.structs[spec.typeSpec.Name.Name] = true
+		}
+	}
+	var structs []structType
+	for _, f := range parsed {
+		g.erlangName = ""
+		for _, i := range f.Imports {
+			if path, _ := strconv.Unquote(i.Path.Value); path == erlangImport {
+				g.erlangName = "erlang"
+				if i.Name != nil {
+					g.erlangName = i.Name.Name
+				}
+			}
+		}
+		for _, spec := range structSpecs(f) {
+			s, err := g.structType(spec)
+			if err != nil {
+				return nil, errors.New(fileSet.Position(spec.typeSpec.Pos()).String() +
+					": " + err.Error())
+			}
+			structs = append(structs, s)
+		}
+	}
+
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = filepath.Base(file)
+	}
+	g.printf("// Code generated by erlgen from %s. DO NOT EDIT.\n\n",
+		strings.Join(names, ", "))
+	g.printf("package %s\n\n", packageName)
+	g.printf("import (\n\t%s\n)\n", strconv.Quote(erlangImport))
+	for _, s := range structs {
+		g.structMethods(s)
+	}
+	return format.Source(g.buffer.Bytes())
+}
+
+type structSpec struct {
+	typeSpec  *ast.TypeSpec
+	directive string
+}
+
+// structSpecs provides the struct types with an erlang: directive
+func structSpecs(f *ast.File) []structSpec {
+	var specs []structSpec
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if _, ok := typeSpec.Type.(*ast.StructType); !ok {
+				continue
+			}
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			for _, comment := range doc.List {
+				if strings.HasPrefix(comment.Text, "//erlang:") {
+					specs = append(specs, structSpec{typeSpec,
+						strings.TrimPrefix(comment.Text, "//erlang:")})
+					break
+				}
+			}
+		}
+	}
+	return specs
+}
+
+func (g *generator) structType(spec structSpec) (structType, error) {
+	s := structType{name: spec.typeSpec.Name.Name}
+	directive := strings.Fields(spec.directive)
+	switch {
+	case len(directive) == 1 && directive[0] == "map":
+		s.encoding = encodingMap
+	case len(directive) == 1 && directive[0] == "tuple":
+		s.encoding = encodingTuple
+	case len(directive) <= 2 && len(directive) > 0 && directive[0] == "record":
+		s.encoding = encodingRecord
+		s.record = erlang.SnakeCase(s.name)
+		if len(directive) == 2 {
+			s.record = directive[1]
+		}
+	default:
+		return s, errors.New("invalid directive //erlang:" + spec.directive)
+	}
+	if spec.typeSpec.TypeParams != nil {
+		return s, errors.New("type parameters not supported")
+	}
+	for _, field := range spec.typeSpec.Type.(*ast.StructType).Fields.List {
+		var tag string
+		if field.Tag != nil {
+			tagText, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(tagText).Get("erlang")
+		}
+		if len(field.Names) == 0 {
+			if !g.isRecord(field.Type) {
+				return s, errors.New("embedded field not supported")
+			}
+			// the erlang.Record name is used by erlang.Marshal
+			if s.encoding != encodingRecord {
+				return s, errors.New("erlang.Record requires //erlang:record")
+			}
+			name := strings.Split(tag, ",")[0]
+			if len(directive) == 1 && len(name) > 0 {
+				s.record = name
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		t := g.goType(field.Type)
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			f := structField{goName: name.Name, name: options[0], t: t}
+			if len(f.name) == 0 {
+				f.name = erlang.SnakeCase(name.Name)
+			}
+			for _, option := range options[1:] {
+				if option == "omitempty" {
+					f.omitEmpty = true
+				}
+			}
+			if f.omitEmpty {
+				if s.encoding != encodingMap {
+					return s, errors.New(name.Name + ": omitempty requires //erlang:map")
+				}
+				if empty, _ := emptyCheck("v."+f.goName, f.t); len(empty) == 0 {
+					return s, errors.New(name.Name + ": omitempty not supported for " +
+						"type " + f.t.name)
+				}
+			}
+			s.fields = append(s.fields, f)
+		}
+	}
+	return s, nil
+}
+
+// isRecord checks if the type expression is erlang.Record
+func (g *generator) isRecord(expr ast.Expr) bool {
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := selector.X.(*ast.Ident)
+	return ok && len(g.erlangName) > 0 && x.Name == g.erlangName &&
+		selector.Sel.Name == "Record"
+}
+
+// goType provides how a Go type expression is encoded
+func (g *generator) goType(expr ast.Expr) *goType {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if integer, ok := integerTypes[t.Name]; ok {
+			return &goType{kind: integer.kind, name: t.Name, bits: integer.bits}
+		}
+		switch {
+		case t.Name == "bool":
+			return &goType{kind: kindBool, name: t.Name}
+		case t.Name == "string":
+			return &goType{kind: kindString, name: t.Name}
+		case g.structs[t.Name]:
+			return &goType{kind: kindStruct, name: t.Name}
+		default:
+			return &goType{kind: kindFallback, name: t.Name}
+		}
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok || len(g.erlangName) == 0 || x.Name != g.erlangName {
+			return &goType{kind: kindFallback}
+		}
+		name := "erlang." + t.Sel.Name
+		switch t.Sel.Name {
+		case "OtpErlangAtom":
+			return &goType{kind: kindAtom, name: name}
+		case "OtpErlangAtomUTF8":
+			return &goType{kind: kindAtomUTF8, name: name}
+		default:
+			return &goType{kind: kindFallback, name: name}
+		}
+	case *ast.ArrayType:
+		elem := g.goType(t.Elt)
+		if len(elem.name) == 0 {
+			return &goType{kind: kindFallback}
+		}
+		if t.Len != nil {
+			length, ok := t.Len.(*ast.BasicLit)
+			if !ok {
+				return &goType{kind: kindFallback}
+			}
+			return &goType{kind: kindFallback,
+				name: "[" + length.Value + "]" + elem.name}
+		}
+		if elem.kind == kindUint && elem.bits == 8 {
+			return &goType{kind: kindBytes, name: "[]" + elem.name}
+		}
+		return &goType{kind: kindSlice, name: "[]" + elem.name, elem: elem}
+	case *ast.MapType:
+		key := g.goType(t.Key)
+		elem := g.goType(t.Value)
+		if len(key.name) == 0 || len(elem.name) == 0 {
+			return &goType{kind: kindFallback}
+		}
+		return &goType{kind: kindMap, name: "map[" + key.name + "]" + elem.name,
+			key: key, elem: elem}
+	case *ast.StarExpr:
+		elem := g.goType(t.X)
+		if len(elem.name) == 0 {
+			return &goType{kind: kindFallback}
+		}
+		return &goType{kind: kindPointer, name: "*" + elem.name, elem: elem}
+	case *ast.InterfaceType:
+		if len(t.Methods.List) == 0 {
+			return &goType{kind: kindFallback, name: "interface{}"}
+		}
+		return &goType{kind: kindFallback}
+	default:
+		return &goType{kind: kindFallback}
+	}
+}
+
+// emptyCheck provides the omitempty conditions for an empty and
+// a non-empty value (like reflect.Value.IsZero)
+func emptyCheck(value string, t *goType) (string, string) {
+	switch t.kind {
+	case kindInt, kindUint, kindFloat:
+		return value + " == 0", value + " != 0"
+	case kindBool:
+		return "!" + value, value
+	case kindString, kindAtom, kindAtomUTF8:
+		return value + ` == ""`, value + ` != ""`
+	case kindBytes, kindSlice, kindMap, kindPointer:
+		return value + " == nil", value + " != nil"
+	case kindFallback:
+		if t.name == "interface{}" || t.name == "any" {
+			return value + " == nil", value + " != nil"
+		}
+		return "", ""
+	default:
+		return "", ""
+	}
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buffer, format, args...)
+}
+
+// atomLiteral provides the Go string literal of an encoded atom
+func atomLiteral(name string) string {
+	data, _ := erlang.AppendAtom(nil, name)
+	header := len(data) - len(name)
+	var literal strings.Builder
+	literal.WriteByte('"')
+	for _, c := range data[:header] {
+		fmt.Fprintf(&literal, "\\x%02x", c)
+	}
+	quoted := strconv.Quote(name)
+	literal.WriteString(quoted[1:])
+	return literal.String()
+}
+
+func (g *generator) structMethods(s structType) {
+	var kind string
+	switch s.encoding {
+	case encodingMap:
+		kind = "map"
+	case encodingTuple:
+		kind = "tuple"
+	case encodingRecord:
+		kind = "#" + s.record + "{} record"
+	}
+	failEncode := func(f structField) string {
+		if s.encoding == encodingRecord {
+			return fmt.Sprintf("return b, &erlang.RecordError{Record: %s, "+
+				"Field: %s, Err: err}", strconv.Quote(s.record),
+				strconv.Quote(f.name))
+		}
+		return "return b, err"
+	}
+	failDecode := func(f structField) string {
+		if s.encoding == encodingRecord {
+			return fmt.Sprintf("return &erlang.RecordError{Record: %s, "+
+				"Field: %s, Err: err}", strconv.Quote(s.record),
+				strconv.Quote(f.name))
+		}
+		return "return err"
+	}
+
+	g.printf("\n// AppendErlang appends the %s term of %s\n", kind, s.name)
+	g.printf("// (in the Erlang External Term Format without the version tag)\n")
+	g.printf("func (v *%s) AppendErlang(b []byte) ([]byte, error) {\n", s.name)
+	g.printf("var err error\n")
+	switch s.encoding {
+	case encodingMap:
+		g.printf("size := %d\n", len(s.fields))
+		for _, f := range s.fields {
+			if f.omitEmpty {
+				empty, _ := emptyCheck("v."+f.goName, f.t)
+				g.printf("if %s {\nsize -= 1\n}\n", empty)
+			}
+		}
+		g.printf("b, err = erlang.AppendMapHeader(b, size)\n")
+		g.printf("if err != nil {\nreturn b, err\n}\n")
+		for _, f := range s.fields {
+			if f.omitEmpty {
+				_, nonEmpty := emptyCheck("v."+f.goName, f.t)
+				g.printf("if %s {\n", nonEmpty)
+			}
+			g.printf("b = append(b, %s...)\n", atomLiteral(f.name))
+			g.encode("v."+f.goName, f.t, failEncode(f))
+			if f.omitEmpty {
+				g.printf("}\n")
+			}
+		}
+	case encodingTuple, encodingRecord:
+		arity := len(s.fields)
+		if s.encoding == encodingRecord {
+			arity += 1
+		}
+		g.printf("b, err = erlang.AppendTupleHeader(b, %d)\n", arity)
+		g.printf("if err != nil {\nreturn b, err\n}\n")
+		if s.encoding == encodingRecord {
+			g.printf("b = append(b, %s...)\n", atomLiteral(s.record))
+		}
+		for _, f := range s.fields {
+			g.encode("v."+f.goName, f.t, failEncode(f))
+		}
+	}
+	g.printf("return b, nil\n}\n")
+
+	g.printf("\n// ReadErlang reads the %s term of %s\n", kind, s.name)
+	g.printf("func (v *%s) ReadErlang(r *erlang.TermReader) error {\n", s.name)
+	switch s.encoding {
+	case encodingMap:
+		g.printf("size, err := r.MapHeader()\n")
+		g.printf("if err != nil {\nreturn err\n}\n")
+		g.printf("for i := 0; i < size; i++ {\n")
+		g.printf("var key string\nkey, err = r.Key()\n")
+		g.printf("if err != nil {\nreturn err\n}\n")
+		g.printf("switch key {\n")
+		for _, f := range s.fields {
+			g.printf("case %s:\n", strconv.Quote(f.name))
+			g.decode("v."+f.goName, f.t, failDecode(f), true)
+		}
+		g.printf("default:\nerr = r.Skip()\n")
+		g.printf("if err != nil {\nreturn err\n}\n")
+		g.printf("}\n}\n")
+	case encodingTuple:
+		g.printf("err := r.Tuple(%d)\n", len(s.fields))
+		g.printf("if err != nil {\nreturn err\n}\n")
+	case encodingRecord:
+		g.printf("err := r.Record(%s, %d)\n", strconv.Quote(s.record),
+			len(s.fields)+1)
+		g.printf("if err != nil {\nreturn err\n}\n")
+	}
+	if s.encoding != encodingMap {
+		for _, f := range s.fields {
+			g.decode("v."+f.goName, f.t, failDecode(f), true)
+		}
+	}
+	g.printf("return nil\n}\n")
+
+	g.printf("\n// MarshalErlangBinary encodes %s in the Erlang External Term Format\n",
+		s.name)
+	g.printf("func (v *%s) MarshalErlangBinary() ([]byte, error) {\n", s.name)
+	g.printf("return v.AppendErlang(erlang.AppendVersion(nil))\n}\n")
+
+	g.printf("\n// UnmarshalErlangBinary decodes %s from the Erlang External Term Format\n",
+		s.name)
+	g.printf("func (v *%s) UnmarshalErlangBinary(data []byte) error {\n", s.name)
+	g.printf("r, err := erlang.NewTermReader(data)\n")
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("err = v.ReadErlang(r)\n")
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("return r.End()\n}\n")
+
+	// erlang.Marshal and erlang.Unmarshal use the same encoding
+	g.printf("\n// MarshalErlang provides the %s term of %s for erlang.Marshal\n",
+		kind, s.name)
+	g.printf("// (a value receiver so both %s and *%s are erlang.Marshaler)\n",
+		s.name, s.name)
+	g.printf("func (v %s) MarshalErlang() (interface{}, error) {\n", s.name)
+	g.printf("b, err := v.AppendErlang(nil)\n")
+	g.printf("if err != nil {\nreturn nil, err\n}\n")
+	g.printf("return erlang.RawTerm(b), nil\n}\n")
+
+	g.printf("\n// UnmarshalErlang stores the %s term in %s for erlang.Unmarshal\n",
+		kind, s.name)
+	g.printf("func (v *%s) UnmarshalErlang(term interface{}) error {\n", s.name)
+	g.printf("data, err := erlang.TermToBinary(term, -1)\n")
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("return v.UnmarshalErlangBinary(data)\n}\n")
+}
+
+// encode provides the statements that append the value
+func (g *generator) encode(value string, t *goType, fail string) {
+	check := "if err != nil {\n" + fail + "\n}\n"
+	switch t.kind {
+	case kindInt:
+		g.printf("b = erlang.AppendInt(b, int64(%s))\n", value)
+	case kindUint:
+		g.printf("b = erlang.AppendUint(b, uint64(%s))\n", value)
+	case kindFloat:
+		g.printf("b = erlang.AppendFloat(b, float64(%s))\n", value)
+	case kindBool:
+		g.printf("b = erlang.AppendBool(b, %s)\n", value)
+	case kindString:
+		g.printf("b, err = erlang.AppendString(b, %s)\n%s", value, check)
+	case kindAtom:
+		g.printf("b, err = erlang.AppendAtom(b, string(%s))\n%s", value, check)
+	case kindAtomUTF8:
+		g.printf("b, err = erlang.AppendAtomUTF8(b, string(%s))\n%s", value, check)
+	case kindBytes:
+		// a nil slice is an empty list (like erlang.MarshalTerm)
+		g.printf("if %s == nil {\nb = erlang.AppendNil(b)\n} else {\n", value)
+		g.printf("b, err = erlang.AppendBinary(b, %s)\n%s}\n", value, check)
+	case kindSlice:
+		g.depth += 1
+		i := "i" + strconv.Itoa(g.depth)
+		g.printf("if len(%s) == 0 {\nb = erlang.AppendNil(b)\n} else {\n", value)
+		g.printf("b, err = erlang.AppendListHeader(b, len(%s))\n%s", value, check)
+		g.printf("for %s := range %s {\n", i, value)
+		g.encode(value+"["+i+"]", t.elem, fail)
+		g.printf("}\nb = erlang.AppendNil(b)\n}\n")
+		g.depth -= 1
+	case kindMap:
+		g.depth += 1
+		key := "k" + strconv.Itoa(g.depth)
+		elem := "e" + strconv.Itoa(g.depth)
+		g.printf("b, err = erlang.AppendMapHeader(b, len(%s))\n%s", value, check)
+		g.printf("for %s, %s := range %s {\n", key, elem, value)
+		g.encode(key, t.key, fail)
+		g.encode(elem, t.elem, fail)
+		g.printf("}\n")
+		g.depth -= 1
+	case kindPointer:
+		g.printf("if %s == nil {\nb = erlang.AppendUndefined(b)\n} else {\n", value)
+		g.encode(dereference(value, t.elem), t.elem, fail)
+		g.printf("}\n")
+	case kindStruct:
+		g.printf("b, err = %s.AppendErlang(b)\n%s", value, check)
+	default:
+		g.printf("{\nvar term interface{}\n")
+		g.printf("term, err = erlang.MarshalTerm(%s)\n%s", value, check)
+		g.printf("b, err = erlang.AppendTerm(b, term)\n%s}\n", check)
+	}
+}
+
+// decode provides the statements that read the value
+// (the undefined atom leaves a value unchanged, like erlang.UnmarshalTerm)
+func (g *generator) decode(value string, t *goType, fail string, undefined bool) {
+	check := "if err != nil {\n" + fail + "\n}\n"
+	opened := false
+	if undefined && t.kind != kindPointer && t.kind != kindFallback {
+		g.printf("if !r.Undefined() {\n")
+		opened = true
+	}
+	// a block for variables
+	block := func() {
+		if !opened {
+			g.printf("{\n")
+			opened = true
+		}
+	}
+	switch t.kind {
+	case kindInt:
+		if t.name == "int64" {
+			g.printf("%s, err = r.Int(%d)\n%s", value, t.bits, check)
+			break
+		}
+		block()
+		g.printf("var integer int64\ninteger, err = r.Int(%d)\n%s", t.bits, check)
+		g.printf("%s = %s(integer)\n", value, t.name)
+	case kindUint:
+		if t.name == "uint64" {
+			g.printf("%s, err = r.Uint(%d)\n%s", value, t.bits, check)
+			break
+		}
+		block()
+		g.printf("var integer uint64\ninteger, err = r.Uint(%d)\n%s", t.bits, check)
+		g.printf("%s = %s(integer)\n", value, t.name)
+	case kindFloat:
+		if t.name == "float64" {
+			g.printf("%s, err = r.Float(%d)\n%s", value, t.bits, check)
+			break
+		}
+		block()
+		g.printf("var float float64\nfloat, err = r.Float(%d)\n%s", t.bits, check)
+		g.printf("%s = %s(float)\n", value, t.name)
+	case kindBool:
+		g.printf("%s, err = r.Bool()\n%s", value, check)
+	case kindString:
+		g.printf("%s, err = r.String()\n%s", value, check)
+	case kindAtom, kindAtomUTF8:
+		block()
+		g.printf("var text string\ntext, err = r.String()\n%s", check)
+		g.printf("%s = %s(text)\n", value, t.name)
+	case kindBytes:
+		g.printf("%s, err = r.Bytes()\n%s", value, check)
+	case kindSlice:
+		g.depth += 1
+		suffix := strconv.Itoa(g.depth)
+		block()
+		g.printf("var length%s int\nlength%s, err = r.ListHeader()\n%s",
+			suffix, suffix, check)
+		g.printf("%s = make(%s, length%s)\n", value, t.name, suffix)
+		g.printf("for i%s := range %s {\n", suffix, value)
+		g.decode(value+"[i"+suffix+"]", t.elem, fail, true)
+		g.printf("}\nerr = r.ListEnd()\n%s", check)
+		g.depth -= 1
+	case kindMap:
+		g.depth += 1
+		suffix := strconv.Itoa(g.depth)
+		block()
+		g.printf("var size%s int\nsize%s, err = r.MapHeader()\n%s",
+			suffix, suffix, check)
+		g.printf("%s = make(%s, size%s)\n", value, t.name, suffix)
+		g.printf("for i%s := 0; i%s < size%s; i%s++ {\n",
+			suffix, suffix, suffix, suffix)
+		g.printf("var k%s %s\nvar e%s %s\n", suffix, t.key.name, suffix, t.elem.name)
+		g.decode("k"+suffix, t.key, fail, true)
+		g.decode("e"+suffix, t.elem, fail, true)
+		g.printf("%s[k%s] = e%s\n}\n", value, suffix, suffix)
+		g.depth -= 1
+	case kindPointer:
+		g.printf("if r.Undefined() {\n%s = nil\n} else {\n", value)
+		g.printf("if %s == nil {\n%s = new(%s)\n}\n", value, value, t.elem.name)
+		g.decode(dereference(value, t.elem), t.elem, fail, false)
+		g.printf("}\n")
+	case kindStruct:
+		g.printf("err = %s.ReadErlang(r)\n%s", value, check)
+	default:
+		block()
+		g.printf("var term interface{}\nterm, err = r.Term()\n%s", check)
+		g.printf("err = erlang.UnmarshalTerm(term, &%s)\n%s", value, check)
+	}
+	if opened {
+		g.printf("}\n")
+	}
+}
+
+// dereference provides the value a pointer refers to
+// (struct methods use the pointer)
+func dereference(value string, elem *goType) string {
+	if elem.kind == kindStruct {
+		return value
+	}
+	return "(*" + value + ")"
+}
@@ -0,0 +1,141 @@
+package fixture
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"reflect"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+// userReflect is User without the generated methods
+type userReflect User
+
+// groupRecordReflect is GroupRecord without the generated methods
+type groupRecordReflect GroupRecord
+
+func TestTuple(t *testing.T) {
+	point := Point{X: 1, Y: -2}
+	data, err := point.MarshalErlangBinary()
+	assertEqual(t, nil, err, "")
+	expect, err := erlang.TermToBinary(erlang.OtpErlangTuple{
+		uint8(1), int32(-2)}, -1)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	data, err = erlang.Marshal(point)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	data, err = erlang.Marshal(&point)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	var result Point
+	assertEqual(t, nil, erlang.Unmarshal(data, &result), "")
+	assertEqual(t, point, result, "")
+}
+
+func TestRecord(t *testing.T) {
+	record := UserRecord{ID: 7, Sub: Point{X: 1, Y: 2}}
+	data, err := record.MarshalErlangBinary()
+	assertEqual(t, nil, err, "")
+	expect, err := erlang.TermToBinary(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("user"), uint8(7),
+		erlang.OtpErlangTuple{uint8(1), uint8(2)}}, -1)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	data, err = erlang.Marshal(record)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	var result UserRecord
+	assertEqual(t, nil, result.UnmarshalErlangBinary(data), "")
+	assertEqual(t, record, result, "")
+	result = UserRecord{}
+	assertEqual(t, nil, erlang.Unmarshal(data, &result), "")
+	assertEqual(t, record, result, "")
+	err = result.UnmarshalErlangBinary([]byte("\x83h\x03d\x00\x04usera\x07j"))
+	assertEqual(t, "#user.sub: invalid tuple: offset 12, tag 106 (NIL_EXT)",
+		err.Error(), "")
+}
+
+func TestEmbeddedRecord(t *testing.T) {
+	group := GroupRecord{Name: "group", Users: []UserRecord{{ID: 1}, {ID: 2}}}
+	data, err := group.MarshalErlangBinary()
+	assertEqual(t, nil, err, "")
+	expect, err := erlang.Marshal(groupRecordReflect(group))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	var result GroupRecord
+	assertEqual(t, nil, erlang.Unmarshal(data, &result), "")
+	assertEqual(t, group, result, "")
+	var resultReflect groupRecordReflect
+	assertEqual(t, nil, erlang.Unmarshal(data, &resultReflect), "")
+	assertEqual(t, group, GroupRecord(resultReflect), "")
+}
+
+func TestMap(t *testing.T) {
+	users := []User{
+		{Tags: []string{}, Scores: map[string]float64{}},
+		{UserID: 1 << 40, Name: "name", Email: []byte("user@example.com"),
+			Tags:     []string{"a", "b"},
+			Scores:   map[string]float64{"x": 1.5, "y": -2.0},
+			Location: &Point{X: 3, Y: 4}, Role: "admin", Ratio: 0.25,
+			Extra: erlang.OtpErlangAtom("extra")},
+	}
+	for _, user := range users {
+		data, err := user.MarshalErlangBinary()
+		assertEqual(t, nil, err, "")
+		// the generated encoding matches the reflection encoding
+		// (compared as terms because map order is not deterministic)
+		expect, err := erlang.Marshal(userReflect(user))
+		assertEqual(t, nil, err, "")
+		term, err := erlang.BinaryToTerm(data)
+		assertEqual(t, nil, err, "")
+		termExpect, err := erlang.BinaryToTerm(expect)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, termExpect, term, "")
+
+		var result User
+		assertEqual(t, nil, result.UnmarshalErlangBinary(expect), "")
+		assertEqual(t, user, result, "")
+		result = User{}
+		assertEqual(t, nil, erlang.Unmarshal(data, &result), "")
+		assertEqual(t, user, result, "")
+		var resultReflect userReflect
+		assertEqual(t, nil, erlang.Unmarshal(data, &resultReflect), "")
+		assertEqual(t, user, User(resultReflect), "")
+	}
+}
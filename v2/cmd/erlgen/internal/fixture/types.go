@@ -0,0 +1,75 @@
+package fixture
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+//go:generate go run github.com/okeuday/erlang_go/v2/cmd/erlgen -output types_erlang.go types.go
+
+import (
+	"time"
+
+	erl "github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// Point is a tuple
+//
+//erlang:tuple
+type Point struct {
+	X, Y int32
+}
+
+// User is not a record
+//
+//erlang:map
+type User struct {
+	UserID   int64
+	Name     string
+	Email    []byte `erlang:",omitempty"`
+	Tags     []string
+	Scores   map[string]float64
+	Location *Point
+	Role     erl.OtpErlangAtom
+	Ratio    float32
+	Ignored  int `erlang:"-"`
+	When     time.Time
+	Extra    interface{} `erlang:"extra,omitempty"`
+}
+
+// UserRecord is a record with the name of the directive
+//
+//erlang:record user
+type UserRecord struct {
+	ID  int
+	Sub Point
+}
+
+// GroupRecord is a record with the name of an embedded erlang.Record
+//
+//erlang:record
+type GroupRecord struct {
+	erl.Record `erlang:"group"`
+	Name       string
+	Users      []UserRecord
+}
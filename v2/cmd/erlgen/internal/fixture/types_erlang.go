@@ -0,0 +1,550 @@
+// Code generated by erlgen from types.go. DO NOT EDIT.
+
+package fixture
+
+import (
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// AppendErlang appends the tuple term of Point
+// (in the Erlang External Term Format without the version tag)
+func (v *Point) AppendErlang(b []byte) ([]byte, error) {
+	var err error
+	b, err = erlang.AppendTupleHeader(b, 2)
+	if err != nil {
+		return b, err
+	}
+	b = erlang.AppendInt(b, int64(v.X))
+	b = erlang.AppendInt(b, int64(v.Y))
+	return b, nil
+}
+
+// ReadErlang reads the tuple term of Point
+func (v *Point) ReadErlang(r *erlang.TermReader) error {
+	err := r.Tuple(2)
+	if err != nil {
+		return err
+	}
+	if !r.Undefined() {
+		var integer int64
+		integer, err = r.Int(32)
+		if err != nil {
+			return err
+		}
+		v.X = int32(integer)
+	}
+	if !r.Undefined() {
+		var integer int64
+		integer, err = r.Int(32)
+		if err != nil {
+			return err
+		}
+		v.Y = int32(integer)
+	}
+	return nil
+}
+
+// MarshalErlangBinary encodes Point in the Erlang External Term Format
+func (v *Point) MarshalErlangBinary() ([]byte, error) {
+	return v.AppendErlang(erlang.AppendVersion(nil))
+}
+
+// UnmarshalErlangBinary decodes Point from the Erlang External Term Format
+func (v *Point) UnmarshalErlangBinary(data []byte) error {
+	r, err := erlang.NewTermReader(data)
+	if err != nil {
+		return err
+	}
+	err = v.ReadErlang(r)
+	if err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// MarshalErlang provides the tuple term of Point for erlang.Marshal
+// (a value receiver so both Point and *Point are erlang.Marshaler)
+func (v Point) MarshalErlang() (interface{}, error) {
+	b, err := v.AppendErlang(nil)
+	if err != nil {
+		return nil, err
+	}
+	return erlang.RawTerm(b), nil
+}
+
+// UnmarshalErlang stores the tuple term in Point for erlang.Unmarshal
+func (v *Point) UnmarshalErlang(term interface{}) error {
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalErlangBinary(data)
+}
+
+// AppendErlang appends the map term of User
+// (in the Erlang External Term Format without the version tag)
+func (v *User) AppendErlang(b []byte) ([]byte, error) {
+	var err error
+	size := 10
+	if v.Email == nil {
+		size -= 1
+	}
+	if v.Extra == nil {
+		size -= 1
+	}
+	b, err = erlang.AppendMapHeader(b, size)
+	if err != nil {
+		return b, err
+	}
+	b = append(b, "\x73\x07user_id"...)
+	b = erlang.AppendInt(b, int64(v.UserID))
+	b = append(b, "\x73\x04name"...)
+	b, err = erlang.AppendString(b, v.Name)
+	if err != nil {
+		return b, err
+	}
+	if v.Email != nil {
+		b = append(b, "\x73\x05email"...)
+		if v.Email == nil {
+			b = erlang.AppendNil(b)
+		} else {
+			b, err = erlang.AppendBinary(b, v.Email)
+			if err != nil {
+				return b, err
+			}
+		}
+	}
+	b = append(b, "\x73\x04tags"...)
+	if len(v.Tags) == 0 {
+		b = erlang.AppendNil(b)
+	} else {
+		b, err = erlang.AppendListHeader(b, len(v.Tags))
+		if err != nil {
+			return b, err
+		}
+		for i1 := range v.Tags {
+			b, err = erlang.AppendString(b, v.Tags[i1])
+			if err != nil {
+				return b, err
+			}
+		}
+		b = erlang.AppendNil(b)
+	}
+	b = append(b, "\x73\x06scores"...)
+	b, err = erlang.AppendMapHeader(b, len(v.Scores))
+	if err != nil {
+		return b, err
+	}
+	for k1, e1 := range v.Scores {
+		b, err = erlang.AppendString(b, k1)
+		if err != nil {
+			return b, err
+		}
+		b = erlang.AppendFloat(b, float64(e1))
+	}
+	b = append(b, "\x73\x08location"...)
+	if v.Location == nil {
+		b = erlang.AppendUndefined(b)
+	} else {
+		b, err = v.Location.AppendErlang(b)
+		if err != nil {
+			return b, err
+		}
+	}
+	b = append(b, "\x73\x04role"...)
+	b, err = erlang.AppendAtom(b, string(v.Role))
+	if err != nil {
+		return b, err
+	}
+	b = append(b, "\x73\x05ratio"...)
+	b = erlang.AppendFloat(b, float64(v.Ratio))
+	b = append(b, "\x73\x04when"...)
+	{
+		var term interface{}
+		term, err = erlang.MarshalTerm(v.When)
+		if err != nil {
+			return b, err
+		}
+		b, err = erlang.AppendTerm(b, term)
+		if err != nil {
+			return b, err
+		}
+	}
+	if v.Extra != nil {
+		b = append(b, "\x73\x05extra"...)
+		{
+			var term interface{}
+			term, err = erlang.MarshalTerm(v.Extra)
+			if err != nil {
+				return b, err
+			}
+			b, err = erlang.AppendTerm(b, term)
+			if err != nil {
+				return b, err
+			}
+		}
+	}
+	return b, nil
+}
+
+// ReadErlang reads the map term of User
+func (v *User) ReadErlang(r *erlang.TermReader) error {
+	size, err := r.MapHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < size; i++ {
+		var key string
+		key, err = r.Key()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "user_id":
+			if !r.Undefined() {
+				v.UserID, err = r.Int(64)
+				if err != nil {
+					return err
+				}
+			}
+		case "name":
+			if !r.Undefined() {
+				v.Name, err = r.String()
+				if err != nil {
+					return err
+				}
+			}
+		case "email":
+			if !r.Undefined() {
+				v.Email, err = r.Bytes()
+				if err != nil {
+					return err
+				}
+			}
+		case "tags":
+			if !r.Undefined() {
+				var length1 int
+				length1, err = r.ListHeader()
+				if err != nil {
+					return err
+				}
+				v.Tags = make([]string, length1)
+				for i1 := range v.Tags {
+					if !r.Undefined() {
+						v.Tags[i1], err = r.String()
+						if err != nil {
+							return err
+						}
+					}
+				}
+				err = r.ListEnd()
+				if err != nil {
+					return err
+				}
+			}
+		case "scores":
+			if !r.Undefined() {
+				var size1 int
+				size1, err = r.MapHeader()
+				if err != nil {
+					return err
+				}
+				v.Scores = make(map[string]float64, size1)
+				for i1 := 0; i1 < size1; i1++ {
+					var k1 string
+					var e1 float64
+					if !r.Undefined() {
+						k1, err = r.String()
+						if err != nil {
+							return err
+						}
+					}
+					if !r.Undefined() {
+						e1, err = r.Float(64)
+						if err != nil {
+							return err
+						}
+					}
+					v.Scores[k1] = e1
+				}
+			}
+		case "location":
+			if r.Undefined() {
+				v.Location = nil
+			} else {
+				if v.Location == nil {
+					v.Location = new(Point)
+				}
+				err = v.Location.ReadErlang(r)
+				if err != nil {
+					return err
+				}
+			}
+		case "role":
+			if !r.Undefined() {
+				var text string
+				text, err = r.String()
+				if err != nil {
+					return err
+				}
+				v.Role = erlang.OtpErlangAtom(text)
+			}
+		case "ratio":
+			if !r.Undefined() {
+				var float float64
+				float, err = r.Float(32)
+				if err != nil {
+					return err
+				}
+				v.Ratio = float32(float)
+			}
+		case "when":
+			{
+				var term interface{}
+				term, err = r.Term()
+				if err != nil {
+					return err
+				}
+				err = erlang.UnmarshalTerm(term, &v.When)
+				if err != nil {
+					return err
+				}
+			}
+		case "extra":
+			{
+				var term interface{}
+				term, err = r.Term()
+				if err != nil {
+					return err
+				}
+				err = erlang.UnmarshalTerm(term, &v.Extra)
+				if err != nil {
+					return err
+				}
+			}
+		default:
+			err = r.Skip()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalErlangBinary encodes User in the Erlang External Term Format
+func (v *User) MarshalErlangBinary() ([]byte, error) {
+	return v.AppendErlang(erlang.AppendVersion(nil))
+}
+
+// UnmarshalErlangBinary decodes User from the Erlang External Term Format
+func (v *User) UnmarshalErlangBinary(data []byte) error {
+	r, err := erlang.NewTermReader(data)
+	if err != nil {
+		return err
+	}
+	err = v.ReadErlang(r)
+	if err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// MarshalErlang provides the map term of User for erlang.Marshal
+// (a value receiver so both User and *User are erlang.Marshaler)
+func (v User) MarshalErlang() (interface{}, error) {
+	b, err := v.AppendErlang(nil)
+	if err != nil {
+		return nil, err
+	}
+	return erlang.RawTerm(b), nil
+}
+
+// UnmarshalErlang stores the map term in User for erlang.Unmarshal
+func (v *User) UnmarshalErlang(term interface{}) error {
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalErlangBinary(data)
+}
+
+// AppendErlang appends the #user{} record term of UserRecord
+// (in the Erlang External Term Format without the version tag)
+func (v *UserRecord) AppendErlang(b []byte) ([]byte, error) {
+	var err error
+	b, err = erlang.AppendTupleHeader(b, 3)
+	if err != nil {
+		return b, err
+	}
+	b = append(b, "\x73\x04user"...)
+	b = erlang.AppendInt(b, int64(v.ID))
+	b, err = v.Sub.AppendErlang(b)
+	if err != nil {
+		return b, &erlang.RecordError{Record: "user", Field: "sub", Err: err}
+	}
+	return b, nil
+}
+
+// ReadErlang reads the #user{} record term of UserRecord
+func (v *UserRecord) ReadErlang(r *erlang.TermReader) error {
+	err := r.Record("user", 3)
+	if err != nil {
+		return err
+	}
+	if !r.Undefined() {
+		var integer int64
+		integer, err = r.Int(0)
+		if err != nil {
+			return &erlang.RecordError{Record: "user", Field: "id", Err: err}
+		}
+		v.ID = int(integer)
+	}
+	if !r.Undefined() {
+		err = v.Sub.ReadErlang(r)
+		if err != nil {
+			return &erlang.RecordError{Record: "user", Field: "sub", Err: err}
+		}
+	}
+	return nil
+}
+
+// MarshalErlangBinary encodes UserRecord in the Erlang External Term Format
+func (v *UserRecord) MarshalErlangBinary() ([]byte, error) {
+	return v.AppendErlang(erlang.AppendVersion(nil))
+}
+
+// UnmarshalErlangBinary decodes UserRecord from the Erlang External Term Format
+func (v *UserRecord) UnmarshalErlangBinary(data []byte) error {
+	r, err := erlang.NewTermReader(data)
+	if err != nil {
+		return err
+	}
+	err = v.ReadErlang(r)
+	if err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// MarshalErlang provides the #user{} record term of UserRecord for erlang.Marshal
+// (a value receiver so both UserRecord and *UserRecord are erlang.Marshaler)
+func (v UserRecord) MarshalErlang() (interface{}, error) {
+	b, err := v.AppendErlang(nil)
+	if err != nil {
+		return nil, err
+	}
+	return erlang.RawTerm(b), nil
+}
+
+// UnmarshalErlang stores the #user{} record term in UserRecord for erlang.Unmarshal
+func (v *UserRecord) UnmarshalErlang(term interface{}) error {
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalErlangBinary(data)
+}
+
+// AppendErlang appends the #group{} record term of GroupRecord
+// (in the Erlang External Term Format without the version tag)
+func (v *GroupRecord) AppendErlang(b []byte) ([]byte, error) {
+	var err error
+	b, err = erlang.AppendTupleHeader(b, 3)
+	if err != nil {
+		return b, err
+	}
+	b = append(b, "\x73\x05group"...)
+	b, err = erlang.AppendString(b, v.Name)
+	if err != nil {
+		return b, &erlang.RecordError{Record: "group", Field: "name", Err: err}
+	}
+	if len(v.Users) == 0 {
+		b = erlang.AppendNil(b)
+	} else {
+		b, err = erlang.AppendListHeader(b, len(v.Users))
+		if err != nil {
+			return b, &erlang.RecordError{Record: "group", Field: "users", Err: err}
+		}
+		for i1 := range v.Users {
+			b, err = v.Users[i1].AppendErlang(b)
+			if err != nil {
+				return b, &erlang.RecordError{Record: "group", Field: "users", Err: err}
+			}
+		}
+		b = erlang.AppendNil(b)
+	}
+	return b, nil
+}
+
+// ReadErlang reads the #group{} record term of GroupRecord
+func (v *GroupRecord) ReadErlang(r *erlang.TermReader) error {
+	err := r.Record("group", 3)
+	if err != nil {
+		return err
+	}
+	if !r.Undefined() {
+		v.Name, err = r.String()
+		if err != nil {
+			return &erlang.RecordError{Record: "group", Field: "name", Err: err}
+		}
+	}
+	if !r.Undefined() {
+		var length1 int
+		length1, err = r.ListHeader()
+		if err != nil {
+			return &erlang.RecordError{Record: "group", Field: "users", Err: err}
+		}
+		v.Users = make([]UserRecord, length1)
+		for i1 := range v.Users {
+			if !r.Undefined() {
+				err = v.Users[i1].ReadErlang(r)
+				if err != nil {
+					return &erlang.RecordError{Record: "group", Field: "users", Err: err}
+				}
+			}
+		}
+		err = r.ListEnd()
+		if err != nil {
+			return &erlang.RecordError{Record: "group", Field: "users", Err: err}
+		}
+	}
+	return nil
+}
+
+// MarshalErlangBinary encodes GroupRecord in the Erlang External Term Format
+func (v *GroupRecord) MarshalErlangBinary() ([]byte, error) {
+	return v.AppendErlang(erlang.AppendVersion(nil))
+}
+
+// UnmarshalErlangBinary decodes GroupRecord from the Erlang External Term Format
+func (v *GroupRecord) UnmarshalErlangBinary(data []byte) error {
+	r, err := erlang.NewTermReader(data)
+	if err != nil {
+		return err
+	}
+	err = v.ReadErlang(r)
+	if err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// MarshalErlang provides the #group{} record term of GroupRecord for erlang.Marshal
+// (a value receiver so both GroupRecord and *GroupRecord are erlang.Marshaler)
+func (v GroupRecord) MarshalErlang() (interface{}, error) {
+	b, err := v.AppendErlang(nil)
+	if err != nil {
+		return nil, err
+	}
+	return erlang.RawTerm(b), nil
+}
+
+// UnmarshalErlang stores the #group{} record term in GroupRecord for erlang.Unmarshal
+func (v *GroupRecord) UnmarshalErlang(term interface{}) error {
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalErlangBinary(data)
+}
@@ -0,0 +1,93 @@
+// Command erlgen generates Erlang External Term Format encoding and
+// decoding methods for Go structs without reflection
+//
+// Usage:
+//
+//	//go:generate go run github.com/okeuday/erlang_go/v2/cmd/erlgen -output types_erlang.go types.go
+//
+// A struct is generated when its doc comment has a directive line:
+//
+//	//erlang:map           a map with atom keys (like erlang.Marshal)
+//	//erlang:tuple         a tuple of the fields in declaration order
+//	//erlang:record name   a record tuple {name, Field1, ...}
+//
+// The record name is the name of the directive, the tag of an embedded
+// erlang.Record or the struct name in snake_case.
+//
+// The struct field tag `erlang:"name,omitempty"` is used as it is
+// by erlang.Marshal.  Field types without specialized code (e.g.,
+// interface{}) use erlang.MarshalTerm and erlang.UnmarshalTerm.
+//
+// The generated methods are AppendErlang, ReadErlang, MarshalErlangBinary
+// and UnmarshalErlangBinary, with MarshalErlang and UnmarshalErlang so
+// erlang.Marshal and erlang.Unmarshal use the same encoding.
+package main
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	output := flag.String("output", "", "output file (default stdout)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [options] file.go ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	err := run(*output, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "erlgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(output string, files []string) error {
+	sources := make(map[string][]byte, len(files))
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sources[file] = source
+	}
+	code, err := generate(files, sources)
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(output, code, 0644)
+}
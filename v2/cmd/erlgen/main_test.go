@@ -0,0 +1,187 @@
+package main
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+const testTypes = `package types
+
+import (
+	"time"
+
+	erl "github.com/okeuday/erlang_go/v2/erlang"
+)
+
+//erlang:tuple
+type Point struct {
+	X, Y int32
+}
+
+// User is not a record
+//
+//erlang:map
+type User struct {
+	UserID   int64
+	Name     string
+	Email    []byte ` + "`erlang:\",omitempty\"`" + `
+	Tags     []string
+	Scores   map[string]float64
+	Location *Point
+	Role     erl.OtpErlangAtom
+	Ratio    float32
+	Ignored  int ` + "`erlang:\"-\"`" + `
+	When     time.Time
+	private  int
+}
+
+//erlang:record user
+type UserRecord struct {
+	ID  int
+	Sub Point
+}
+
+//erlang:record
+type GroupRecord struct {
+	erl.Record ` + "`erlang:\"group\"`" + `
+	Name       string
+}
+
+type Other struct {
+	Value int
+}
+`
+
+func testGenerate(t *testing.T, source string) (string, error) {
+	code, err := generate([]string{"types.go"},
+		map[string][]byte{"types.go": []byte(source)})
+	return string(code), err
+}
+
+func TestGenerate(t *testing.T) {
+	source, err := testGenerate(t, testTypes)
+	assertEqual(t, nil, err, "")
+	for _, expect := range []string{
+		"// Code generated by erlgen from types.go. DO NOT EDIT.\n",
+		"package types\n",
+		"func (v *Point) AppendErlang(b []byte) ([]byte, error) {\n",
+		"\tb, err = erlang.AppendTupleHeader(b, 2)\n",
+		"\tb = erlang.AppendInt(b, int64(v.X))\n",
+		"\terr := r.Tuple(2)\n",
+		"\t\tv.X = int32(integer)\n",
+		"\tsize := 9\n\tif v.Email == nil {\n\t\tsize -= 1\n\t}\n",
+		"\tb = append(b, \"\\x73\\x07user_id\"...)\n",
+		"\tif v.Email != nil {\n",
+		"\t\tcase \"user_id\":\n\t\t\tif !r.Undefined() {\n" +
+			"\t\t\t\tv.UserID, err = r.Int(64)\n",
+		"\t\t\t\tv.Tags = make([]string, length1)\n",
+		"\t\t\t\tv.Scores = make(map[string]float64, size1)\n",
+		"\t\tb, err = v.Location.AppendErlang(b)\n",
+		"\t\t\t\terr = v.Location.ReadErlang(r)\n",
+		"\tb, err = erlang.AppendAtom(b, string(v.Role))\n",
+		"\t\t\t\tv.Role = erlang.OtpErlangAtom(text)\n",
+		"\t\t\t\tfloat, err = r.Float(32)\n",
+		"\t\tterm, err = erlang.MarshalTerm(v.When)\n",
+		"\t\t\t\terr = erlang.UnmarshalTerm(term, &v.When)\n",
+		"\tb = append(b, \"\\x73\\x04user\"...)\n",
+		"\terr := r.Record(\"user\", 3)\n",
+		"\t\treturn b, &erlang.RecordError{Record: \"user\", Field: \"sub\", Err: err}\n",
+		"\tb = append(b, \"\\x73\\x05group\"...)\n",
+		"\terr := r.Record(\"group\", 2)\n",
+		"func (v *UserRecord) MarshalErlangBinary() ([]byte, error) {\n",
+		"func (v *UserRecord) UnmarshalErlangBinary(data []byte) error {\n",
+		"func (v UserRecord) MarshalErlang() (interface{}, error) {\n",
+		"\treturn erlang.RawTerm(b), nil\n",
+		"func (v *UserRecord) UnmarshalErlang(term interface{}) error {\n",
+	} {
+		if !strings.Contains(source, expect) {
+			t.Fatalf("missing %q in:\n%s", expect, source)
+		}
+	}
+	for _, unexpected := range []string{"Ignored", "private", "Other"} {
+		if strings.Contains(source, unexpected) {
+			t.Fatalf("unexpected %q in:\n%s", unexpected, source)
+		}
+	}
+}
+
+// the fixture package compiles the generated code and
+// compares it with erlang.Marshal
+func TestGenerateFixture(t *testing.T) {
+	file := filepath.Join("internal", "fixture", "types.go")
+	source, err := os.ReadFile(file)
+	assertEqual(t, nil, err, "")
+	code, err := generate([]string{file}, map[string][]byte{file: source})
+	assertEqual(t, nil, err, "")
+	expect, err := os.ReadFile(filepath.Join("internal", "fixture",
+		"types_erlang.go"))
+	assertEqual(t, nil, err, "")
+	if string(expect) != string(code) {
+		t.Fatal("internal/fixture/types_erlang.go is not current " +
+			"(run go generate)")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		expect string
+	}{
+		{"package x\n//erlang:list\ntype X struct{}\n",
+			"types.go:3:6: invalid directive //erlang:list"},
+		{"package x\n//erlang:tuple\ntype X struct{ A int `erlang:\",omitempty\"` }\n",
+			"types.go:3:6: A: omitempty requires //erlang:map"},
+		{"package x\n//erlang:map\ntype X struct{ A struct{} `erlang:\",omitempty\"` }\n",
+			"types.go:3:6: A: omitempty not supported for type "},
+		{"package x\ntype Y struct{}\n//erlang:map\ntype X struct{ Y }\n",
+			"types.go:4:6: embedded field not supported"},
+		{"package x\nimport \"github.com/okeuday/erlang_go/v2/erlang\"\n" +
+			"//erlang:map\ntype X struct{ erlang.Record }\n",
+			"types.go:4:6: erlang.Record requires //erlang:record"},
+		{"package x\n//erlang:map\ntype X[T any] struct{ A T }\n",
+			"types.go:3:6: type parameters not supported"},
+	}
+	for _, test := range tests {
+		_, err := testGenerate(t, test.source)
+		assertEqual(t, test.expect, err.Error(), test.source)
+	}
+}
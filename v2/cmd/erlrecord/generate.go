@@ -0,0 +1,369 @@
+package main
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+const (
+	typeAny       = "interface{}"
+	typeAtom      = "erlang.OtpErlangAtom"
+	typeBool      = "bool"
+	typeBytes     = "[]byte"
+	typeFloat     = "float64"
+	typeInteger   = "int64"
+	typeString    = "string"
+	typeTuple     = "erlang.OtpErlangTuple"
+	typeMap       = "erlang.OtpErlangMap"
+	typePid       = "erlang.OtpErlangPid"
+	typePort      = "erlang.OtpErlangPort"
+	typeReference = "erlang.OtpErlangReference"
+)
+
+// builtin Erlang types with a Go type
+var builtinTypes = map[string]string{
+	"integer":                      typeInteger,
+	"non_neg_integer":              typeInteger,
+	"pos_integer":                  typeInteger,
+	"neg_integer":                  typeInteger,
+	"char":                         typeInteger,
+	"byte":                         typeInteger,
+	"arity":                        typeInteger,
+	"timeout":                      typeAny,
+	"float":                        typeFloat,
+	"boolean":                      typeBool,
+	"binary":                       typeBytes,
+	"bitstring":                    typeBytes,
+	"nonempty_binary":              typeBytes,
+	"iodata":                       typeAny,
+	"string":                       typeString,
+	"nonempty_string":              typeString,
+	"atom":                         typeAtom,
+	"module":                       typeAtom,
+	"node":                         typeAtom,
+	"pid":                          typePid,
+	"port":                         typePort,
+	"reference":                    typeReference,
+	"tuple":                        typeTuple,
+	"map":                          typeMap,
+	"list":                         "[]" + typeAny,
+	"nonempty_list":                "[]" + typeAny,
+	"term":                         typeAny,
+	"any":                          typeAny,
+	"number":                       typeAny,
+	"mfa":                          typeTuple,
+	"identifier":                   typeAny,
+	"nonempty_maybe_improper_list": typeAny,
+}
+
+// generator creates Go source for Erlang records
+type generator struct {
+	packageName string
+	prefix      string
+	records     map[string]bool
+	buffer      bytes.Buffer
+}
+
+func generate(packageName, prefix, source string, records []record) ([]byte, error) {
+	g := &generator{
+		packageName: packageName,
+		prefix:      prefix,
+		records:     make(map[string]bool),
+	}
+	for _, r := range records {
+		g.records[r.name] = true
+	}
+	g.printf("// Code generated by erlrecord from %s. DO NOT EDIT.\n\n", source)
+	g.printf("package %s\n\n", packageName)
+	g.printf("import (\n\t\"github.com/okeuday/erlang_go/v2/erlang\"\n)\n")
+	for _, r := range records {
+		g.record(r)
+	}
+	return format.Source(g.buffer.Bytes())
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buffer, format, args...)
+}
+
+func (g *generator) typeName(name string) string {
+	return g.prefix + camelCase(name)
+}
+
+func (g *generator) record(r record) {
+	name := g.typeName(r.name)
+	arity := len(r.fields) + 1
+	quoted := strconv.Quote(r.name)
+	goTypes := make([]string, len(r.fields))
+	for i, f := range r.fields {
+		goTypes[i] = g.goType(f.typeDef)
+	}
+
+	g.printf("\n// %s is the #%s{} record\n", name, r.name)
+	g.printf("type %s struct {\n", name)
+	for i, f := range r.fields {
+		g.printf("\t%s %s `erlang:%s`\n",
+			camelCase(f.name), goTypes[i], strconv.Quote(f.name))
+	}
+	g.printf("}\n")
+
+	g.printf("\n// New%s provides a #%s{} record with the default values\n",
+		name, r.name)
+	g.printf("func New%s() *%s {\n\tr := &%s{}\n", name, name, name)
+	for i, f := range r.fields {
+		value, ok := g.goValue(f.value, goTypes[i])
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(goTypes[i], "*") {
+			g.printf("\t{\n\t\tvalue := %s(%s)\n\t\tr.%s = &value\n\t}\n",
+				goTypes[i][1:], value, camelCase(f.name))
+		} else {
+			g.printf("\tr.%s = %s\n", camelCase(f.name), value)
+		}
+	}
+	g.printf("\treturn r\n}\n")
+
+	g.printf("\n// ToTuple provides the #%s{} record tuple\n", r.name)
+	g.printf("func (r %s) ToTuple() (erlang.OtpErlangTuple, error) {\n", name)
+	g.printf("\ttuple := make(erlang.OtpErlangTuple, %d)\n", arity)
+	g.printf("\ttuple[0] = erlang.OtpErlangAtom(%s)\n", quoted)
+	if len(r.fields) > 0 {
+		g.printf("\tvar err error\n")
+	}
+	for i, f := range r.fields {
+		g.printf("\ttuple[%d], err = erlang.MarshalTerm(r.%s)\n",
+			i+1, camelCase(f.name))
+		g.printf("\tif err != nil {\n\t\treturn nil, &erlang.RecordError{"+
+			"Record: %s, Field: %s, Err: err}\n\t}\n",
+			quoted, strconv.Quote(f.name))
+	}
+	g.printf("\treturn tuple, nil\n}\n")
+
+	g.printf("\n// FromTuple stores the #%s{} record tuple\n", r.name)
+	g.printf("func (r *%s) FromTuple(tuple erlang.OtpErlangTuple) error {\n", name)
+	g.printf("\t_, err := erlang.RecordCheck(tuple, %s, %d)\n", quoted, arity)
+	g.printf("\tif err != nil {\n\t\treturn err\n\t}\n")
+	for i, f := range r.fields {
+		g.printf("\terr = erlang.UnmarshalTerm(tuple[%d], &r.%s)\n",
+			i+1, camelCase(f.name))
+		g.printf("\tif err != nil {\n\t\treturn &erlang.RecordError{"+
+			"Record: %s, Field: %s, Err: err}\n\t}\n",
+			quoted, strconv.Quote(f.name))
+	}
+	g.printf("\treturn nil\n}\n")
+
+	g.printf("\n// MarshalErlang provides the #%s{} record tuple\n", r.name)
+	g.printf("func (r %s) MarshalErlang() (interface{}, error) {\n", name)
+	g.printf("\treturn r.ToTuple()\n}\n")
+
+	g.printf("\n// UnmarshalErlang stores the #%s{} record tuple\n", r.name)
+	g.printf("func (r *%s) UnmarshalErlang(term interface{}) error {\n", name)
+	g.printf("\ttuple, err := erlang.RecordCheck(term, %s, %d)\n", quoted, arity)
+	g.printf("\tif err != nil {\n\t\treturn err\n\t}\n")
+	g.printf("\treturn r.FromTuple(tuple)\n}\n")
+}
+
+// goType provides the Go type of an Erlang type expression
+// (a union with undefined provides a pointer type)
+func (g *generator) goType(typeDef []token) string {
+	if len(typeDef) == 0 {
+		return typeAny
+	}
+	alternatives := split(typeDef, "|")
+	nullable := false
+	result := ""
+	for _, alternative := range alternatives {
+		if len(alternative) == 1 && alternative[0].kind == tokenAtom &&
+			alternative[0].text == "undefined" {
+			nullable = true
+			continue
+		}
+		goType := g.goTypeSingle(alternative)
+		if len(result) == 0 {
+			result = goType
+		} else if result != goType {
+			return typeAny
+		}
+	}
+	if len(result) == 0 || result == typeAny {
+		return typeAny
+	}
+	if nullable {
+		return "*" + result
+	}
+	return result
+}
+
+func (g *generator) goTypeSingle(tokens []token) string {
+	switch {
+	case len(tokens) == 1 && tokens[0].kind == tokenAtom:
+		if tokens[0].text == "true" || tokens[0].text == "false" {
+			return typeBool
+		}
+		return typeAtom
+	case len(tokens) >= 1 && tokens[0].kind == tokenInteger,
+		len(tokens) >= 2 && isPunct(tokens[0], "-") &&
+			tokens[1].kind == tokenInteger:
+		// integer or range
+		return typeInteger
+	case len(tokens) >= 3 && tokens[0].kind == tokenAtom &&
+		isPunct(tokens[1], "("):
+		name := tokens[0].text
+		arguments := tokens[2 : len(tokens)-1]
+		if (name == "list" || name == "nonempty_list") && len(arguments) > 0 {
+			return "[]" + g.goType(arguments)
+		}
+		if goType, ok := builtinTypes[name]; ok {
+			return goType
+		}
+		return typeAny
+	case len(tokens) >= 2 && isPunct(tokens[0], "["):
+		if len(tokens) == 2 {
+			return "[]" + typeAny
+		}
+		elements := split(tokens[1:len(tokens)-1], ",")
+		return "[]" + g.goType(elements[0])
+	case len(tokens) >= 2 && isPunct(tokens[0], "<<"):
+		return typeBytes
+	case len(tokens) >= 2 && isPunct(tokens[0], "{"):
+		return typeTuple
+	case len(tokens) >= 3 && isPunct(tokens[0], "#") && isPunct(tokens[1], "{"):
+		return typeMap
+	case len(tokens) >= 4 && isPunct(tokens[0], "#") &&
+		tokens[1].kind == tokenAtom && isPunct(tokens[2], "{"):
+		if g.records[tokens[1].text] {
+			return g.typeName(tokens[1].text)
+		}
+		return typeTuple
+	default:
+		return typeAny
+	}
+}
+
+// goValue provides the Go expression of a literal default value
+func (g *generator) goValue(value []token, goType string) (string, bool) {
+	if len(value) == 0 {
+		return "", false
+	}
+	goType = strings.TrimPrefix(goType, "*")
+	negative := false
+	if len(value) == 2 && isPunct(value[0], "-") {
+		negative = true
+		value = value[1:]
+	}
+	if len(value) == 3 && isPunct(value[0], "<<") && isPunct(value[2], ">>") &&
+		value[1].kind == tokenString {
+		value = value[1:2]
+		if goType == typeBytes || goType == typeAny {
+			return "[]byte(" + strconv.Quote(value[0].text) + ")", true
+		}
+		return "", false
+	}
+	if len(value) != 1 {
+		return "", false
+	}
+	t := value[0]
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+	switch t.kind {
+	case tokenInteger:
+		integer, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil && strings.Contains(t.text, "#") {
+			parts := strings.SplitN(t.text, "#", 2)
+			base, _ := strconv.Atoi(parts[0])
+			integer, err = strconv.ParseInt(parts[1], base, 64)
+		}
+		if err != nil {
+			return "", false
+		}
+		switch goType {
+		case typeInteger, typeAny:
+			return sign + strconv.FormatInt(integer, 10), true
+		case typeFloat:
+			return sign + strconv.FormatInt(integer, 10) + ".0", true
+		}
+	case tokenFloat:
+		if goType == typeFloat || goType == typeAny {
+			return sign + t.text, true
+		}
+	case tokenString:
+		if negative {
+			return "", false
+		}
+		switch goType {
+		case typeString, typeAny:
+			return strconv.Quote(t.text), true
+		}
+	case tokenAtom:
+		if negative || t.text == "undefined" {
+			return "", false
+		}
+		if t.text == "true" || t.text == "false" {
+			if goType == typeBool || goType == typeAny {
+				return t.text, true
+			}
+			return "", false
+		}
+		switch goType {
+		case typeAtom, typeAny:
+			return "erlang.OtpErlangAtom(" + strconv.Quote(t.text) + ")", true
+		}
+	}
+	return "", false
+}
+
+var initialisms = map[string]string{
+	"api": "API", "id": "ID", "ip": "IP", "json": "JSON", "http": "HTTP",
+	"tcp": "TCP", "udp": "UDP", "uri": "URI", "url": "URL", "uuid": "UUID",
+}
+
+// camelCase converts an Erlang name (e.g., user_id) into an exported
+// Go name (e.g., UserID)
+func camelCase(name string) string {
+	var result strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(c rune) bool {
+		return c == '_' || c == '-' || c == '.' || c == '@' || c == ' '
+	}) {
+		if initialism, ok := initialisms[part]; ok {
+			result.WriteString(initialism)
+			continue
+		}
+		characters := []rune(part)
+		result.WriteString(strings.ToUpper(string(characters[0])))
+		result.WriteString(string(characters[1:]))
+	}
+	if result.Len() == 0 {
+		return "Record"
+	}
+	return result.String()
+}
@@ -0,0 +1,100 @@
+package fixture
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+
+//go:generate go run github.com/okeuday/erlang_go/v2/cmd/erlrecord -package fixture -output records.go records.hrl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func TestTuple(t *testing.T) {
+	email := "user@example.com"
+	user := NewUser()
+	user.ID = 7
+	user.Email = &email
+	user.Tags = []erlang.OtpErlangAtom{"a", "b"}
+	user.Location = &Point{X: 1, Y: -2}
+	user.Extra = erlang.OtpErlangAtom("extra")
+	user.Options = erlang.OtpErlangMap{
+		erlang.OtpErlangAtom("k"): erlang.OtpErlangAtom("v")}
+	tuple, err := user.ToTuple()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 13, len(tuple), "")
+	assertEqual(t, erlang.OtpErlangAtom("user"), tuple[0], "")
+	var result User
+	assertEqual(t, nil, result.FromTuple(tuple), "")
+	assertEqual(t, *user, result, "")
+
+	// the Marshaler methods are used by TermToBinary and Marshal
+	data, err := erlang.TermToBinary(user, -1)
+	assertEqual(t, nil, err, "")
+	expect, err := erlang.Marshal(*user)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	result = User{}
+	assertEqual(t, nil, erlang.Unmarshal(data, &result), "")
+	assertEqual(t, *user, result, "")
+
+	// the defaults
+	data, err = erlang.TermToBinary(NewUser(), -1)
+	assertEqual(t, nil, err, "")
+	result = User{}
+	assertEqual(t, nil, erlang.Unmarshal(data, &result), "")
+	age := int64(3)
+	assertEqual(t, User{Name: []byte("anonymous"), Age: &age,
+		Role: "guest", Active: true, Score: -1.5,
+		Tags: []erlang.OtpErlangAtom{}, UserURL: "http://",
+		Options: erlang.OtpErlangMap{}}, result, "")
+}
+
+func TestTupleError(t *testing.T) {
+	var point Point
+	err := point.FromTuple(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("point"), uint8(1)})
+	assertEqual(t, "#point{}: arity 2 != 3", err.Error(), "")
+	err = point.FromTuple(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("point"), uint8(1), "y"})
+	var recordError *erlang.RecordError
+	assertEqual(t, true, errors.As(err, &recordError), "")
+	assertEqual(t, "y", recordError.Field, "")
+}
@@ -0,0 +1,226 @@
+// Code generated by erlrecord from records.hrl. DO NOT EDIT.
+
+package fixture
+
+import (
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// Point is the #point{} record
+type Point struct {
+	X int64 `erlang:"x"`
+	Y int64 `erlang:"y"`
+}
+
+// NewPoint provides a #point{} record with the default values
+func NewPoint() *Point {
+	r := &Point{}
+	r.X = 0
+	r.Y = 0
+	return r
+}
+
+// ToTuple provides the #point{} record tuple
+func (r Point) ToTuple() (erlang.OtpErlangTuple, error) {
+	tuple := make(erlang.OtpErlangTuple, 3)
+	tuple[0] = erlang.OtpErlangAtom("point")
+	var err error
+	tuple[1], err = erlang.MarshalTerm(r.X)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "point", Field: "x", Err: err}
+	}
+	tuple[2], err = erlang.MarshalTerm(r.Y)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "point", Field: "y", Err: err}
+	}
+	return tuple, nil
+}
+
+// FromTuple stores the #point{} record tuple
+func (r *Point) FromTuple(tuple erlang.OtpErlangTuple) error {
+	_, err := erlang.RecordCheck(tuple, "point", 3)
+	if err != nil {
+		return err
+	}
+	err = erlang.UnmarshalTerm(tuple[1], &r.X)
+	if err != nil {
+		return &erlang.RecordError{Record: "point", Field: "x", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[2], &r.Y)
+	if err != nil {
+		return &erlang.RecordError{Record: "point", Field: "y", Err: err}
+	}
+	return nil
+}
+
+// MarshalErlang provides the #point{} record tuple
+func (r Point) MarshalErlang() (interface{}, error) {
+	return r.ToTuple()
+}
+
+// UnmarshalErlang stores the #point{} record tuple
+func (r *Point) UnmarshalErlang(term interface{}) error {
+	tuple, err := erlang.RecordCheck(term, "point", 3)
+	if err != nil {
+		return err
+	}
+	return r.FromTuple(tuple)
+}
+
+// User is the #user{} record
+type User struct {
+	ID       int64                  `erlang:"id"`
+	Name     []byte                 `erlang:"name"`
+	Email    *string                `erlang:"email"`
+	Age      *int64                 `erlang:"age"`
+	Role     erlang.OtpErlangAtom   `erlang:"role"`
+	Active   bool                   `erlang:"active"`
+	Score    float64                `erlang:"score"`
+	Tags     []erlang.OtpErlangAtom `erlang:"tags"`
+	Location *Point                 `erlang:"location"`
+	UserURL  string                 `erlang:"user-url"`
+	Extra    interface{}            `erlang:"extra"`
+	Options  erlang.OtpErlangMap    `erlang:"options"`
+}
+
+// NewUser provides a #user{} record with the default values
+func NewUser() *User {
+	r := &User{}
+	r.Name = []byte("anonymous")
+	{
+		value := int64(3)
+		r.Age = &value
+	}
+	r.Role = erlang.OtpErlangAtom("guest")
+	r.Active = true
+	r.Score = -1.5
+	r.UserURL = "http://"
+	return r
+}
+
+// ToTuple provides the #user{} record tuple
+func (r User) ToTuple() (erlang.OtpErlangTuple, error) {
+	tuple := make(erlang.OtpErlangTuple, 13)
+	tuple[0] = erlang.OtpErlangAtom("user")
+	var err error
+	tuple[1], err = erlang.MarshalTerm(r.ID)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "id", Err: err}
+	}
+	tuple[2], err = erlang.MarshalTerm(r.Name)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "name", Err: err}
+	}
+	tuple[3], err = erlang.MarshalTerm(r.Email)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "email", Err: err}
+	}
+	tuple[4], err = erlang.MarshalTerm(r.Age)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "age", Err: err}
+	}
+	tuple[5], err = erlang.MarshalTerm(r.Role)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "role", Err: err}
+	}
+	tuple[6], err = erlang.MarshalTerm(r.Active)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "active", Err: err}
+	}
+	tuple[7], err = erlang.MarshalTerm(r.Score)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "score", Err: err}
+	}
+	tuple[8], err = erlang.MarshalTerm(r.Tags)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "tags", Err: err}
+	}
+	tuple[9], err = erlang.MarshalTerm(r.Location)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "location", Err: err}
+	}
+	tuple[10], err = erlang.MarshalTerm(r.UserURL)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "user-url", Err: err}
+	}
+	tuple[11], err = erlang.MarshalTerm(r.Extra)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "extra", Err: err}
+	}
+	tuple[12], err = erlang.MarshalTerm(r.Options)
+	if err != nil {
+		return nil, &erlang.RecordError{Record: "user", Field: "options", Err: err}
+	}
+	return tuple, nil
+}
+
+// FromTuple stores the #user{} record tuple
+func (r *User) FromTuple(tuple erlang.OtpErlangTuple) error {
+	_, err := erlang.RecordCheck(tuple, "user", 13)
+	if err != nil {
+		return err
+	}
+	err = erlang.UnmarshalTerm(tuple[1], &r.ID)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "id", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[2], &r.Name)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "name", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[3], &r.Email)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "email", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[4], &r.Age)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "age", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[5], &r.Role)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "role", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[6], &r.Active)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "active", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[7], &r.Score)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "score", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[8], &r.Tags)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "tags", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[9], &r.Location)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "location", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[10], &r.UserURL)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "user-url", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[11], &r.Extra)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "extra", Err: err}
+	}
+	err = erlang.UnmarshalTerm(tuple[12], &r.Options)
+	if err != nil {
+		return &erlang.RecordError{Record: "user", Field: "options", Err: err}
+	}
+	return nil
+}
+
+// MarshalErlang provides the #user{} record tuple
+func (r User) MarshalErlang() (interface{}, error) {
+	return r.ToTuple()
+}
+
+// UnmarshalErlang stores the #user{} record tuple
+func (r *User) UnmarshalErlang(term interface{}) error {
+	tuple, err := erlang.RecordCheck(term, "user", 13)
+	if err != nil {
+		return err
+	}
+	return r.FromTuple(tuple)
+}
@@ -0,0 +1,92 @@
+// Command erlrecord generates Go structs from the Erlang records of
+// .hrl files, with methods for the record tuple conversion
+//
+// Usage:
+//
+//	//go:generate go run github.com/okeuday/erlang_go/v2/cmd/erlrecord -package x -output records.go records.hrl
+//
+// Each record field type is used for the Go field type (an untyped field
+// is interface{} and a union with undefined is a pointer) and literal
+// default values are set by the New function of the struct.
+package main
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	packageName := flag.String("package", "main", "Go package name")
+	output := flag.String("output", "", "output file (default stdout)")
+	prefix := flag.String("prefix", "", "Go type name prefix")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [options] file.hrl ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	err := run(*packageName, *output, *prefix, flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "erlrecord:", err)
+		os.Exit(1)
+	}
+}
+
+func run(packageName, output, prefix string, files []string) error {
+	var records []record
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var parsed []record
+		parsed, err = parseRecords(string(source))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		records = append(records, parsed...)
+		names = append(names, filepath.Base(file))
+	}
+	code, err := generate(packageName, prefix,
+		strings.Join(names, ", "), records)
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(output, code, 0644)
+}
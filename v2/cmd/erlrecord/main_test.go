@@ -0,0 +1,185 @@
+package main
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+const testRecords = `
+%% comment with -record(ignored, {}).
+-define(DEFAULT, 1).
+-record(point, {x = 0 :: integer(), y = 0 :: integer()}).
+-record(user, {
+    id :: non_neg_integer(),
+    name = <<"anonymous">> :: binary(),
+    email :: string() | undefined,
+    age = 3 :: 0..150 | undefined,
+    role = guest :: admin | guest,
+    active = true :: boolean(),
+    score = -1.5 :: float(),
+    tags = [] :: [atom()],
+    location :: #point{} | undefined,
+    'user-url' = "http://" :: string(),
+    extra,
+    options = #{} :: #{atom() => term()}
+}).
+`
+
+func TestParseRecords(t *testing.T) {
+	records, err := parseRecords(testRecords)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 2, len(records), "")
+	assertEqual(t, "point", records[0].name, "")
+	assertEqual(t, 2, len(records[0].fields), "")
+	assertEqual(t, "user", records[1].name, "")
+	names := make([]string, 0, len(records[1].fields))
+	for _, f := range records[1].fields {
+		names = append(names, f.name)
+	}
+	assertEqual(t, []string{"id", "name", "email", "age", "role", "active",
+		"score", "tags", "location", "user-url", "extra", "options"},
+		names, "")
+	assertEqual(t, 0, len(records[1].fields[10].value), "")
+	assertEqual(t, 0, len(records[1].fields[10].typeDef), "")
+
+	_, err = parseRecords("-record(broken, {a = }).")
+	assertEqual(t, "line 1: invalid record field", err.Error(), "")
+	_, err = parseRecords("-record(broken, {a}")
+	assertEqual(t, "line 1: invalid record", err.Error(), "")
+	_, err = parseRecords("-record(broken, {a = \"x}).")
+	assertEqual(t, true, err != nil, "")
+}
+
+func TestGoType(t *testing.T) {
+	g := &generator{records: map[string]bool{"point": true}}
+	tests := []struct {
+		typeDef string
+		expect  string
+	}{
+		{"", "interface{}"},
+		{"integer()", "int64"},
+		{"1..10", "int64"},
+		{"-1 | 1", "int64"},
+		{"float()", "float64"},
+		{"binary()", "[]byte"},
+		{"<<_:8>>", "[]byte"},
+		{"string()", "string"},
+		{"boolean()", "bool"},
+		{"ok | error", "erlang.OtpErlangAtom"},
+		{"pid() | undefined", "*erlang.OtpErlangPid"},
+		{"[binary()]", "[][]byte"},
+		{"list(integer())", "[]int64"},
+		{"{integer(), integer()}", "erlang.OtpErlangTuple"},
+		{"#point{}", "Point"},
+		{"#other{}", "erlang.OtpErlangTuple"},
+		{"#{}", "erlang.OtpErlangMap"},
+		{"integer() | binary()", "interface{}"},
+		{"module:type()", "interface{}"},
+		{"undefined", "interface{}"},
+	}
+	for _, test := range tests {
+		tokens, err := tokenize(test.typeDef)
+		assertEqual(t, nil, err, test.typeDef)
+		assertEqual(t, test.expect, g.goType(tokens), test.typeDef)
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	assertEqual(t, "UserID", camelCase("user_id"), "")
+	assertEqual(t, "HTTPProxyURL", camelCase("http_proxy_url"), "")
+	assertEqual(t, "QuotedName", camelCase("quoted-name"), "")
+	assertEqual(t, "Record", camelCase("_"), "")
+}
+
+func TestGenerate(t *testing.T) {
+	records, err := parseRecords(testRecords)
+	assertEqual(t, nil, err, "")
+	code, err := generate("records", "", "test.hrl", records)
+	assertEqual(t, nil, err, "")
+	source := string(code)
+	for _, expect := range []string{
+		"// Code generated by erlrecord from test.hrl. DO NOT EDIT.\n",
+		"package records\n",
+		"type Point struct {\n\tX int64 `erlang:\"x\"`\n",
+		"\tEmail    *string                `erlang:\"email\"`\n",
+		"\tLocation *Point                 `erlang:\"location\"`\n",
+		"\tUserURL  string                 `erlang:\"user-url\"`\n",
+		"\tExtra    interface{}            `erlang:\"extra\"`\n",
+		"\tr.Name = []byte(\"anonymous\")\n",
+		"\t\tvalue := int64(3)\n\t\tr.Age = &value\n",
+		"\tr.Role = erlang.OtpErlangAtom(\"guest\")\n",
+		"\tr.Score = -1.5\n",
+		"\tr.UserURL = \"http://\"\n",
+		"\ttuple[0] = erlang.OtpErlangAtom(\"user\")\n",
+		"\t_, err := erlang.RecordCheck(tuple, \"user\", 13)\n",
+		"return &erlang.RecordError{Record: \"user\", Field: \"email\", Err: err}",
+		"func (r User) MarshalErlang() (interface{}, error) {\n",
+		"func (r *User) UnmarshalErlang(term interface{}) error {\n",
+	} {
+		if !strings.Contains(source, expect) {
+			t.Fatalf("missing %q in:\n%s", expect, source)
+		}
+	}
+	code, err = generate("records", "Erl", "test.hrl", records)
+	assertEqual(t, nil, err, "")
+	source = string(code)
+	assertEqual(t, true, strings.Contains(source,
+		"\tLocation *ErlPoint "), "")
+}
+
+// the fixture package compiles the generated code and
+// tests the record tuple conversion
+func TestGenerateFixture(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "records.go")
+	err := run("fixture", output, "",
+		[]string{filepath.Join("internal", "fixture", "records.hrl")})
+	assertEqual(t, nil, err, "")
+	code, err := os.ReadFile(output)
+	assertEqual(t, nil, err, "")
+	expect, err := os.ReadFile(filepath.Join("internal", "fixture",
+		"records.go"))
+	assertEqual(t, nil, err, "")
+	if string(expect) != string(code) {
+		t.Fatal("internal/fixture/records.go is not current " +
+			"(run go generate)")
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	tokenAtom = iota
+	tokenVar
+	tokenString
+	tokenInteger
+	tokenFloat
+	tokenPunct
+)
+
+type token struct {
+	kind int
+	text string // decoded text of atoms and strings
+	line int
+}
+
+// record is an Erlang -record definition
+type record struct {
+	name   string
+	fields []field
+}
+
+type field struct {
+	name    string
+	value   []token // default value expression (empty if none)
+	typeDef []token // type expression (empty if none)
+}
+
+var punctuation = []string{
+	"=:=", "=/=", "...", "::", "..", "->", "<<", ">>", "=>", ":=", "==",
+	"/=", "=<", ">=", "++", "--", "||", "<-", "<=",
+}
+
+// tokenize provides the tokens of Erlang source (without comments)
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	characters := []rune(source)
+	line := 1
+	for i := 0; i < len(characters); {
+		c := characters[i]
+		switch {
+		case c == '\n':
+			line += 1
+			i += 1
+		case unicode.IsSpace(c):
+			i += 1
+		case c == '%':
+			for i < len(characters) && characters[i] != '\n' {
+				i += 1
+			}
+		case unicode.IsLower(c):
+			start := i
+			for i < len(characters) && isNameCharacter(characters[i]) {
+				i += 1
+			}
+			tokens = append(tokens, token{tokenAtom, string(characters[start:i]), line})
+		case unicode.IsUpper(c) || c == '_':
+			start := i
+			for i < len(characters) && isNameCharacter(characters[i]) {
+				i += 1
+			}
+			tokens = append(tokens, token{tokenVar, string(characters[start:i]), line})
+		case c == '\'' || c == '"':
+			text, end, err := quoted(characters, i)
+			if err != nil {
+				return nil, lineError(line, err)
+			}
+			kind := tokenAtom
+			if c == '"' {
+				kind = tokenString
+			}
+			line += strings.Count(string(characters[i:end]), "\n")
+			tokens = append(tokens, token{kind, text, line})
+			i = end
+		case c == '$':
+			if i+1 >= len(characters) {
+				return nil, lineError(line, errors.New("character literal"))
+			}
+			var value rune
+			end := i + 2
+			if characters[i+1] == '\\' {
+				var err error
+				value, end, err = escape(characters, i+1)
+				if err != nil {
+					return nil, lineError(line, err)
+				}
+			} else {
+				value = characters[i+1]
+			}
+			tokens = append(tokens, token{tokenInteger, strconv.Itoa(int(value)), line})
+			i = end
+		case unicode.IsDigit(c):
+			start := i
+			kind := tokenInteger
+			for i < len(characters) && (unicode.IsDigit(characters[i]) || characters[i] == '_') {
+				i += 1
+			}
+			if i < len(characters) && characters[i] == '#' {
+				// Base#Digits
+				i += 1
+				for i < len(characters) && (isNameCharacter(characters[i])) {
+					i += 1
+				}
+			} else if i+1 < len(characters) && characters[i] == '.' &&
+				unicode.IsDigit(characters[i+1]) {
+				kind = tokenFloat
+				i += 1
+				for i < len(characters) && (unicode.IsDigit(characters[i]) || characters[i] == '_') {
+					i += 1
+				}
+				if i < len(characters) && (characters[i] == 'e' || characters[i] == 'E') {
+					i += 1
+					if i < len(characters) && (characters[i] == '+' || characters[i] == '-') {
+						i += 1
+					}
+					for i < len(characters) && unicode.IsDigit(characters[i]) {
+						i += 1
+					}
+				}
+			}
+			text := strings.Replace(string(characters[start:i]), "_", "", -1)
+			tokens = append(tokens, token{kind, text, line})
+		default:
+			text := string(c)
+			for _, p := range punctuation {
+				if strings.HasPrefix(string(characters[i:minimum(i+len(p), len(characters))]), p) {
+					text = p
+					break
+				}
+			}
+			tokens = append(tokens, token{tokenPunct, text, line})
+			i += len([]rune(text))
+		}
+	}
+	return tokens, nil
+}
+
+func minimum(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func isNameCharacter(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_' || c == '@'
+}
+
+// quoted provides the text of a quoted atom or string at index i
+func quoted(characters []rune, i int) (string, int, error) {
+	quote := characters[i]
+	var text strings.Builder
+	i += 1
+	for i < len(characters) {
+		c := characters[i]
+		switch c {
+		case quote:
+			return text.String(), i + 1, nil
+		case '\\':
+			value, end, err := escape(characters, i)
+			if err != nil {
+				return "", 0, err
+			}
+			text.WriteRune(value)
+			i = end
+		default:
+			text.WriteRune(c)
+			i += 1
+		}
+	}
+	return "", 0, errors.New("unterminated quote")
+}
+
+// escape provides the character of an escape sequence at index i
+func escape(characters []rune, i int) (rune, int, error) {
+	if i+1 >= len(characters) {
+		return 0, 0, errors.New("escape sequence")
+	}
+	c := characters[i+1]
+	switch c {
+	case 'b':
+		return '\b', i + 2, nil
+	case 'd':
+		return 0x7f, i + 2, nil
+	case 'e':
+		return 0x1b, i + 2, nil
+	case 'f':
+		return '\f', i + 2, nil
+	case 'n':
+		return '\n', i + 2, nil
+	case 'r':
+		return '\r', i + 2, nil
+	case 's':
+		return ' ', i + 2, nil
+	case 't':
+		return '\t', i + 2, nil
+	case 'v':
+		return '\v', i + 2, nil
+	case '^':
+		if i+2 >= len(characters) {
+			return 0, 0, errors.New("escape sequence")
+		}
+		return characters[i+2] & 0x1f, i + 3, nil
+	case 'x':
+		end := i + 2
+		if end < len(characters) && characters[end] == '{' {
+			close := end
+			for close < len(characters) && characters[close] != '}' {
+				close += 1
+			}
+			value, err := strconv.ParseInt(string(characters[end+1:close]), 16, 32)
+			if err != nil {
+				return 0, 0, errors.New("escape sequence")
+			}
+			return rune(value), close + 1, nil
+		}
+		value, err := strconv.ParseInt(string(characters[end:minimum(end+2, len(characters))]), 16, 32)
+		if err != nil {
+			return 0, 0, errors.New("escape sequence")
+		}
+		return rune(value), end + 2, nil
+	}
+	if c >= '0' && c <= '7' {
+		end := i + 1
+		for end < len(characters) && end < i+4 && characters[end] >= '0' && characters[end] <= '7' {
+			end += 1
+		}
+		value, _ := strconv.ParseInt(string(characters[i+1:end]), 8, 32)
+		return rune(value), end, nil
+	}
+	return c, i + 2, nil
+}
+
+func lineError(line int, err error) error {
+	return errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+}
+
+// parseRecords provides the -record definitions of Erlang source
+func parseRecords(source string) ([]record, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	var records []record
+	for i := 0; i+5 < len(tokens); i++ {
+		if !isPunct(tokens[i], "-") || tokens[i+1].kind != tokenAtom ||
+			tokens[i+1].text != "record" || !isPunct(tokens[i+2], "(") {
+			continue
+		}
+		line := tokens[i].line
+		if tokens[i+3].kind != tokenAtom || !isPunct(tokens[i+4], ",") ||
+			!isPunct(tokens[i+5], "{") {
+			return nil, lineError(line, errors.New("invalid record"))
+		}
+		r := record{name: tokens[i+3].text}
+		end := closing(tokens, i+5)
+		if end < 0 || end+2 >= len(tokens) || !isPunct(tokens[end+1], ")") ||
+			!isPunct(tokens[end+2], ".") {
+			return nil, lineError(line, errors.New("invalid record"))
+		}
+		for _, fieldTokens := range split(tokens[i+6:end], ",") {
+			if len(fieldTokens) == 0 || fieldTokens[0].kind != tokenAtom {
+				return nil, lineError(line, errors.New("invalid record field"))
+			}
+			f := field{name: fieldTokens[0].text}
+			rest := fieldTokens[1:]
+			typeParts := split(rest, "::")
+			if len(typeParts) > 2 {
+				return nil, lineError(line, errors.New("invalid record field"))
+			}
+			if len(typeParts) == 2 {
+				f.typeDef = typeParts[1]
+			}
+			var value []token
+			if len(typeParts) > 0 {
+				value = typeParts[0]
+			}
+			if len(value) > 0 {
+				if !isPunct(value[0], "=") || len(value) == 1 {
+					return nil, lineError(line, errors.New("invalid record field"))
+				}
+				f.value = value[1:]
+			}
+			r.fields = append(r.fields, f)
+		}
+		records = append(records, r)
+		i = end + 2
+	}
+	return records, nil
+}
+
+func isPunct(t token, text string) bool {
+	return t.kind == tokenPunct && t.text == text
+}
+
+func opening(t token) bool {
+	return t.kind == tokenPunct &&
+		(t.text == "(" || t.text == "[" || t.text == "{" || t.text == "<<")
+}
+
+func closingToken(t token) bool {
+	return t.kind == tokenPunct &&
+		(t.text == ")" || t.text == "]" || t.text == "}" || t.text == ">>")
+}
+
+// closing provides the index of the token that closes the token at index i
+func closing(tokens []token, i int) int {
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		if opening(tokens[j]) {
+			depth += 1
+		} else if closingToken(tokens[j]) {
+			depth -= 1
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// split provides the tokens separated by the punctuation
+// that is not nested
+func split(tokens []token, separator string) [][]token {
+	if len(tokens) == 0 {
+		return nil
+	}
+	var parts [][]token
+	depth := 0
+	start := 0
+	for i, t := range tokens {
+		switch {
+		case opening(t):
+			depth += 1
+		case closingToken(t):
+			depth -= 1
+		case depth == 0 && isPunct(t, separator):
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tokens[start:])
+}
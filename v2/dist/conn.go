@@ -0,0 +1,169 @@
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrFrameSize is returned when a frame is larger than 4 GB
+// or a received frame is larger than the message size limit
+var ErrFrameSize = errors.New("frame size overflow")
+
+// Conn sends and receives distribution messages after the handshake
+type Conn struct {
+	Peer           Peer
+	TickTimeout    time.Duration // receive timeout, if positive
+	FragmentSize   int           // send fragment size, 0 is FragmentSizeDefault
+	MaxMessageSize int           // receive message limit, 0 is MessageSizeDefault
+	conn           net.Conn
+	reader         *bufio.Reader
+	cache          AtomCache
+	reassembler    *Reassembler
+	fragmenter     Fragmenter
+	mutex          sync.Mutex
+}
+
+// NewConn creates a Conn for the connection that completed the handshake
+func NewConn(conn net.Conn, peer Peer) *Conn {
+	c := &Conn{
+		Peer:   peer,
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+	c.reassembler = NewReassembler(&c.cache, 0)
+	return c
+}
+
+// Send sends the message with the format the peer flags allow
+// (a large message is fragmented with FlagFragments)
+func (c *Conn) Send(message Message) error {
+	const flagsFragments = FlagFragments | FlagDistHdrAtomCache
+	if c.Peer.Flags&flagsFragments != flagsFragments {
+		data, err := Encode(message, c.Peer.Flags)
+		if err != nil {
+			return err
+		}
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return WriteFrame(c.conn, data)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fragmenter.Size = c.FragmentSize
+	fragments, err := c.fragmenter.Fragments(message)
+	if err != nil {
+		return err
+	}
+	for _, data := range fragments {
+		err = WriteFrame(c.conn, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tick sends a tick to keep the connection alive
+func (c *Conn) Tick() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return WriteFrame(c.conn, nil)
+}
+
+// Receive provides the next message, ignoring ticks
+func (c *Conn) Receive() (Message, error) {
+	for {
+		if c.TickTimeout > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.TickTimeout))
+		}
+		maxSize := c.MaxMessageSize
+		if maxSize <= 0 {
+			maxSize = MessageSizeDefault
+		}
+		data, err := readFrame(c.reader, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		c.reassembler.maxSize = maxSize
+		var message Message
+		message, err = c.reassembler.Add(data)
+		if err != nil || message != nil {
+			return message, err
+		}
+	}
+}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// ReadFrame reads data with a 4 byte length (a tick is empty)
+func ReadFrame(reader io.Reader) ([]byte, error) {
+	return readFrame(reader, 0)
+}
+
+// readFrame limits the data to maxSize bytes, if positive
+func readFrame(reader io.Reader, maxSize int) ([]byte, error) {
+	var length uint32
+	err := binary.Read(reader, binary.BigEndian, &length)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && uint64(length) > uint64(maxSize) {
+		return nil, ErrFrameSize
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// WriteFrame writes data with a 4 byte length (a tick is empty)
+func WriteFrame(writer io.Writer, data []byte) error {
+	if uint64(len(data)) > math.MaxUint32 {
+		return ErrFrameSize
+	}
+	frame := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	_, err := writer.Write(append(frame, data...))
+	return err
+}
@@ -0,0 +1,92 @@
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"net"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func TestConn(t *testing.T) {
+	connA, connB := net.Pipe()
+	a := NewConn(connA, Peer{Name: "b@host", Flags: FlagsDefault})
+	b := NewConn(connB, Peer{Name: "a@host", Flags: FlagsDefault | FlagDistHdrAtomCache})
+	pid := term(t, "\x83Xw\x06b@host\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x02").(erlang.OtpErlangPid)
+	go func() {
+		a.Tick()
+		a.Send(Send{To: pid, Message: uint8(1)})
+		a.Tick()
+	}()
+	message, err := b.Receive()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, Send{To: pid, Message: uint8(1)}, message, "")
+	go func() {
+		b.Send(RegSend{From: pid, ToName: "name", Message: uint8(2)})
+	}()
+	// a tick is received first
+	message, err = a.Receive()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, RegSend{From: pid, ToName: "name", Message: uint8(2)}, message, "")
+	a.Close()
+	_, err = b.Receive()
+	assertEqual(t, false, err == nil, "")
+}
+
+func TestConnFragments(t *testing.T) {
+	connA, connB := net.Pipe()
+	a := NewConn(connA, Peer{Name: "b@host", Flags: FlagsDefault})
+	b := NewConn(connB, Peer{Name: "a@host", Flags: FlagsDefault})
+	a.FragmentSize = 16
+	pid := term(t, "\x83Xw\x06b@host\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x02").(erlang.OtpErlangPid)
+	message := Send{To: pid, Message: erlang.OtpErlangBinary{Value: make([]byte, 100), Bits: 8}}
+	go func() {
+		a.Send(message)
+		a.Send(message)
+	}()
+	result, err := b.Receive()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, message, result, "")
+	b.MaxMessageSize = 64
+	_, err = b.Receive()
+	assertEqual(t, ErrFragmentSize, err, "")
+	a.Close()
+	b.Close()
+}
+
+func TestConnFrameSize(t *testing.T) {
+	connA, connB := net.Pipe()
+	b := NewConn(connB, Peer{Name: "a@host", Flags: FlagsDefault})
+	b.MaxMessageSize = 64
+	go func() {
+		// only the length is sent, the data is never allocated
+		connA.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}()
+	_, err := b.Receive()
+	assertEqual(t, ErrFrameSize, err, "")
+	connA.Close()
+	b.Close()
+}
@@ -0,0 +1,188 @@
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	tagDistFragHeader = 69
+	tagDistFragCont   = 70
+
+	// FragmentSizeDefault is the Fragmenter size used when Size is 0
+	FragmentSizeDefault = 64 * 1024
+
+	// MessageSizeDefault is the received message limit used when
+	// the limit is not positive
+	MessageSizeDefault = 64 * 1024 * 1024
+
+	// SequencesMax is the number of incomplete fragmented messages
+	// a Reassembler keeps
+	SequencesMax = 256
+)
+
+// Fragment errors
+var (
+	ErrFragment     = errors.New("invalid fragment")
+	ErrFragmentSize = errors.New("fragmented message size limit exceeded")
+	ErrSequences    = errors.New("fragmented message sequence limit exceeded")
+)
+
+// Reassembler combines DIST_FRAG_HEADER and DIST_FRAG_CONT data
+// into messages, with the fragments of each sequence ID interleaved
+type Reassembler struct {
+	cache     *AtomCache
+	maxSize   int
+	sequences map[uint64]*fragmentSequence
+}
+
+type fragmentSequence struct {
+	fragmentID uint64 // next fragment ID
+	refs       []interface{}
+	data       []byte
+}
+
+// NewReassembler creates a Reassembler that uses the connection
+// atom cache and limits the data of each sequence to maxSize bytes
+// (MessageSizeDefault if maxSize is not positive)
+func NewReassembler(cache *AtomCache, maxSize int) *Reassembler {
+	return &Reassembler{
+		cache:     cache,
+		maxSize:   maxSize,
+		sequences: make(map[uint64]*fragmentSequence),
+	}
+}
+
+// Add provides a message when the data completes one
+// (nil is provided for a fragment that does not complete a message
+// and data that is not a fragment is decoded immediately)
+func (r *Reassembler) Add(data []byte) (Message, error) {
+	if len(data) < 2 || data[0] != tagVersion ||
+		(data[1] != tagDistFragHeader && data[1] != tagDistFragCont) {
+		return Decode(data, r.cache)
+	}
+	if len(data) < 18 {
+		return nil, ErrFragment
+	}
+	sequenceID := binary.BigEndian.Uint64(data[2:10])
+	fragmentID := binary.BigEndian.Uint64(data[10:18])
+	if fragmentID == 0 {
+		return nil, ErrFragment
+	}
+	sequence, exists := r.sequences[sequenceID]
+	var i int
+	if data[1] == tagDistFragHeader {
+		if exists {
+			delete(r.sequences, sequenceID)
+			return nil, ErrFragment
+		}
+		if len(r.sequences) >= SequencesMax {
+			return nil, ErrSequences
+		}
+		refs, iNext, err := atomCacheRefs(data, 18, r.cache)
+		if err != nil {
+			return nil, err
+		}
+		i = iNext
+		sequence = &fragmentSequence{fragmentID: fragmentID, refs: refs}
+		r.sequences[sequenceID] = sequence
+	} else {
+		if !exists || sequence.fragmentID != fragmentID {
+			delete(r.sequences, sequenceID)
+			return nil, ErrFragment
+		}
+		i = 18
+	}
+	maxSize := r.maxSize
+	if maxSize <= 0 {
+		maxSize = MessageSizeDefault
+	}
+	if len(sequence.data)+len(data)-i > maxSize {
+		delete(r.sequences, sequenceID)
+		return nil, ErrFragmentSize
+	}
+	sequence.data = append(sequence.data, data[i:]...)
+	sequence.fragmentID -= 1
+	if sequence.fragmentID > 0 {
+		return nil, nil
+	}
+	delete(r.sequences, sequenceID)
+	return decodeTerms(sequence.data, sequence.refs)
+}
+
+// Pending provides the number of incomplete sequences
+func (r *Reassembler) Pending() int {
+	return len(r.sequences)
+}
+
+// Fragmenter splits messages into DIST_FRAG_HEADER and DIST_FRAG_CONT data
+type Fragmenter struct {
+	Size       int // message data bytes in each fragment, 0 is FragmentSizeDefault
+	sequenceID uint64
+}
+
+// Fragments provides the data of each fragment of the message
+// (a message that fits in a single fragment uses a DIST_HEADER)
+func (f *Fragmenter) Fragments(message Message) ([][]byte, error) {
+	data, err := Encode(message, FlagDistHdrAtomCache)
+	if err != nil {
+		return nil, err
+	}
+	size := f.Size
+	if size <= 0 {
+		size = FragmentSizeDefault
+	}
+	// without the DIST_HEADER
+	data = data[3:]
+	if len(data) <= size {
+		return [][]byte{append([]byte{tagVersion, tagDistHeader, 0}, data...)}, nil
+	}
+	f.sequenceID += 1
+	count := uint64((len(data) + size - 1) / size)
+	fragments := make([][]byte, 0, count)
+	for fragmentID := count; fragmentID > 0; fragmentID-- {
+		var fragment []byte
+		if fragmentID == count {
+			fragment = make([]byte, 19, 19+size)
+			fragment[1] = tagDistFragHeader
+			// fragment[18] is 0 atom cache references
+		} else {
+			fragment = make([]byte, 18, 18+size)
+			fragment[1] = tagDistFragCont
+		}
+		fragment[0] = tagVersion
+		binary.BigEndian.PutUint64(fragment[2:10], f.sequenceID)
+		binary.BigEndian.PutUint64(fragment[10:18], fragmentID)
+		length := size
+		if length > len(data) {
+			length = len(data)
+		}
+		fragments = append(fragments, append(fragment, data[:length]...))
+		data = data[length:]
+	}
+	return fragments, nil
+}
@@ -0,0 +1,120 @@
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func TestFragment(t *testing.T) {
+	pid := term(t, "\x83Xw\x06b@host\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x02").(erlang.OtpErlangPid)
+	message1 := Send{To: pid, Message: erlang.OtpErlangBinary{Value: bytes.Repeat([]byte("a"), 100), Bits: 8}}
+	message2 := RegSend{From: pid, ToName: "name", Message: erlang.OtpErlangBinary{Value: bytes.Repeat([]byte("b"), 50), Bits: 8}}
+	fragmenter := Fragmenter{Size: 32}
+	fragments1, err := fragmenter.Fragments(message1)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 5, len(fragments1), "")
+	assertEqual(t, "\x83E\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x05\x00", string(fragments1[0][:19]), "")
+	assertEqual(t, "\x83F\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01", string(fragments1[4][:18]), "")
+	fragments2, err := fragmenter.Fragments(message2)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 3, len(fragments2), "")
+
+	// fragments of each sequence are interleaved
+	reassembler := NewReassembler(new(AtomCache), 0)
+	var messages []Message
+	for i := 0; i < len(fragments1); i++ {
+		for _, fragments := range [][][]byte{fragments1, fragments2} {
+			if i >= len(fragments) {
+				continue
+			}
+			message, err := reassembler.Add(fragments[i])
+			assertEqual(t, nil, err, "")
+			if message != nil {
+				messages = append(messages, message)
+			}
+		}
+	}
+	assertEqual(t, []Message{message2, message1}, messages, "")
+	assertEqual(t, 0, reassembler.Pending(), "")
+
+	// a small message is not fragmented
+	fragments, err := fragmenter.Fragments(NodeLink{})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, [][]byte{[]byte("\x83D\x00h\x01a\x05")}, fragments, "")
+	message, err := reassembler.Add(fragments[0])
+	assertEqual(t, nil, err, "")
+	assertEqual(t, NodeLink{}, message, "")
+}
+
+func TestFragmentError(t *testing.T) {
+	pid := term(t, "\x83Xw\x06b@host\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x02").(erlang.OtpErlangPid)
+	message := Send{To: pid, Message: erlang.OtpErlangBinary{Value: bytes.Repeat([]byte("a"), 100), Bits: 8}}
+	fragmenter := Fragmenter{Size: 32}
+	fragments, err := fragmenter.Fragments(message)
+	assertEqual(t, nil, err, "")
+
+	reassembler := NewReassembler(new(AtomCache), 64)
+	_, err = reassembler.Add(fragments[0])
+	assertEqual(t, nil, err, "")
+	_, err = reassembler.Add(fragments[1])
+	assertEqual(t, nil, err, "")
+	_, err = reassembler.Add(fragments[2])
+	assertEqual(t, ErrFragmentSize, err, "")
+	assertEqual(t, 0, reassembler.Pending(), "")
+
+	reassembler = NewReassembler(new(AtomCache), 0)
+	_, err = reassembler.Add(fragments[1])
+	assertEqual(t, ErrFragment, err, "")
+	_, err = reassembler.Add(fragments[0])
+	assertEqual(t, nil, err, "")
+	_, err = reassembler.Add(fragments[2])
+	assertEqual(t, ErrFragment, err, "")
+	assertEqual(t, 0, reassembler.Pending(), "")
+	_, err = reassembler.Add([]byte("\x83F\x00"))
+	assertEqual(t, ErrFragment, err, "")
+}
+
+func TestFragmentSequences(t *testing.T) {
+	pid := term(t, "\x83Xw\x06b@host\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x02").(erlang.OtpErlangPid)
+	message := Send{To: pid, Message: erlang.OtpErlangBinary{Value: bytes.Repeat([]byte("a"), 100), Bits: 8}}
+	fragmenter := Fragmenter{Size: 32}
+	reassembler := NewReassembler(new(AtomCache), 0)
+	for i := 0; i < SequencesMax; i++ {
+		fragments, err := fragmenter.Fragments(message)
+		assertEqual(t, nil, err, "")
+		_, err = reassembler.Add(fragments[0])
+		assertEqual(t, nil, err, "")
+	}
+	assertEqual(t, SequencesMax, reassembler.Pending(), "")
+	fragments, err := fragmenter.Fragments(message)
+	assertEqual(t, nil, err, "")
+	_, err = reassembler.Add(fragments[0])
+	assertEqual(t, ErrSequences, err, "")
+	assertEqual(t, SequencesMax, reassembler.Pending(), "")
+}
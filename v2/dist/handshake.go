@@ -0,0 +1,432 @@
+// Package dist provides the Erlang distribution protocol
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"strconv"
+)
+
+// Distribution flags (DFLAG_*)
+const (
+	FlagPublished          uint64 = 0x01
+	FlagAtomCache          uint64 = 0x02
+	FlagExtendedReferences uint64 = 0x04
+	FlagDistMonitor        uint64 = 0x08
+	FlagFunTags            uint64 = 0x10
+	FlagDistMonitorName    uint64 = 0x20
+	FlagHiddenAtomCache    uint64 = 0x40
+	FlagNewFunTags         uint64 = 0x80
+	FlagExtendedPidsPorts  uint64 = 0x100
+	FlagExportPtrTag       uint64 = 0x200
+	FlagBitBinaries        uint64 = 0x400
+	FlagNewFloats          uint64 = 0x800
+	FlagUnicodeIO          uint64 = 0x1000
+	FlagDistHdrAtomCache   uint64 = 0x2000
+	FlagSmallAtomTags      uint64 = 0x4000
+	FlagUTF8Atoms          uint64 = 0x10000
+	FlagMapTag             uint64 = 0x20000
+	FlagBigCreation        uint64 = 0x40000
+	FlagSendSender         uint64 = 0x80000
+	FlagBigSeqtraceLabels  uint64 = 0x100000
+	FlagExitPayload        uint64 = 0x400000
+	FlagFragments          uint64 = 0x800000
+	FlagHandshake23        uint64 = 0x1000000
+	FlagUnlinkID           uint64 = 0x2000000
+	FlagMandatory25Digest  uint64 = 0x4000000
+	FlagSpawn              uint64 = 1 << 32
+	FlagNameMe             uint64 = 1 << 33
+	FlagV4NC               uint64 = 1 << 34
+	FlagAlias              uint64 = 1 << 35
+
+	// FlagsMandatory are the flags required by Erlang/OTP 25 and later
+	FlagsMandatory = FlagExtendedReferences | FlagFunTags |
+		FlagExtendedPidsPorts | FlagUTF8Atoms | FlagNewFunTags |
+		FlagBigCreation | FlagNewFloats | FlagMapTag | FlagExportPtrTag |
+		FlagBitBinaries | FlagHandshake23
+
+	// FlagsDefault are the flags used when Config.Flags is 0
+	FlagsDefault = FlagsMandatory | FlagPublished | FlagDistMonitor |
+		FlagDistMonitorName | FlagSmallAtomTags | FlagExitPayload |
+		FlagUnlinkID | FlagV4NC | FlagAlias | FlagDistHdrAtomCache |
+		FlagFragments
+)
+
+const (
+	// handshake message tags
+	tagName           = 'n'
+	tagNameNew        = 'N'
+	tagStatus         = 's'
+	tagComplement     = 'c'
+	tagChallengeReply = 'r'
+	tagChallengeAck   = 'a'
+
+	versionDefault = 6
+)
+
+// Handshake errors
+var (
+	ErrCookie    = errors.New("invalid cookie digest")
+	ErrFlags     = errors.New("missing mandatory flags")
+	ErrHandshake = errors.New("invalid handshake message")
+)
+
+// StatusError is a handshake status that rejected the connection
+type StatusError struct {
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return "handshake status " + e.Status
+}
+
+// Config is the local node information used by the handshake
+type Config struct {
+	Name     string // node name, e.g., "name@host"
+	Cookie   string
+	Flags    uint64 // 0 is FlagsDefault
+	Creation uint32
+	Hidden   bool // a hidden node does not use FlagPublished
+	Version  int  // send_name version 5 or 6, 0 is 6 (from EPMD HighestVersion)
+}
+
+// Peer is the remote node information from the handshake
+type Peer struct {
+	Name     string
+	Flags    uint64 // negotiated flags, provided by both nodes
+	Creation uint32
+}
+
+func (config *Config) flags() uint64 {
+	flags := config.Flags
+	if flags == 0 {
+		flags = FlagsDefault
+	}
+	if config.Hidden {
+		flags &^= FlagPublished
+	} else {
+		flags |= FlagPublished
+	}
+	return flags
+}
+
+// Connect performs the handshake as the initiator (node A)
+func Connect(conn net.Conn, config Config) (Peer, error) {
+	flags := config.flags()
+	version := config.Version
+	if version == 0 {
+		version = versionDefault
+	}
+	var message *bytes.Buffer = new(bytes.Buffer)
+	switch version {
+	case 5:
+		message.WriteByte(tagName)
+		binary.Write(message, binary.BigEndian, uint16(5))
+		binary.Write(message, binary.BigEndian, uint32(flags))
+		message.WriteString(config.Name)
+	case 6:
+		message.WriteByte(tagNameNew)
+		binary.Write(message, binary.BigEndian, flags)
+		binary.Write(message, binary.BigEndian, config.Creation)
+		err := writeString(message, config.Name)
+		if err != nil {
+			return Peer{}, err
+		}
+	default:
+		return Peer{}, errors.New("version in [5, 6]")
+	}
+	err := writeMessage(conn, message.Bytes())
+	if err != nil {
+		return Peer{}, err
+	}
+
+	// recv_status
+	var data []byte
+	data, err = readMessage(conn)
+	if err != nil {
+		return Peer{}, err
+	}
+	if len(data) < 1 || data[0] != tagStatus {
+		return Peer{}, ErrHandshake
+	}
+	status := string(data[1:])
+	if status != "ok" && status != "ok_simultaneous" {
+		return Peer{}, &StatusError{Status: status}
+	}
+
+	// recv_challenge (always the new format with FlagHandshake23)
+	data, err = readMessage(conn)
+	if err != nil {
+		return Peer{}, err
+	}
+	reader := bytes.NewReader(data)
+	var header struct {
+		Tag       uint8
+		Flags     uint64
+		Challenge uint32
+		Creation  uint32
+	}
+	err = binary.Read(reader, binary.BigEndian, &header)
+	if err != nil || header.Tag != tagNameNew {
+		return Peer{}, ErrHandshake
+	}
+	var peer Peer
+	peer.Name, err = readString(reader)
+	if err != nil {
+		return Peer{}, ErrHandshake
+	}
+	peer.Creation = header.Creation
+	if header.Flags&FlagsMandatory != FlagsMandatory {
+		return Peer{}, ErrFlags
+	}
+	peer.Flags = header.Flags & flags
+
+	if version == 5 {
+		// send_complement
+		message.Reset()
+		message.WriteByte(tagComplement)
+		binary.Write(message, binary.BigEndian, uint32(flags>>32))
+		binary.Write(message, binary.BigEndian, config.Creation)
+		err = writeMessage(conn, message.Bytes())
+		if err != nil {
+			return Peer{}, err
+		}
+	}
+
+	// send_challenge_reply
+	var challenge uint32
+	challenge, err = challengeNew()
+	if err != nil {
+		return Peer{}, err
+	}
+	message.Reset()
+	message.WriteByte(tagChallengeReply)
+	binary.Write(message, binary.BigEndian, challenge)
+	message.Write(digest(header.Challenge, config.Cookie))
+	err = writeMessage(conn, message.Bytes())
+	if err != nil {
+		return Peer{}, err
+	}
+
+	// recv_challenge_ack
+	data, err = readMessage(conn)
+	if err != nil {
+		return Peer{}, err
+	}
+	if len(data) != 17 || data[0] != tagChallengeAck {
+		return Peer{}, ErrHandshake
+	}
+	if !bytes.Equal(data[1:], digest(challenge, config.Cookie)) {
+		return Peer{}, ErrCookie
+	}
+	return peer, nil
+}
+
+// Accept performs the handshake as the acceptor (node B)
+func Accept(conn net.Conn, config Config) (Peer, error) {
+	flags := config.flags()
+
+	// recv_name
+	data, err := readMessage(conn)
+	if err != nil {
+		return Peer{}, err
+	}
+	if len(data) < 1 {
+		return Peer{}, ErrHandshake
+	}
+	var peer Peer
+	var peerFlags uint64
+	reader := bytes.NewReader(data[1:])
+	switch data[0] {
+	case tagName:
+		var header struct {
+			Version uint16
+			Flags   uint32
+		}
+		err = binary.Read(reader, binary.BigEndian, &header)
+		if err != nil {
+			return Peer{}, ErrHandshake
+		}
+		peerFlags = uint64(header.Flags)
+		peer.Name = string(data[1+6:])
+		if peerFlags&FlagsMandatory != FlagsMandatory {
+			return Peer{}, ErrFlags
+		}
+	case tagNameNew:
+		var header struct {
+			Flags    uint64
+			Creation uint32
+		}
+		err = binary.Read(reader, binary.BigEndian, &header)
+		if err != nil {
+			return Peer{}, ErrHandshake
+		}
+		peerFlags = header.Flags
+		peer.Creation = header.Creation
+		peer.Name, err = readString(reader)
+		if err != nil {
+			return Peer{}, ErrHandshake
+		}
+	default:
+		return Peer{}, ErrHandshake
+	}
+
+	// send_status
+	err = writeMessage(conn, []byte("sok"))
+	if err != nil {
+		return Peer{}, err
+	}
+
+	// send_challenge
+	var challenge uint32
+	challenge, err = challengeNew()
+	if err != nil {
+		return Peer{}, err
+	}
+	var message *bytes.Buffer = new(bytes.Buffer)
+	message.WriteByte(tagNameNew)
+	binary.Write(message, binary.BigEndian, flags)
+	binary.Write(message, binary.BigEndian, challenge)
+	binary.Write(message, binary.BigEndian, config.Creation)
+	err = writeString(message, config.Name)
+	if err != nil {
+		return Peer{}, err
+	}
+	err = writeMessage(conn, message.Bytes())
+	if err != nil {
+		return Peer{}, err
+	}
+
+	data, err = readMessage(conn)
+	if err != nil {
+		return Peer{}, err
+	}
+	if data[0] == tagComplement && len(data) == 9 {
+		// recv_complement
+		peerFlags |= uint64(binary.BigEndian.Uint32(data[1:5])) << 32
+		peer.Creation = binary.BigEndian.Uint32(data[5:9])
+		data, err = readMessage(conn)
+		if err != nil {
+			return Peer{}, err
+		}
+	}
+	if peerFlags&FlagsMandatory != FlagsMandatory {
+		return Peer{}, ErrFlags
+	}
+	peer.Flags = peerFlags & flags
+
+	// recv_challenge_reply
+	if len(data) != 21 || data[0] != tagChallengeReply {
+		return Peer{}, ErrHandshake
+	}
+	if !bytes.Equal(data[5:], digest(challenge, config.Cookie)) {
+		return Peer{}, ErrCookie
+	}
+
+	// send_challenge_ack
+	message.Reset()
+	message.WriteByte(tagChallengeAck)
+	message.Write(digest(binary.BigEndian.Uint32(data[1:5]), config.Cookie))
+	err = writeMessage(conn, message.Bytes())
+	if err != nil {
+		return Peer{}, err
+	}
+	return peer, nil
+}
+
+// digest is MD5(Cookie ++ integer_to_list(Challenge))
+func digest(challenge uint32, cookie string) []byte {
+	value := md5.Sum([]byte(cookie + strconv.FormatUint(uint64(challenge), 10)))
+	return value[:]
+}
+
+func challengeNew() (uint32, error) {
+	var value [4]byte
+	_, err := rand.Read(value[:])
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(value[:]), nil
+}
+
+// handshake messages use a 2 byte length
+
+func readMessage(reader io.Reader) ([]byte, error) {
+	var length uint16
+	err := binary.Read(reader, binary.BigEndian, &length)
+	if err != nil {
+		return nil, err
+	}
+	if length == 0 {
+		return nil, ErrHandshake
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func writeMessage(writer io.Writer, data []byte) error {
+	if len(data) > math.MaxUint16 {
+		return ErrHandshake
+	}
+	message := make([]byte, 2, 2+len(data))
+	binary.BigEndian.PutUint16(message, uint16(len(data)))
+	_, err := writer.Write(append(message, data...))
+	return err
+}
+
+func writeString(buffer *bytes.Buffer, value string) error {
+	if len(value) > math.MaxUint16 {
+		return ErrHandshake
+	}
+	binary.Write(buffer, binary.BigEndian, uint16(len(value)))
+	buffer.WriteString(value)
+	return nil
+}
+
+func readString(reader io.Reader) (string, error) {
+	var length uint16
+	err := binary.Read(reader, binary.BigEndian, &length)
+	if err != nil {
+		return "", err
+	}
+	value := make([]byte, length)
+	_, err = io.ReadFull(reader, value)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
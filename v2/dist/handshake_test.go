@@ -0,0 +1,119 @@
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"encoding/hex"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+type handshakeResult struct {
+	peer Peer
+	err  error
+}
+
+func handshake(configA, configB Config) (handshakeResult, handshakeResult) {
+	connA, connB := net.Pipe()
+	done := make(chan handshakeResult)
+	go func() {
+		peer, err := Accept(connB, configB)
+		connB.Close()
+		done <- handshakeResult{peer, err}
+	}()
+	peer, err := Connect(connA, configA)
+	connA.Close()
+	return handshakeResult{peer, err}, <-done
+}
+
+func TestDigest(t *testing.T) {
+	assertEqual(t, "538d8614340a769da7ef34a8d2e0938a", hex.EncodeToString(digest(123, "cookie")), "")
+}
+
+func TestHandshake(t *testing.T) {
+	configA := Config{Name: "a@host", Cookie: "cookie", Creation: 1}
+	configB := Config{Name: "b@host", Cookie: "cookie", Creation: 2, Flags: FlagsDefault | FlagSpawn}
+	for _, version := range []int{5, 6} {
+		configA.Version = version
+		resultA, resultB := handshake(configA, configB)
+		assertEqual(t, nil, resultA.err, "")
+		assertEqual(t, nil, resultB.err, "")
+		assertEqual(t, Peer{Name: "b@host", Flags: FlagsDefault, Creation: 2}, resultA.peer, "")
+		assertEqual(t, Peer{Name: "a@host", Flags: FlagsDefault, Creation: 1}, resultB.peer, "")
+	}
+
+	configA.Hidden = true
+	resultA, resultB := handshake(configA, configB)
+	assertEqual(t, nil, resultA.err, "")
+	assertEqual(t, FlagsDefault&^FlagPublished, resultB.peer.Flags, "")
+}
+
+func TestHandshakeError(t *testing.T) {
+	configA := Config{Name: "a@host", Cookie: "cookie"}
+	configB := Config{Name: "b@host", Cookie: "other"}
+	resultA, resultB := handshake(configA, configB)
+	assertEqual(t, false, resultA.err == nil, "")
+	assertEqual(t, ErrCookie, resultB.err, "")
+
+	configB.Cookie = "cookie"
+	configA.Flags = FlagsDefault &^ FlagMapTag
+	resultA, resultB = handshake(configA, configB)
+	assertEqual(t, false, resultA.err == nil, "")
+	assertEqual(t, ErrFlags, resultB.err, "")
+	configA.Version = 5
+	resultA, resultB = handshake(configA, configB)
+	assertEqual(t, false, resultA.err == nil, "")
+	assertEqual(t, ErrFlags, resultB.err, "")
+
+	configA.Flags = 0
+	configA.Version = 5
+	configB.Flags = FlagsDefault &^ FlagBitBinaries
+	resultA, resultB = handshake(configA, configB)
+	assertEqual(t, ErrFlags, resultA.err, "")
+
+	// reject status
+	connA, connB := net.Pipe()
+	go func() {
+		readMessage(connB)
+		writeMessage(connB, []byte("salive"))
+		connB.Close()
+	}()
+	_, err := Connect(connA, configA)
+	assertEqual(t, &StatusError{Status: "alive"}, err, "")
+	assertEqual(t, "handshake status alive", err.Error(), "")
+}
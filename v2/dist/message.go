@@ -0,0 +1,855 @@
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// Control message operations
+const (
+	OpLink                = 1
+	OpSend                = 2
+	OpExit                = 3
+	OpUnlink              = 4
+	OpNodeLink            = 5
+	OpRegSend             = 6
+	OpGroupLeader         = 7
+	OpExit2               = 8
+	OpSendTT              = 12
+	OpExitTT              = 13
+	OpRegSendTT           = 16
+	OpExit2TT             = 18
+	OpMonitorP            = 19
+	OpDemonitorP          = 20
+	OpMonitorPExit        = 21
+	OpSendSender          = 22
+	OpSendSenderTT        = 23
+	OpPayloadExit         = 24
+	OpPayloadExitTT       = 25
+	OpPayloadExit2        = 26
+	OpPayloadExit2TT      = 27
+	OpPayloadMonitorPExit = 28
+	OpSpawnRequest        = 29
+	OpSpawnRequestTT      = 30
+	OpSpawnReply          = 31
+	OpSpawnReplyTT        = 32
+	OpAliasSend           = 33
+	OpAliasSendTT         = 34
+	OpUnlinkID            = 35
+	OpUnlinkIDAck         = 36
+)
+
+const (
+	tagPassThrough   = 'p'
+	tagVersion       = 131
+	tagDistHeader    = 68
+	tagAtomCacheRef  = 82
+	tagAtomUTF8      = 118
+	tagSmallAtomUTF8 = 119
+	atomCacheSize    = 2048
+)
+
+// Message errors
+var (
+	ErrAtomCache = errors.New("invalid atom cache reference")
+	ErrControl   = errors.New("invalid control message")
+	ErrMessage   = errors.New("invalid distribution message")
+)
+
+// Message is a distribution control message (with the optional payload)
+type Message interface {
+	control() (erlang.OtpErlangTuple, interface{}, bool)
+}
+
+// Link is LINK
+type Link struct {
+	From erlang.OtpErlangPid
+	To   erlang.OtpErlangPid
+}
+
+// Send is SEND or SEND_TT (when TraceToken is not nil)
+type Send struct {
+	To         erlang.OtpErlangPid
+	Message    interface{}
+	TraceToken interface{}
+}
+
+// Exit is EXIT, EXIT_TT, PAYLOAD_EXIT or PAYLOAD_EXIT_TT
+// (the Reason is the payload when Payload is true)
+type Exit struct {
+	From       erlang.OtpErlangPid
+	To         erlang.OtpErlangPid
+	Reason     interface{}
+	TraceToken interface{}
+	Payload    bool
+}
+
+// Unlink is UNLINK (replaced by UnlinkID)
+type Unlink struct {
+	From erlang.OtpErlangPid
+	To   erlang.OtpErlangPid
+}
+
+// NodeLink is NODE_LINK
+type NodeLink struct{}
+
+// RegSend is REG_SEND or REG_SEND_TT (when TraceToken is not nil)
+type RegSend struct {
+	From       erlang.OtpErlangPid
+	ToName     string
+	Message    interface{}
+	TraceToken interface{}
+}
+
+// GroupLeader is GROUP_LEADER
+type GroupLeader struct {
+	From erlang.OtpErlangPid
+	To   erlang.OtpErlangPid
+}
+
+// Exit2 is EXIT2, EXIT2_TT, PAYLOAD_EXIT2 or PAYLOAD_EXIT2_TT
+// (the Reason is the payload when Payload is true)
+type Exit2 struct {
+	From       erlang.OtpErlangPid
+	To         erlang.OtpErlangPid
+	Reason     interface{}
+	TraceToken interface{}
+	Payload    bool
+}
+
+// MonitorP is MONITOR_P (To is a pid or a registered name atom)
+type MonitorP struct {
+	From erlang.OtpErlangPid
+	To   interface{}
+	Ref  erlang.OtpErlangReference
+}
+
+// DemonitorP is DEMONITOR_P (To is a pid or a registered name atom)
+type DemonitorP struct {
+	From erlang.OtpErlangPid
+	To   interface{}
+	Ref  erlang.OtpErlangReference
+}
+
+// MonitorPExit is MONITOR_P_EXIT or PAYLOAD_MONITOR_P_EXIT
+// (From is a pid or a registered name atom and
+// the Reason is the payload when Payload is true)
+type MonitorPExit struct {
+	From    interface{}
+	To      erlang.OtpErlangPid
+	Ref     erlang.OtpErlangReference
+	Reason  interface{}
+	Payload bool
+}
+
+// SendSender is SEND_SENDER or SEND_SENDER_TT (when TraceToken is not nil)
+type SendSender struct {
+	From       erlang.OtpErlangPid
+	To         erlang.OtpErlangPid
+	Message    interface{}
+	TraceToken interface{}
+}
+
+// SpawnRequest is SPAWN_REQUEST or SPAWN_REQUEST_TT
+// (when TraceToken is not nil)
+type SpawnRequest struct {
+	ReqID       erlang.OtpErlangReference
+	From        erlang.OtpErlangPid
+	GroupLeader erlang.OtpErlangPid
+	Module      string
+	Function    string
+	Arity       int
+	Options     interface{}
+	Args        interface{}
+	TraceToken  interface{}
+}
+
+// SpawnReply is SPAWN_REPLY or SPAWN_REPLY_TT (when TraceToken is not nil)
+type SpawnReply struct {
+	ReqID      erlang.OtpErlangReference
+	To         erlang.OtpErlangPid
+	Flags      int
+	Result     interface{}
+	TraceToken interface{}
+}
+
+// AliasSend is ALIAS_SEND or ALIAS_SEND_TT (when TraceToken is not nil)
+type AliasSend struct {
+	From       erlang.OtpErlangPid
+	Alias      erlang.OtpErlangReference
+	Message    interface{}
+	TraceToken interface{}
+}
+
+// UnlinkID is UNLINK_ID
+type UnlinkID struct {
+	ID   uint64
+	From erlang.OtpErlangPid
+	To   erlang.OtpErlangPid
+}
+
+// UnlinkIDAck is UNLINK_ID_ACK
+type UnlinkIDAck struct {
+	ID   uint64
+	From erlang.OtpErlangPid
+	To   erlang.OtpErlangPid
+}
+
+func (m Link) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpLink, m.From, m.To}, nil, false
+}
+
+func (m Send) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	if m.TraceToken != nil {
+		return erlang.OtpErlangTuple{OpSendTT, unused, m.To, m.TraceToken}, m.Message, true
+	}
+	return erlang.OtpErlangTuple{OpSend, unused, m.To}, m.Message, true
+}
+
+func (m Exit) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return exitControl(m.From, m.To, m.Reason, m.TraceToken, m.Payload,
+		OpExit, OpExitTT, OpPayloadExit, OpPayloadExitTT)
+}
+
+func (m Unlink) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpUnlink, m.From, m.To}, nil, false
+}
+
+func (m NodeLink) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpNodeLink}, nil, false
+}
+
+func (m RegSend) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	name := erlang.OtpErlangAtomUTF8(m.ToName)
+	if m.TraceToken != nil {
+		return erlang.OtpErlangTuple{OpRegSendTT, m.From, unused, name, m.TraceToken}, m.Message, true
+	}
+	return erlang.OtpErlangTuple{OpRegSend, m.From, unused, name}, m.Message, true
+}
+
+func (m GroupLeader) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpGroupLeader, m.From, m.To}, nil, false
+}
+
+func (m Exit2) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return exitControl(m.From, m.To, m.Reason, m.TraceToken, m.Payload,
+		OpExit2, OpExit2TT, OpPayloadExit2, OpPayloadExit2TT)
+}
+
+func (m MonitorP) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpMonitorP, m.From, m.To, m.Ref}, nil, false
+}
+
+func (m DemonitorP) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpDemonitorP, m.From, m.To, m.Ref}, nil, false
+}
+
+func (m MonitorPExit) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	if m.Payload {
+		return erlang.OtpErlangTuple{OpPayloadMonitorPExit, m.From, m.To, m.Ref}, m.Reason, true
+	}
+	return erlang.OtpErlangTuple{OpMonitorPExit, m.From, m.To, m.Ref, m.Reason}, nil, false
+}
+
+func (m SendSender) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	if m.TraceToken != nil {
+		return erlang.OtpErlangTuple{OpSendSenderTT, m.From, m.To, m.TraceToken}, m.Message, true
+	}
+	return erlang.OtpErlangTuple{OpSendSender, m.From, m.To}, m.Message, true
+}
+
+func (m SpawnRequest) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	mfa := erlang.OtpErlangTuple{
+		erlang.OtpErlangAtomUTF8(m.Module),
+		erlang.OtpErlangAtomUTF8(m.Function),
+		m.Arity,
+	}
+	options := m.Options
+	if options == nil {
+		options = erlang.OtpErlangList{}
+	}
+	args := m.Args
+	if args == nil {
+		args = erlang.OtpErlangList{}
+	}
+	if m.TraceToken != nil {
+		return erlang.OtpErlangTuple{OpSpawnRequestTT, m.ReqID, m.From, m.GroupLeader, mfa, options, m.TraceToken}, args, true
+	}
+	return erlang.OtpErlangTuple{OpSpawnRequest, m.ReqID, m.From, m.GroupLeader, mfa, options}, args, true
+}
+
+func (m SpawnReply) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	if m.TraceToken != nil {
+		return erlang.OtpErlangTuple{OpSpawnReplyTT, m.ReqID, m.To, m.Flags, m.Result, m.TraceToken}, nil, false
+	}
+	return erlang.OtpErlangTuple{OpSpawnReply, m.ReqID, m.To, m.Flags, m.Result}, nil, false
+}
+
+func (m AliasSend) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	if m.TraceToken != nil {
+		return erlang.OtpErlangTuple{OpAliasSendTT, m.From, m.Alias, m.TraceToken}, m.Message, true
+	}
+	return erlang.OtpErlangTuple{OpAliasSend, m.From, m.Alias}, m.Message, true
+}
+
+func (m UnlinkID) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpUnlinkID, integer(m.ID), m.From, m.To}, nil, false
+}
+
+func (m UnlinkIDAck) control() (erlang.OtpErlangTuple, interface{}, bool) {
+	return erlang.OtpErlangTuple{OpUnlinkIDAck, integer(m.ID), m.From, m.To}, nil, false
+}
+
+// unused is the ” atom used for unused control message elements
+var unused = erlang.OtpErlangAtomUTF8("")
+
+func exitControl(from, to erlang.OtpErlangPid, reason, token interface{}, payload bool, op, opTT, opPayload, opPayloadTT int) (erlang.OtpErlangTuple, interface{}, bool) {
+	switch {
+	case payload && token != nil:
+		return erlang.OtpErlangTuple{opPayloadTT, from, to, token}, reason, true
+	case payload:
+		return erlang.OtpErlangTuple{opPayload, from, to}, reason, true
+	case token != nil:
+		return erlang.OtpErlangTuple{opTT, from, to, token, reason}, nil, false
+	default:
+		return erlang.OtpErlangTuple{op, from, to, reason}, nil, false
+	}
+}
+
+// Encode provides the data of a distribution message without the length,
+// using the pass through format or, if FlagDistHdrAtomCache is set,
+// a DIST_HEADER without atom cache references
+func Encode(message Message, flags uint64) ([]byte, error) {
+	control, payload, hasPayload := message.control()
+	data := new(bytes.Buffer)
+	var header int
+	if flags&FlagDistHdrAtomCache != 0 {
+		data.Write([]byte{tagVersion, tagDistHeader, 0})
+		header = 1
+	} else {
+		data.WriteByte(tagPassThrough)
+	}
+	value, err := erlang.TermToBinary(control, -1)
+	if err != nil {
+		return nil, err
+	}
+	data.Write(value[header:])
+	if hasPayload {
+		value, err = erlang.TermToBinary(payload, -1)
+		if err != nil {
+			return nil, err
+		}
+		data.Write(value[header:])
+	}
+	return data.Bytes(), nil
+}
+
+// AtomCache is the atom cache of a connection,
+// updated by each DIST_HEADER that is decoded
+type AtomCache struct {
+	atoms [atomCacheSize]string
+}
+
+// Decode provides the distribution message from data without the length
+// (cache may be nil if DIST_HEADER atom cache references are not used)
+func Decode(data []byte, cache *AtomCache) (Message, error) {
+	if len(data) == 0 {
+		return nil, ErrMessage
+	}
+	if data[0] == tagPassThrough {
+		return decodeTerms(data[1:], nil)
+	}
+	if len(data) < 3 || data[0] != tagVersion || data[1] != tagDistHeader {
+		return nil, ErrMessage
+	}
+	refs, i, err := atomCacheRefs(data, 2, cache)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTerms(data[i:], refs)
+}
+
+// decodeTerms decodes the control message and the optional payload,
+// with the version tags when refs is nil
+func decodeTerms(data []byte, refs []interface{}) (Message, error) {
+	if refs != nil {
+		// DIST_HEADER terms do not have a version tag
+		data = termsVersion(data)
+	}
+	control, i, err := erlang.BinaryToTermPrefix(data)
+	if err != nil {
+		return nil, err
+	}
+	var payload interface{}
+	hasPayload := i < len(data)
+	if hasPayload {
+		if refs != nil {
+			// the control message data is no longer needed
+			data[i-1] = tagVersion
+			payload, err = erlang.BinaryToTerm(data[i-1:])
+		} else {
+			payload, err = erlang.BinaryToTerm(data[i:])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if refs != nil {
+		control, err = atomCacheResolve(control, refs)
+		if err != nil {
+			return nil, err
+		}
+		if hasPayload {
+			payload, err = atomCacheResolve(payload, refs)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	tuple, ok := control.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) == 0 {
+		return nil, ErrControl
+	}
+	return decodeControl(tuple, payload, hasPayload)
+}
+
+// termsVersion provides a copy of the data with a version tag prefix
+func termsVersion(data []byte) []byte {
+	value := make([]byte, len(data)+1)
+	value[0] = tagVersion
+	copy(value[1:], data)
+	return value
+}
+
+func decodeControl(tuple erlang.OtpErlangTuple, payload interface{}, hasPayload bool) (Message, error) {
+	op, err := termInteger(tuple[0])
+	if err != nil {
+		return nil, err
+	}
+	var d controlDecoder = controlDecoder{tuple: tuple}
+	switch op {
+	case OpLink:
+		d.arity(3, false, hasPayload)
+		return Link{From: d.pid(1), To: d.pid(2)}, d.err
+	case OpSend:
+		d.arity(3, true, hasPayload)
+		return Send{To: d.pid(2), Message: payload}, d.err
+	case OpSendTT:
+		d.arity(4, true, hasPayload)
+		return Send{To: d.pid(2), TraceToken: tuple[3], Message: payload}, d.err
+	case OpExit, OpExit2:
+		d.arity(4, false, hasPayload)
+		return exitMessage(op == OpExit, Exit{From: d.pid(1), To: d.pid(2), Reason: tuple[3]}), d.err
+	case OpExitTT, OpExit2TT:
+		d.arity(5, false, hasPayload)
+		return exitMessage(op == OpExitTT, Exit{From: d.pid(1), To: d.pid(2), TraceToken: tuple[3], Reason: tuple[4]}), d.err
+	case OpPayloadExit, OpPayloadExit2:
+		d.arity(3, true, hasPayload)
+		return exitMessage(op == OpPayloadExit, Exit{From: d.pid(1), To: d.pid(2), Reason: payload, Payload: true}), d.err
+	case OpPayloadExitTT, OpPayloadExit2TT:
+		d.arity(4, true, hasPayload)
+		return exitMessage(op == OpPayloadExitTT, Exit{From: d.pid(1), To: d.pid(2), TraceToken: tuple[3], Reason: payload, Payload: true}), d.err
+	case OpUnlink:
+		d.arity(3, false, hasPayload)
+		return Unlink{From: d.pid(1), To: d.pid(2)}, d.err
+	case OpNodeLink:
+		d.arity(1, false, hasPayload)
+		return NodeLink{}, d.err
+	case OpRegSend:
+		d.arity(4, true, hasPayload)
+		return RegSend{From: d.pid(1), ToName: d.atom(3), Message: payload}, d.err
+	case OpRegSendTT:
+		d.arity(5, true, hasPayload)
+		return RegSend{From: d.pid(1), ToName: d.atom(3), TraceToken: tuple[4], Message: payload}, d.err
+	case OpGroupLeader:
+		d.arity(3, false, hasPayload)
+		return GroupLeader{From: d.pid(1), To: d.pid(2)}, d.err
+	case OpMonitorP:
+		d.arity(4, false, hasPayload)
+		return MonitorP{From: d.pid(1), To: tuple[2], Ref: d.reference(3)}, d.err
+	case OpDemonitorP:
+		d.arity(4, false, hasPayload)
+		return DemonitorP{From: d.pid(1), To: tuple[2], Ref: d.reference(3)}, d.err
+	case OpMonitorPExit:
+		d.arity(5, false, hasPayload)
+		return MonitorPExit{From: tuple[1], To: d.pid(2), Ref: d.reference(3), Reason: tuple[4]}, d.err
+	case OpPayloadMonitorPExit:
+		d.arity(4, true, hasPayload)
+		return MonitorPExit{From: tuple[1], To: d.pid(2), Ref: d.reference(3), Reason: payload, Payload: true}, d.err
+	case OpSendSender:
+		d.arity(3, true, hasPayload)
+		return SendSender{From: d.pid(1), To: d.pid(2), Message: payload}, d.err
+	case OpSendSenderTT:
+		d.arity(4, true, hasPayload)
+		return SendSender{From: d.pid(1), To: d.pid(2), TraceToken: tuple[3], Message: payload}, d.err
+	case OpSpawnRequest, OpSpawnRequestTT:
+		var message SpawnRequest
+		if op == OpSpawnRequestTT {
+			d.arity(7, true, hasPayload)
+			if d.err == nil {
+				message.TraceToken = tuple[6]
+			}
+		} else {
+			d.arity(6, true, hasPayload)
+		}
+		message.ReqID = d.reference(1)
+		message.From = d.pid(2)
+		message.GroupLeader = d.pid(3)
+		message.Module, message.Function, message.Arity = d.mfa(4)
+		if d.err == nil {
+			message.Options = tuple[5]
+		}
+		message.Args = payload
+		return message, d.err
+	case OpSpawnReply:
+		d.arity(5, false, hasPayload)
+		return SpawnReply{ReqID: d.reference(1), To: d.pid(2), Flags: int(d.integer(3)), Result: tuple[4]}, d.err
+	case OpSpawnReplyTT:
+		d.arity(6, false, hasPayload)
+		return SpawnReply{ReqID: d.reference(1), To: d.pid(2), Flags: int(d.integer(3)), Result: tuple[4], TraceToken: tuple[5]}, d.err
+	case OpAliasSend:
+		d.arity(3, true, hasPayload)
+		return AliasSend{From: d.pid(1), Alias: d.reference(2), Message: payload}, d.err
+	case OpAliasSendTT:
+		d.arity(4, true, hasPayload)
+		return AliasSend{From: d.pid(1), Alias: d.reference(2), TraceToken: tuple[3], Message: payload}, d.err
+	case OpUnlinkID:
+		d.arity(4, false, hasPayload)
+		return UnlinkID{ID: d.integer(1), From: d.pid(2), To: d.pid(3)}, d.err
+	case OpUnlinkIDAck:
+		d.arity(4, false, hasPayload)
+		return UnlinkIDAck{ID: d.integer(1), From: d.pid(2), To: d.pid(3)}, d.err
+	default:
+		return nil, ErrControl
+	}
+}
+
+func exitMessage(exit bool, message Exit) Message {
+	if exit {
+		return message
+	}
+	return Exit2(message)
+}
+
+// controlDecoder keeps the first error of the control tuple elements
+type controlDecoder struct {
+	tuple erlang.OtpErlangTuple
+	err   error
+}
+
+func (d *controlDecoder) arity(arity int, payload, hasPayload bool) {
+	if len(d.tuple) != arity || payload != hasPayload {
+		d.err = ErrControl
+	}
+}
+
+func (d *controlDecoder) pid(i int) erlang.OtpErlangPid {
+	if d.err != nil {
+		return erlang.OtpErlangPid{}
+	}
+	value, ok := d.tuple[i].(erlang.OtpErlangPid)
+	if !ok {
+		d.err = ErrControl
+	}
+	return value
+}
+
+func (d *controlDecoder) reference(i int) erlang.OtpErlangReference {
+	if d.err != nil {
+		return erlang.OtpErlangReference{}
+	}
+	value, ok := d.tuple[i].(erlang.OtpErlangReference)
+	if !ok {
+		d.err = ErrControl
+	}
+	return value
+}
+
+func (d *controlDecoder) atom(i int) string {
+	if d.err != nil {
+		return ""
+	}
+	value, err := termAtom(d.tuple[i])
+	if err != nil {
+		d.err = err
+	}
+	return value
+}
+
+func (d *controlDecoder) integer(i int) uint64 {
+	if d.err != nil {
+		return 0
+	}
+	value, err := termInteger(d.tuple[i])
+	if err != nil {
+		d.err = err
+	}
+	return value
+}
+
+func (d *controlDecoder) mfa(i int) (string, string, int) {
+	if d.err != nil {
+		return "", "", 0
+	}
+	mfa, ok := d.tuple[i].(erlang.OtpErlangTuple)
+	if !ok || len(mfa) != 3 {
+		d.err = ErrControl
+		return "", "", 0
+	}
+	module, err := termAtom(mfa[0])
+	if err != nil {
+		d.err = err
+		return "", "", 0
+	}
+	var function string
+	function, err = termAtom(mfa[1])
+	if err != nil {
+		d.err = err
+		return "", "", 0
+	}
+	var arity uint64
+	arity, err = termInteger(mfa[2])
+	if err != nil {
+		d.err = err
+		return "", "", 0
+	}
+	return module, function, int(arity)
+}
+
+func termAtom(term interface{}) (string, error) {
+	switch value := term.(type) {
+	case erlang.OtpErlangAtom:
+		return string(value), nil
+	case erlang.OtpErlangAtomUTF8:
+		return string(value), nil
+	case bool:
+		if value {
+			return "true", nil
+		}
+		return "false", nil
+	default:
+		return "", ErrControl
+	}
+}
+
+func termInteger(term interface{}) (uint64, error) {
+	switch value := term.(type) {
+	case uint8:
+		return uint64(value), nil
+	case int32:
+		if value < 0 {
+			return 0, ErrControl
+		}
+		return uint64(value), nil
+	case *big.Int:
+		if value.Sign() < 0 || !value.IsUint64() {
+			return 0, ErrControl
+		}
+		return value.Uint64(), nil
+	default:
+		return 0, ErrControl
+	}
+}
+
+// integer provides the smallest integer encoding
+func integer(value uint64) interface{} {
+	if value <= math.MaxInt32 {
+		return int(value)
+	}
+	return value
+}
+
+// atomCacheRefs decodes the atom cache references of a DIST_HEADER
+// (or DIST_FRAG_HEADER) at data[i], providing the decoded atom of each
+// reference and the index after the header
+func atomCacheRefs(data []byte, i int, cache *AtomCache) ([]interface{}, int, error) {
+	if i >= len(data) {
+		return nil, i, ErrMessage
+	}
+	count := int(data[i])
+	i += 1
+	refs := make([]interface{}, count)
+	if count == 0 {
+		return refs, i, nil
+	}
+	if cache == nil {
+		return nil, i, ErrAtomCache
+	}
+	flagsSize := count/2 + 1
+	if i+flagsSize > len(data) {
+		return nil, i, ErrMessage
+	}
+	flags := data[i : i+flagsSize]
+	i += flagsSize
+	longAtoms := flagHalfByte(flags, count)&0x1 != 0
+	for ref := 0; ref < count; ref++ {
+		flag := flagHalfByte(flags, ref)
+		if i >= len(data) {
+			return nil, i, ErrMessage
+		}
+		index := int(flag&0x7)<<8 | int(data[i])
+		i += 1
+		if flag&0x8 != 0 {
+			// new cache entry
+			var length int
+			if longAtoms {
+				if i+2 > len(data) {
+					return nil, i, ErrMessage
+				}
+				length = int(data[i])<<8 | int(data[i+1])
+				i += 2
+			} else {
+				if i+1 > len(data) {
+					return nil, i, ErrMessage
+				}
+				length = int(data[i])
+				i += 1
+			}
+			if i+length > len(data) {
+				return nil, i, ErrMessage
+			}
+			cache.atoms[index] = string(data[i : i+length])
+			i += length
+		}
+		atom, err := atomTerm(cache.atoms[index])
+		if err != nil {
+			return nil, i, err
+		}
+		refs[ref] = atom
+	}
+	return refs, i, nil
+}
+
+func flagHalfByte(flags []byte, index int) uint8 {
+	value := flags[index/2]
+	if index%2 == 1 {
+		return value >> 4
+	}
+	return value & 0xf
+}
+
+// atomTerm decodes the atom name the same way as other atoms
+// (true and false are bool, the undefined atom is nil)
+func atomTerm(name string) (interface{}, error) {
+	data, err := atomEncode(name)
+	if err != nil {
+		return nil, err
+	}
+	return erlang.BinaryToTerm(append([]byte{tagVersion}, data...))
+}
+
+func atomEncode(name string) ([]byte, error) {
+	switch length := len(name); {
+	case length <= math.MaxUint8:
+		return append([]byte{tagSmallAtomUTF8, uint8(length)}, name...), nil
+	case length <= math.MaxUint16:
+		return append([]byte{tagAtomUTF8, uint8(length >> 8), uint8(length)}, name...), nil
+	default:
+		return nil, ErrAtomCache
+	}
+}
+
+// atomCacheResolve replaces atom cache references within the term
+func atomCacheResolve(termI interface{}, refs []interface{}) (interface{}, error) {
+	switch term := termI.(type) {
+	case erlang.OtpErlangAtomCacheRef:
+		if int(term) >= len(refs) {
+			return nil, ErrAtomCache
+		}
+		return refs[term], nil
+	case erlang.OtpErlangTuple:
+		for i, element := range term {
+			value, err := atomCacheResolve(element, refs)
+			if err != nil {
+				return nil, err
+			}
+			term[i] = value
+		}
+		return term, nil
+	case erlang.OtpErlangList:
+		for i, element := range term.Value {
+			value, err := atomCacheResolve(element, refs)
+			if err != nil {
+				return nil, err
+			}
+			term.Value[i] = value
+		}
+		return term, nil
+	case erlang.OtpErlangMap:
+		result := make(erlang.OtpErlangMap, len(term))
+		for key, value := range term {
+			keyNew, err := atomCacheResolve(key, refs)
+			if err != nil {
+				return nil, err
+			}
+			var valueNew interface{}
+			valueNew, err = atomCacheResolve(value, refs)
+			if err != nil {
+				return nil, err
+			}
+			result[keyNew] = valueNew
+		}
+		return result, nil
+	case erlang.OtpErlangPid:
+		var err error
+		term.NodeTag, term.Node, err = atomCacheNode(term.NodeTag, term.Node, refs)
+		return term, err
+	case erlang.OtpErlangPort:
+		var err error
+		term.NodeTag, term.Node, err = atomCacheNode(term.NodeTag, term.Node, refs)
+		return term, err
+	case erlang.OtpErlangReference:
+		var err error
+		term.NodeTag, term.Node, err = atomCacheNode(term.NodeTag, term.Node, refs)
+		return term, err
+	default:
+		return termI, nil
+	}
+}
+
+func atomCacheNode(nodeTag uint8, node []byte, refs []interface{}) (uint8, []byte, error) {
+	if nodeTag != tagAtomCacheRef {
+		return nodeTag, node, nil
+	}
+	if len(node) != 1 || int(node[0]) >= len(refs) {
+		return 0, nil, ErrAtomCache
+	}
+	name, err := termAtom(refs[node[0]])
+	if err != nil {
+		return 0, nil, ErrAtomCache
+	}
+	var data []byte
+	data, err = atomEncode(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	return data[0], data[1:], nil
+}
@@ -0,0 +1,133 @@
+package dist
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func term(t *testing.T, data string) interface{} {
+	value, err := erlang.BinaryToTerm([]byte(data))
+	assertEqual(t, nil, err, "")
+	return value
+}
+
+func TestMessage(t *testing.T) {
+	pid1 := term(t, "\x83Xw\x06a@host\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01").(erlang.OtpErlangPid)
+	pid2 := term(t, "\x83Xw\x06b@host\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00\x02").(erlang.OtpErlangPid)
+	ref := term(t, "\x83Z\x00\x03w\x06a@host\x00\x00\x00\x01\x00\x00\x00\x01\x00\x00\x00\x02\x00\x00\x00\x03").(erlang.OtpErlangReference)
+	token := erlang.OtpErlangTuple{uint8(1), uint8(2)}
+	reason := erlang.OtpErlangAtomUTF8("normal")
+	messages := []Message{
+		Link{From: pid1, To: pid2},
+		Send{To: pid2, Message: erlang.OtpErlangBinary{Value: []byte("data"), Bits: 8}},
+		Send{To: pid2, Message: erlang.OtpErlangBinary{Value: []byte("data"), Bits: 8}, TraceToken: token},
+		Exit{From: pid1, To: pid2, Reason: reason},
+		Exit{From: pid1, To: pid2, Reason: reason, TraceToken: token},
+		Exit{From: pid1, To: pid2, Reason: reason, Payload: true},
+		Exit{From: pid1, To: pid2, Reason: reason, TraceToken: token, Payload: true},
+		Unlink{From: pid1, To: pid2},
+		NodeLink{},
+		RegSend{From: pid1, ToName: "name", Message: uint8(1)},
+		RegSend{From: pid1, ToName: "name", Message: uint8(1), TraceToken: token},
+		GroupLeader{From: pid1, To: pid2},
+		Exit2{From: pid1, To: pid2, Reason: reason},
+		Exit2{From: pid1, To: pid2, Reason: reason, TraceToken: token},
+		Exit2{From: pid1, To: pid2, Reason: reason, Payload: true},
+		Exit2{From: pid1, To: pid2, Reason: reason, TraceToken: token, Payload: true},
+		MonitorP{From: pid1, To: pid2, Ref: ref},
+		MonitorP{From: pid1, To: erlang.OtpErlangAtomUTF8("name"), Ref: ref},
+		DemonitorP{From: pid1, To: pid2, Ref: ref},
+		MonitorPExit{From: pid1, To: pid2, Ref: ref, Reason: reason},
+		MonitorPExit{From: pid1, To: pid2, Ref: ref, Reason: reason, Payload: true},
+		SendSender{From: pid1, To: pid2, Message: uint8(1)},
+		SendSender{From: pid1, To: pid2, Message: uint8(1), TraceToken: token},
+		SpawnRequest{ReqID: ref, From: pid1, GroupLeader: pid2, Module: "m", Function: "f", Arity: 1,
+			Options: erlang.OtpErlangList{Value: []interface{}{erlang.OtpErlangAtomUTF8("link")}},
+			Args:    erlang.OtpErlangList{Value: []interface{}{uint8(1)}}},
+		SpawnRequest{ReqID: ref, From: pid1, GroupLeader: pid2, Module: "m", Function: "f", Arity: 1,
+			Options:    erlang.OtpErlangList{Value: []interface{}{erlang.OtpErlangAtomUTF8("link")}},
+			Args:       erlang.OtpErlangList{Value: []interface{}{uint8(1)}},
+			TraceToken: token},
+		SpawnReply{ReqID: ref, To: pid1, Flags: 1, Result: pid2},
+		SpawnReply{ReqID: ref, To: pid1, Flags: 1, Result: pid2, TraceToken: token},
+		AliasSend{From: pid1, Alias: ref, Message: uint8(1)},
+		AliasSend{From: pid1, Alias: ref, Message: uint8(1), TraceToken: token},
+		UnlinkID{ID: 1, From: pid1, To: pid2},
+		UnlinkIDAck{ID: 1 << 40, From: pid1, To: pid2},
+	}
+	for _, flags := range []uint64{0, FlagDistHdrAtomCache} {
+		for _, message := range messages {
+			data, err := Encode(message, flags)
+			assertEqual(t, nil, err, "")
+			if flags == 0 {
+				assertEqual(t, uint8('p'), data[0], "")
+			} else {
+				assertEqual(t, "\x83D\x00", string(data[:3]), "")
+			}
+			result, err := Decode(data, nil)
+			assertEqual(t, nil, err, "")
+			assertEqual(t, message, result, "")
+		}
+	}
+}
+
+func TestMessageAtomCache(t *testing.T) {
+	cache := new(AtomCache)
+	// {6, Pid, '', foo} with foo as the payload and the pid node
+	pid := "X\x52\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01"
+	data := "\x83D\x01\x08\x05\x03foo" + "h\x04a\x06" + pid + "w\x00R\x00" + "R\x00"
+	message, err := Decode([]byte(data), cache)
+	assertEqual(t, nil, err, "")
+	from := term(t, "\x83Xw\x03foo\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01")
+	assertEqual(t, RegSend{From: from.(erlang.OtpErlangPid), ToName: "foo", Message: erlang.OtpErlangAtomUTF8("foo")}, message, "")
+	// the cache entry is used without the atom text
+	data = "\x83D\x01\x00\x05" + "h\x04a\x06" + pid + "w\x00R\x00" + "R\x00"
+	message, err = Decode([]byte(data), cache)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "foo", message.(RegSend).ToName, "")
+	_, err = Decode([]byte(data), nil)
+	assertEqual(t, ErrAtomCache, err, "")
+	_, err = Decode([]byte("\x83D\x00h\x01a\x63"), nil)
+	assertEqual(t, ErrControl, err, "")
+	_, err = Decode([]byte("\x83E"), nil)
+	assertEqual(t, ErrMessage, err, "")
+}
+
+func TestFrame(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	assertEqual(t, nil, WriteFrame(buffer, []byte("abc")), "")
+	assertEqual(t, nil, WriteFrame(buffer, nil), "")
+	assertEqual(t, "\x00\x00\x00\x03abc\x00\x00\x00\x00", buffer.String(), "")
+	data, err := ReadFrame(buffer)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "abc", string(data), "")
+	data, err = ReadFrame(buffer)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 0, len(data), "")
+}
@@ -0,0 +1,321 @@
+// Package epmd provides the Erlang Port Mapper Daemon protocol
+package epmd
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// PortDefault is the EPMD port when ERL_EPMD_PORT is not set
+	PortDefault = 4369
+
+	// NodeTypeNormal is a visible Erlang node
+	NodeTypeNormal = 77
+	// NodeTypeHidden is a hidden Erlang node
+	NodeTypeHidden = 72
+	// ProtocolTCP is TCP/IPv4
+	ProtocolTCP = 0
+
+	// request/response tags
+	tagAlive2Req      = 120
+	tagAlive2Resp     = 121
+	tagAlive2XResp    = 118
+	tagPortPlease2Req = 122
+	tagPort2Resp      = 119
+	tagNamesReq       = 110
+	tagDumpReq        = 100
+)
+
+// EPMD errors
+var (
+	ErrNotRegistered = errors.New("node not registered")
+	ErrRegister      = errors.New("node registration failed")
+	ErrResponse      = errors.New("invalid response")
+	ErrRequest       = errors.New("invalid request")
+)
+
+// Node is the registration information of an Erlang node
+type Node struct {
+	Name           string // without the "@host" suffix
+	Port           uint16
+	NodeType       uint8
+	Protocol       uint8
+	HighestVersion uint16
+	LowestVersion  uint16
+	Extra          []byte
+}
+
+// Name is a single NAMES_REQ result
+type Name struct {
+	Name string
+	Port int
+}
+
+// Client makes EPMD requests
+type Client struct {
+	Address string        // host:port of EPMD
+	Timeout time.Duration // connect and request timeout, if positive
+}
+
+// Registration keeps a node registered until it is closed
+type Registration struct {
+	Creation uint32
+	conn     net.Conn
+}
+
+// NewClient creates a Client for the EPMD on host
+// (the port is ERL_EPMD_PORT or PortDefault)
+func NewClient(host string) *Client {
+	port := strconv.Itoa(PortDefault)
+	value := os.Getenv("ERL_EPMD_PORT")
+	if value != "" {
+		port = value
+	}
+	return &Client{Address: net.JoinHostPort(host, port)}
+}
+
+// Register sends ALIVE2_REQ with the connection kept open
+// for the lifetime of the registration
+func (c *Client) Register(node Node) (*Registration, error) {
+	request := new(bytes.Buffer)
+	request.WriteByte(tagAlive2Req)
+	binary.Write(request, binary.BigEndian, node.Port)
+	request.WriteByte(node.NodeType)
+	request.WriteByte(node.Protocol)
+	binary.Write(request, binary.BigEndian, node.HighestVersion)
+	binary.Write(request, binary.BigEndian, node.LowestVersion)
+	err := writeString(request, []byte(node.Name))
+	if err != nil {
+		return nil, err
+	}
+	err = writeString(request, node.Extra)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	conn, err = c.request(request.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	var response [2]byte
+	_, err = io.ReadFull(conn, response[:])
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if response[1] != 0 {
+		conn.Close()
+		return nil, ErrRegister
+	}
+	var creation uint32
+	switch response[0] {
+	case tagAlive2Resp:
+		var value uint16
+		err = binary.Read(conn, binary.BigEndian, &value)
+		creation = uint32(value)
+	case tagAlive2XResp:
+		err = binary.Read(conn, binary.BigEndian, &creation)
+	default:
+		err = ErrResponse
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return &Registration{Creation: creation, conn: conn}, nil
+}
+
+// Close unregisters the node
+func (r *Registration) Close() error {
+	return r.conn.Close()
+}
+
+// PortPlease sends PORT_PLEASE2_REQ to get the registration information
+// of the node name
+func (c *Client) PortPlease(name string) (Node, error) {
+	conn, err := c.request(append([]byte{tagPortPlease2Req}, name...))
+	if err != nil {
+		return Node{}, err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	var response [2]byte
+	_, err = io.ReadFull(reader, response[:])
+	if err != nil {
+		return Node{}, err
+	}
+	if response[0] != tagPort2Resp {
+		return Node{}, ErrResponse
+	}
+	if response[1] != 0 {
+		return Node{}, ErrNotRegistered
+	}
+	var node Node
+	var header [8]byte
+	_, err = io.ReadFull(reader, header[:])
+	if err != nil {
+		return Node{}, err
+	}
+	node.Port = binary.BigEndian.Uint16(header[0:2])
+	node.NodeType = header[2]
+	node.Protocol = header[3]
+	node.HighestVersion = binary.BigEndian.Uint16(header[4:6])
+	node.LowestVersion = binary.BigEndian.Uint16(header[6:8])
+	var value []byte
+	value, err = readString(reader)
+	if err != nil {
+		return Node{}, err
+	}
+	node.Name = string(value)
+	node.Extra, err = readString(reader)
+	if err != nil {
+		return Node{}, err
+	}
+	return node, nil
+}
+
+var namesLine = regexp.MustCompile(`^name (.+) at port (\d+)$`)
+
+// Names sends NAMES_REQ to get the EPMD port and the registered nodes
+func (c *Client) Names() (int, []Name, error) {
+	lines, port, err := c.text(tagNamesReq)
+	if err != nil {
+		return 0, nil, err
+	}
+	var names []Name
+	for _, line := range lines {
+		match := namesLine.FindStringSubmatch(line)
+		if match == nil {
+			return 0, nil, ErrResponse
+		}
+		var value int
+		value, err = strconv.Atoi(match[2])
+		if err != nil {
+			return 0, nil, ErrResponse
+		}
+		names = append(names, Name{Name: match[1], Port: value})
+	}
+	return port, names, nil
+}
+
+// Dump sends DUMP_REQ to get the EPMD port and the text description
+// of all nodes
+func (c *Client) Dump() (int, []string, error) {
+	lines, port, err := c.text(tagDumpReq)
+	if err != nil {
+		return 0, nil, err
+	}
+	return port, lines, nil
+}
+
+func (c *Client) text(tag uint8) ([]string, int, error) {
+	conn, err := c.request([]byte{tag})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	var port uint32
+	err = binary.Read(reader, binary.BigEndian, &port)
+	if err != nil {
+		return nil, 0, err
+	}
+	var data []byte
+	data, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	var lines []string
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.Trim(line, "\x00 ")
+		if len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines, int(port), nil
+}
+
+func (c *Client) request(request []byte) (net.Conn, error) {
+	if len(request) > math.MaxUint16 {
+		return nil, ErrRequest
+	}
+	var conn net.Conn
+	var err error
+	if c.Timeout > 0 {
+		conn, err = net.DialTimeout("tcp", c.Address, c.Timeout)
+	} else {
+		conn, err = net.Dial("tcp", c.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+	data := make([]byte, 2, 2+len(request))
+	binary.BigEndian.PutUint16(data, uint16(len(request)))
+	_, err = conn.Write(append(data, request...))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func writeString(buffer *bytes.Buffer, value []byte) error {
+	if len(value) > math.MaxUint16 {
+		return ErrRequest
+	}
+	binary.Write(buffer, binary.BigEndian, uint16(len(value)))
+	buffer.Write(value)
+	return nil
+}
+
+func readString(reader io.Reader) ([]byte, error) {
+	var length uint16
+	err := binary.Read(reader, binary.BigEndian, &length)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]byte, length)
+	_, err = io.ReadFull(reader, value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
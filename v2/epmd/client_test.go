@@ -0,0 +1,133 @@
+package epmd
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+// fakeEPMD accepts a single connection for each response, providing the
+// request data that was received
+func fakeEPMD(t *testing.T, responses ...[]byte) (*Client, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assertEqual(t, nil, err, "")
+	requests := make(chan []byte, len(responses))
+	go func() {
+		defer listener.Close()
+		for _, response := range responses {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			var length uint16
+			binary.Read(conn, binary.BigEndian, &length)
+			request := make([]byte, length)
+			io.ReadFull(conn, request)
+			requests <- request
+			conn.Write(response)
+			conn.Close()
+		}
+	}()
+	return &Client{Address: listener.Addr().String()}, requests
+}
+
+func TestClientRegister(t *testing.T) {
+	client, requests := fakeEPMD(t,
+		[]byte("v\x00\x01\x02\x03\x04"),
+		[]byte("y\x00\x00\x03"),
+		[]byte("y\x01\x00\x00"))
+	node := Node{
+		Name:           "test",
+		Port:           5000,
+		NodeType:       NodeTypeNormal,
+		Protocol:       ProtocolTCP,
+		HighestVersion: 6,
+		LowestVersion:  5,
+	}
+	registration, err := client.Register(node)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, uint32(0x01020304), registration.Creation, "")
+	assertEqual(t, nil, registration.Close(), "")
+	assertEqual(t, "x\x13\x88M\x00\x00\x06\x00\x05\x00\x04test\x00\x00", string(<-requests), "")
+	registration, err = client.Register(node)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, uint32(3), registration.Creation, "")
+	registration.Close()
+	<-requests
+	_, err = client.Register(node)
+	assertEqual(t, ErrRegister, err, "")
+}
+
+func TestClientPortPlease(t *testing.T) {
+	client, requests := fakeEPMD(t,
+		[]byte("w\x00\x13\x88H\x00\x00\x06\x00\x05\x00\x04test\x00\x01x"),
+		[]byte("w\x01"))
+	node, err := client.PortPlease("test")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "ztest", string(<-requests), "")
+	assertEqual(t, Node{
+		Name:           "test",
+		Port:           5000,
+		NodeType:       NodeTypeHidden,
+		Protocol:       ProtocolTCP,
+		HighestVersion: 6,
+		LowestVersion:  5,
+		Extra:          []byte("x"),
+	}, node, "")
+	_, err = client.PortPlease("missing")
+	assertEqual(t, ErrNotRegistered, err, "")
+}
+
+func TestClientNames(t *testing.T) {
+	client, requests := fakeEPMD(t,
+		[]byte("\x00\x00\x11\x11name a at port 5000\nname b at port 5001\n"),
+		[]byte("\x00\x00\x11\x11active name     <a> at port 5000, fd = 4\n"))
+	port, names, err := client.Names()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "n", string(<-requests), "")
+	assertEqual(t, 4369, port, "")
+	assertEqual(t, []Name{{Name: "a", Port: 5000}, {Name: "b", Port: 5001}}, names, "")
+	port, lines, err := client.Dump()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "d", string(<-requests), "")
+	assertEqual(t, 4369, port, "")
+	assertEqual(t, []string{"active name     <a> at port 5000, fd = 4"}, lines, "")
+}
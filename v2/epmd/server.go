@@ -0,0 +1,226 @@
+package epmd
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Server is an EPMD that keeps each node registered
+// while the ALIVE2_REQ connection remains open
+type Server struct {
+	mutex    sync.Mutex
+	nodes    map[string]*serverNode
+	creation uint32
+	id       int
+}
+
+type serverNode struct {
+	node Node
+	id   int
+}
+
+// NewServer creates an EPMD Server
+func NewServer() *Server {
+	return &Server{
+		nodes:    make(map[string]*serverNode),
+		creation: uint32(time.Now().UnixNano()),
+	}
+}
+
+// Serve handles EPMD requests from the listener connections
+// until the listener fails
+func (s *Server) Serve(listener net.Listener) error {
+	var port int
+	address, ok := listener.Addr().(*net.TCPAddr)
+	if ok {
+		port = address.Port
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handle(conn, port)
+	}
+}
+
+// Nodes provides the registered nodes sorted by name
+func (s *Server) Nodes() []Node {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	nodes := make([]Node, 0, len(s.nodes))
+	for _, registered := range s.nodes {
+		nodes = append(nodes, registered.node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+func (s *Server) handle(conn net.Conn, port int) {
+	defer conn.Close()
+	var length uint16
+	err := binary.Read(conn, binary.BigEndian, &length)
+	if err != nil || length == 0 {
+		return
+	}
+	request := make([]byte, length)
+	_, err = io.ReadFull(conn, request)
+	if err != nil {
+		return
+	}
+	switch request[0] {
+	case tagAlive2Req:
+		s.alive2(conn, request[1:])
+	case tagPortPlease2Req:
+		s.portPlease2(conn, string(request[1:]))
+	case tagNamesReq:
+		s.text(conn, port, "name %s at port %d\n", false)
+	case tagDumpReq:
+		s.text(conn, port, "active name     <%s> at port %d, fd = %d\n", true)
+	}
+}
+
+func (s *Server) alive2(conn net.Conn, request []byte) {
+	reader := bytes.NewReader(request)
+	var node Node
+	var header [8]byte
+	_, err := io.ReadFull(reader, header[:])
+	if err != nil {
+		return
+	}
+	node.Port = binary.BigEndian.Uint16(header[0:2])
+	node.NodeType = header[2]
+	node.Protocol = header[3]
+	node.HighestVersion = binary.BigEndian.Uint16(header[4:6])
+	node.LowestVersion = binary.BigEndian.Uint16(header[6:8])
+	var name []byte
+	name, err = readString(reader)
+	if err != nil || len(name) == 0 {
+		return
+	}
+	node.Name = string(name)
+	node.Extra, err = readString(reader)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	_, exists := s.nodes[node.Name]
+	var registered *serverNode
+	var creation uint32
+	if !exists {
+		s.id += 1
+		s.creation += 1
+		if s.creation == 0 {
+			s.creation = 1
+		}
+		creation = s.creation
+		registered = &serverNode{node: node, id: s.id}
+		s.nodes[node.Name] = registered
+	}
+	s.mutex.Unlock()
+
+	var result uint8
+	if exists {
+		result = 1
+	}
+	response := new(bytes.Buffer)
+	if node.HighestVersion >= 6 {
+		response.Write([]byte{tagAlive2XResp, result})
+		binary.Write(response, binary.BigEndian, creation)
+	} else {
+		if !exists {
+			creation = creation%3 + 1
+		}
+		response.Write([]byte{tagAlive2Resp, result})
+		binary.Write(response, binary.BigEndian, uint16(creation))
+	}
+	_, err = conn.Write(response.Bytes())
+	if err == nil && !exists {
+		// registered until the connection is closed
+		io.Copy(io.Discard, conn)
+	}
+	if !exists {
+		s.mutex.Lock()
+		if s.nodes[node.Name] == registered {
+			delete(s.nodes, node.Name)
+		}
+		s.mutex.Unlock()
+	}
+}
+
+func (s *Server) portPlease2(conn net.Conn, name string) {
+	s.mutex.Lock()
+	registered, exists := s.nodes[name]
+	s.mutex.Unlock()
+	if !exists {
+		conn.Write([]byte{tagPort2Resp, 1})
+		return
+	}
+	node := registered.node
+	response := new(bytes.Buffer)
+	response.Write([]byte{tagPort2Resp, 0})
+	binary.Write(response, binary.BigEndian, node.Port)
+	response.WriteByte(node.NodeType)
+	response.WriteByte(node.Protocol)
+	binary.Write(response, binary.BigEndian, node.HighestVersion)
+	binary.Write(response, binary.BigEndian, node.LowestVersion)
+	writeString(response, []byte(node.Name))
+	writeString(response, node.Extra)
+	conn.Write(response.Bytes())
+}
+
+func (s *Server) text(conn net.Conn, port int, format string, dump bool) {
+	s.mutex.Lock()
+	names := make([]string, 0, len(s.nodes))
+	for name := range s.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	response := new(bytes.Buffer)
+	binary.Write(response, binary.BigEndian, uint32(port))
+	for _, name := range names {
+		registered := s.nodes[name]
+		if dump {
+			fmt.Fprintf(response, format, name, registered.node.Port, registered.id)
+			// each dump line is null terminated, like the C implementation
+			response.WriteByte(0)
+		} else {
+			fmt.Fprintf(response, format, name, registered.node.Port)
+		}
+	}
+	s.mutex.Unlock()
+	conn.Write(response.Bytes())
+}
@@ -0,0 +1,93 @@
+package epmd
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assertEqual(t, nil, err, "")
+	server := NewServer()
+	done := make(chan error)
+	go func() {
+		done <- server.Serve(listener)
+	}()
+	client := &Client{Address: listener.Addr().String(), Timeout: time.Second}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	node1 := Node{Name: "node1", Port: 5001, NodeType: NodeTypeNormal, HighestVersion: 6, LowestVersion: 5}
+	node2 := Node{Name: "node2", Port: 5002, NodeType: NodeTypeHidden, HighestVersion: 5, LowestVersion: 5, Extra: []byte("extra")}
+	registration1, err := client.Register(node1)
+	assertEqual(t, nil, err, "")
+	registration2, err := client.Register(node2)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, true, registration2.Creation >= 1 && registration2.Creation <= 3, "")
+	_, err = client.Register(node1)
+	assertEqual(t, ErrRegister, err, "")
+
+	node, err := client.PortPlease("node2")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, node2, node, "")
+	_, err = client.PortPlease("node3")
+	assertEqual(t, ErrNotRegistered, err, "")
+
+	epmdPort, names, err := client.Names()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, port, epmdPort, "")
+	assertEqual(t, []Name{{Name: "node1", Port: 5001}, {Name: "node2", Port: 5002}}, names, "")
+	_, lines, err := client.Dump()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, []string{
+		"active name     <node1> at port 5001, fd = 1",
+		"active name     <node2> at port 5002, fd = 2",
+	}, lines, "")
+
+	// the registration ends with the connection
+	assertEqual(t, nil, registration1.Close(), "")
+	for i := 0; i < 100; i++ {
+		_, err = client.PortPlease("node1")
+		if err == ErrNotRegistered {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertEqual(t, ErrNotRegistered, err, "")
+	nodes := server.Nodes()
+	assertEqual(t, 1, len(nodes), "")
+	assertEqual(t, "node2", nodes[0].Name, "")
+	registration1, err = client.Register(node1)
+	assertEqual(t, nil, err, "")
+	registration1.Close()
+	registration2.Close()
+
+	listener.Close()
+	err = <-done
+	assertEqual(t, false, err == nil, strconv.Itoa(port))
+}
@@ -0,0 +1,166 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"math"
+	"math/big"
+)
+
+// The Append functions encode terms with the same functions as TermToBinary
+// without boxing values in interface{} (used by code that cmd/erlgen
+// generates).  The version tag is only added by AppendVersion.
+
+// AppendVersion appends the version tag that starts encoded data
+func AppendVersion(b []byte) []byte {
+	return append(b, tagVersion)
+}
+
+// AppendInt appends an integer
+func AppendInt(b []byte, value int64) []byte {
+	b, _ = appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return intToBinary(value, buffer)
+	})
+	return b
+}
+
+// AppendUint appends an unsigned integer
+func AppendUint(b []byte, value uint64) []byte {
+	if value <= math.MaxInt64 {
+		return AppendInt(b, int64(value))
+	}
+	b, _ = appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return bignumToBinary(new(big.Int).SetUint64(value), buffer)
+	})
+	return b
+}
+
+// AppendFloat appends a float
+func AppendFloat(b []byte, value float64) []byte {
+	b, _ = appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return floatToBinary(value, buffer)
+	})
+	return b
+}
+
+// AppendBool appends the true or false atom
+func AppendBool(b []byte, value bool) []byte {
+	if value {
+		b, _ = AppendAtomUTF8(b, "true")
+		return b
+	}
+	b, _ = AppendAtomUTF8(b, "false")
+	return b
+}
+
+// AppendUndefined appends the undefined atom (a nil value)
+func AppendUndefined(b []byte) []byte {
+	b, _ = AppendAtomUTF8(b, undefined)
+	return b
+}
+
+// AppendAtom appends an OtpErlangAtom
+func AppendAtom(b []byte, name string) ([]byte, error) {
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return atomToBinary(name, buffer)
+	})
+}
+
+// AppendAtomUTF8 appends an OtpErlangAtomUTF8
+func AppendAtomUTF8(b []byte, name string) ([]byte, error) {
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return atomUtf8ToBinary(name, buffer)
+	})
+}
+
+// AppendString appends a string (a list of bytes)
+func AppendString(b []byte, value string) ([]byte, error) {
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return stringToBinary(value, buffer)
+	})
+}
+
+// AppendBinary appends a binary
+func AppendBinary(b []byte, value []byte) ([]byte, error) {
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return binaryObjectToBinary(OtpErlangBinary{Value: value, Bits: 8}, buffer)
+	})
+}
+
+// AppendTupleHeader appends the start of a tuple with arity elements
+// that follow
+func AppendTupleHeader(b []byte, arity int) ([]byte, error) {
+	if arity < 0 {
+		return b, outputErrorNew("invalid tuple arity")
+	}
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return tupleHeaderToBinary(arity, buffer)
+	})
+}
+
+// AppendListHeader appends the start of a list with length elements
+// that follow before AppendNil (an empty list is only AppendNil)
+func AppendListHeader(b []byte, length int) ([]byte, error) {
+	if length <= 0 {
+		return b, outputErrorNew("invalid list length")
+	}
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return listHeaderToBinary(length, buffer)
+	})
+}
+
+// AppendNil appends an empty list
+func AppendNil(b []byte) []byte {
+	return append(b, tagNilExt)
+}
+
+// AppendMapHeader appends the start of a map with size key/value pairs
+// that follow
+func AppendMapHeader(b []byte, size int) ([]byte, error) {
+	if size < 0 {
+		return b, outputErrorNew("invalid map size")
+	}
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return mapHeaderToBinary(size, buffer)
+	})
+}
+
+// AppendTerm appends any term that TermToBinary accepts
+func AppendTerm(b []byte, term interface{}) ([]byte, error) {
+	return appendBuffer(b, func(buffer *bytes.Buffer) (*bytes.Buffer, error) {
+		return termsToBinary(term, buffer)
+	})
+}
+
+// appendBuffer appends with a TermToBinary function
+// (b is unchanged if an error occurs)
+func appendBuffer(b []byte, f func(*bytes.Buffer) (*bytes.Buffer, error)) ([]byte, error) {
+	buffer, err := f(bytes.NewBuffer(b))
+	if err != nil {
+		return b, err
+	}
+	return buffer.Bytes(), nil
+}
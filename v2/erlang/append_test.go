@@ -0,0 +1,107 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	atomLong := strings.Repeat("a", 256)
+	stringLong := strings.Repeat("s", math.MaxUint16+1)
+	tests := []struct {
+		term   interface{}
+		result []byte
+	}{
+		{uint8(0), AppendInt(nil, 0)},
+		{uint8(255), AppendInt(nil, 255)},
+		{int32(-1), AppendInt(nil, -1)},
+		{int32(math.MaxInt32), AppendInt(nil, math.MaxInt32)},
+		{big.NewInt(math.MinInt64), AppendInt(nil, math.MinInt64)},
+		{big.NewInt(math.MaxInt32 + 1), AppendUint(nil, math.MaxInt32+1)},
+		{new(big.Int).SetUint64(math.MaxUint64), AppendUint(nil, math.MaxUint64)},
+		{uint8(7), AppendUint(nil, 7)},
+		{1.5, AppendFloat(nil, 1.5)},
+		{true, AppendBool(nil, true)},
+		{false, AppendBool(nil, false)},
+		{nil, AppendUndefined(nil)},
+		{"", AppendNil(nil)},
+	}
+	for _, test := range tests {
+		expect, err := TermToBinary(test.term, -1)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, expect[1:], test.result, TermString(test.term))
+	}
+	encode := func(term interface{}) []byte {
+		data, err := TermToBinary(term, -1)
+		assertEqual(t, nil, err, "")
+		return data[1:]
+	}
+	result, err := AppendAtom(nil, "atom")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, encode(OtpErlangAtom("atom")), result, "")
+	result, err = AppendAtom(nil, atomLong)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, encode(OtpErlangAtom(atomLong)), result, "")
+	result, err = AppendAtomUTF8(nil, "ǻtom")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, encode(OtpErlangAtomUTF8("ǻtom")), result, "")
+	_, err = AppendAtom(nil, strings.Repeat("a", math.MaxUint16+1))
+	assertEqual(t, "uint16 overflow", err.Error(), "")
+	result, err = AppendString(nil, "text")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, encode("text"), result, "")
+	result, err = AppendString(nil, stringLong)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, encode(stringLong), result, "")
+	result, err = AppendBinary(nil, []byte("data"))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, encode([]byte("data")), result, "")
+
+	result, err = AppendTupleHeader(AppendVersion(nil), 2)
+	assertEqual(t, nil, err, "")
+	result = AppendInt(result, 1)
+	result, err = AppendListHeader(result, 1)
+	assertEqual(t, nil, err, "")
+	result, err = AppendMapHeader(result, 1)
+	assertEqual(t, nil, err, "")
+	result, err = AppendAtom(result, "key")
+	assertEqual(t, nil, err, "")
+	result, err = AppendTerm(result, OtpErlangTuple{uint8(1), "a"})
+	assertEqual(t, nil, err, "")
+	result = AppendNil(result)
+	term, err := BinaryToTerm(result)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{uint8(1), OtpErlangList{Value: []interface{}{
+		OtpErlangMap{OtpErlangAtom("key"): OtpErlangTuple{uint8(1), "a"}},
+	}}}, term, "")
+	_, err = AppendListHeader(nil, 0)
+	assertEqual(t, "invalid list length", err.Error(), "")
+	_, err = AppendTerm(nil, struct{}{})
+	assertEqual(t, "unknown go type", err.Error(), "")
+}
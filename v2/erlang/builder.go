@@ -0,0 +1,304 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Builder encodes a term into the Erlang External Term Format
+// one element at a time, like ei_x_buff in erl_interface
+// (errors are kept until Bytes is called)
+type Builder struct {
+	buffer   bytes.Buffer
+	stack    []builderContainer
+	complete bool
+	err      error
+}
+
+type builderContainer struct {
+	tag    uint8
+	length int // elements expected, negative when the list length is unknown
+	count  int // elements written
+	offset int // list length field offset
+}
+
+// size describes the elements the container expects
+func (c builderContainer) size() string {
+	switch c.tag {
+	case tagListExt:
+		return "list length " + strconv.Itoa(c.length)
+	case tagMapExt:
+		return "map size " + strconv.Itoa(c.length/2)
+	default:
+		return "tuple arity " + strconv.Itoa(c.length)
+	}
+}
+
+// NewBuilder creates a Builder for a single term
+func NewBuilder() *Builder {
+	b := &Builder{}
+	b.buffer.WriteByte(tagVersion)
+	return b
+}
+
+// BeginTuple starts a tuple with arity elements that follow
+// (the tuple ends after the last element)
+func (b *Builder) BeginTuple(arity int) {
+	if !b.element() {
+		return
+	}
+	switch {
+	case arity < 0:
+		b.err = outputErrorNew("invalid tuple arity")
+		return
+	case arity <= math.MaxUint8:
+		b.write([]byte{tagSmallTupleExt, uint8(arity)})
+	case uint64(arity) <= math.MaxUint32:
+		b.write([]byte{tagLargeTupleExt})
+		b.writeUint32(uint32(arity))
+	default:
+		b.err = outputErrorNew("uint32 overflow")
+		return
+	}
+	b.stack = append(b.stack, builderContainer{tag: tagSmallTupleExt, length: arity})
+	b.end()
+}
+
+// BeginList starts a list with length elements that follow before EndList
+// (a negative length is determined when EndList is called)
+func (b *Builder) BeginList(length int) {
+	if !b.element() {
+		return
+	}
+	if uint64(length) > math.MaxUint32 && length >= 0 {
+		b.err = outputErrorNew("uint32 overflow")
+		return
+	}
+	offset := b.buffer.Len()
+	b.write([]byte{tagListExt})
+	if length < 0 {
+		b.writeUint32(0)
+	} else {
+		b.writeUint32(uint32(length))
+	}
+	b.stack = append(b.stack, builderContainer{tag: tagListExt, length: length, offset: offset})
+}
+
+// EndList ends the current list with an empty list tail
+func (b *Builder) EndList() {
+	b.endList(false)
+}
+
+// EndListImproper ends the current list using the last element as the tail
+// (the BeginList length includes the tail)
+func (b *Builder) EndListImproper() {
+	b.endList(true)
+}
+
+// Map starts a map with size key/value pairs that follow
+// (the map ends after the last value)
+func (b *Builder) Map(size int) {
+	if !b.element() {
+		return
+	}
+	if size < 0 || uint64(size) > math.MaxUint32 {
+		b.err = outputErrorNew("invalid map size")
+		return
+	}
+	b.write([]byte{tagMapExt})
+	b.writeUint32(uint32(size))
+	b.stack = append(b.stack, builderContainer{tag: tagMapExt, length: size * 2})
+	b.end()
+}
+
+// Atom adds an atom
+func (b *Builder) Atom(name string) {
+	b.Term(OtpErlangAtomUTF8(name))
+}
+
+// Int adds an integer
+func (b *Builder) Int(value int64) {
+	switch {
+	case value >= 0 && value <= math.MaxUint8:
+		b.Term(uint8(value))
+	case value >= math.MinInt32 && value <= math.MaxInt32:
+		b.Term(int32(value))
+	default:
+		b.Term(big.NewInt(value))
+	}
+}
+
+// Float adds a float
+func (b *Builder) Float(value float64) {
+	b.Term(value)
+}
+
+// Binary adds a binary
+func (b *Builder) Binary(value []byte) {
+	b.Term(OtpErlangBinary{Value: value, Bits: 8})
+}
+
+// String adds a string (a list of bytes)
+func (b *Builder) String(value string) {
+	b.Term(value)
+}
+
+// Pid adds a pid
+func (b *Builder) Pid(value OtpErlangPid) {
+	b.Term(value)
+}
+
+// Nil adds an empty list
+func (b *Builder) Nil() {
+	b.Term(OtpErlangList{})
+}
+
+// Term adds any term that TermToBinary accepts
+func (b *Builder) Term(term interface{}) {
+	if !b.element() {
+		return
+	}
+	_, err := termsToBinary(term, &b.buffer)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.end()
+}
+
+// Bytes provides the encoded term after checking it is complete
+func (b *Builder) Bytes() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.stack) > 0 {
+		top := b.stack[len(b.stack)-1]
+		switch top.tag {
+		case tagListExt:
+			return nil, outputErrorNew("list missing EndList")
+		case tagMapExt:
+			return nil, outputErrorNew(top.size() + " incomplete")
+		default:
+			return nil, outputErrorNew(top.size() + " has " +
+				strconv.Itoa(top.count) + " elements")
+		}
+	}
+	if !b.complete {
+		return nil, outputErrorNew("no term")
+	}
+	return b.buffer.Bytes(), nil
+}
+
+// element counts a new element in the current container
+func (b *Builder) element() bool {
+	if b.err != nil {
+		return false
+	}
+	if len(b.stack) == 0 {
+		if b.complete {
+			b.err = outputErrorNew("term already complete")
+			return false
+		}
+		return true
+	}
+	top := &b.stack[len(b.stack)-1]
+	if top.length >= 0 && top.count == top.length {
+		b.err = outputErrorNew(top.size() + " exceeded")
+		return false
+	}
+	top.count += 1
+	return true
+}
+
+// end removes the tuples and maps that have all their elements
+func (b *Builder) end() {
+	for len(b.stack) > 0 {
+		top := b.stack[len(b.stack)-1]
+		if top.tag == tagListExt || top.count < top.length {
+			return
+		}
+		b.stack = b.stack[:len(b.stack)-1]
+	}
+	b.complete = true
+}
+
+func (b *Builder) endList(improper bool) {
+	if b.err != nil {
+		return
+	}
+	if len(b.stack) == 0 || b.stack[len(b.stack)-1].tag != tagListExt {
+		b.err = outputErrorNew("EndList without BeginList")
+		return
+	}
+	top := b.stack[len(b.stack)-1]
+	length := top.count
+	if improper {
+		if length < 2 {
+			b.err = outputErrorNew("improper list requires 2 elements")
+			return
+		}
+		length -= 1
+	}
+	if top.length >= 0 && top.count != top.length {
+		b.err = outputErrorNew(top.size() + " has " +
+			strconv.Itoa(top.count) + " elements")
+		return
+	}
+	if uint64(length) > math.MaxUint32 {
+		b.err = outputErrorNew("uint32 overflow")
+		return
+	}
+	b.stack = b.stack[:len(b.stack)-1]
+	if length == 0 {
+		// an empty list is only the tail
+		b.buffer.Truncate(top.offset)
+	} else {
+		binary.BigEndian.PutUint32(b.buffer.Bytes()[top.offset+1:], uint32(length))
+	}
+	if !improper {
+		b.write([]byte{tagNilExt})
+	}
+	b.end()
+}
+
+func (b *Builder) write(data []byte) {
+	_, err := b.buffer.Write(data)
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *Builder) writeUint32(value uint32) {
+	var data [4]byte
+	binary.BigEndian.PutUint32(data[:], value)
+	b.write(data[:])
+}
@@ -0,0 +1,138 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	pid := OtpErlangPid{NodeTag: 119, Node: []byte("\x0dnonode@nohost"), ID: []byte{0, 0, 0, 83}, Serial: []byte{0, 0, 0, 0}, Creation: []byte{0, 0, 0, 0}}
+	b := NewBuilder()
+	b.BeginTuple(4)
+	b.Atom("ok")
+	b.BeginList(-1)
+	b.Int(1)
+	b.Int(-1)
+	b.Int(4294967296)
+	b.Binary([]byte("abc"))
+	b.Map(1)
+	b.Atom("a")
+	b.Float(1.5)
+	b.EndList()
+	b.Pid(pid)
+	b.BeginList(-1)
+	b.EndList()
+	data, err := b.Bytes()
+	assertEqual(t, nil, err, "")
+	expected, err := TermToBinary(OtpErlangTuple{
+		OtpErlangAtomUTF8("ok"),
+		OtpErlangList{Value: []interface{}{1, -1, int64(4294967296), []byte("abc"), OtpErlangMap{OtpErlangAtomUTF8("a"): 1.5}}},
+		pid,
+		OtpErlangList{},
+	}, -1)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, string(expected), string(data), "")
+
+	b = NewBuilder()
+	b.BeginList(3)
+	b.String("ab")
+	b.Nil()
+	b.Atom("tail")
+	b.EndListImproper()
+	data, err = b.Bytes()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x83l\x00\x00\x00\x02k\x00\x02abjw\x04tail", string(data), "")
+
+	b = NewBuilder()
+	b.BeginTuple(0)
+	data, err = b.Bytes()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x83h\x00", string(data), "")
+}
+
+func TestBuilderError(t *testing.T) {
+	var b *Builder
+	var err error
+	b = NewBuilder()
+	_, err = b.Bytes()
+	assertEqual(t, "no term", err.Error(), "")
+	b = NewBuilder()
+	b.BeginTuple(3)
+	b.Atom("a")
+	_, err = b.Bytes()
+	assertEqual(t, "tuple arity 3 has 1 elements", err.Error(), "")
+	b = NewBuilder()
+	b.Map(1)
+	b.Atom("a")
+	_, err = b.Bytes()
+	assertEqual(t, "map size 1 incomplete", err.Error(), "")
+	b = NewBuilder()
+	b.BeginList(1)
+	b.Atom("a")
+	_, err = b.Bytes()
+	assertEqual(t, "list missing EndList", err.Error(), "")
+	b = NewBuilder()
+	b.BeginList(1)
+	b.Atom("a")
+	b.Atom("b")
+	b.EndList()
+	_, err = b.Bytes()
+	assertEqual(t, "list length 1 exceeded", err.Error(), "")
+	// tuples and maps end after their last element, so only
+	// a full container on the stack is able to be exceeded
+	b = NewBuilder()
+	b.stack = append(b.stack, builderContainer{tag: tagSmallTupleExt, length: 1, count: 1})
+	b.Atom("a")
+	_, err = b.Bytes()
+	assertEqual(t, "tuple arity 1 exceeded", err.Error(), "")
+	b = NewBuilder()
+	b.stack = append(b.stack, builderContainer{tag: tagMapExt, length: 2, count: 2})
+	b.Atom("a")
+	_, err = b.Bytes()
+	assertEqual(t, "map size 1 exceeded", err.Error(), "")
+	b = NewBuilder()
+	b.BeginList(2)
+	b.Atom("a")
+	b.EndList()
+	_, err = b.Bytes()
+	assertEqual(t, "list length 2 has 1 elements", err.Error(), "")
+	b = NewBuilder()
+	b.BeginTuple(1)
+	b.EndList()
+	_, err = b.Bytes()
+	assertEqual(t, "EndList without BeginList", err.Error(), "")
+	b = NewBuilder()
+	b.Atom("a")
+	b.Atom("b")
+	_, err = b.Bytes()
+	assertEqual(t, "term already complete", err.Error(), "")
+	b = NewBuilder()
+	b.Term(struct{}{})
+	_, err = b.Bytes()
+	assertEqual(t, "unknown go type", err.Error(), "")
+}
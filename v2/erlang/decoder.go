@@ -0,0 +1,617 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+// TokenKind identifies the kind of Token provided by a Decoder
+type TokenKind int
+
+// TokenKind values listed in the order of the Erlang term types
+const (
+	TokenTupleStart TokenKind = iota + 1 // Length elements follow
+	TokenListStart                       // Length elements and a TokenListTail follow
+	TokenListTail                        // the list tail term follows
+	TokenMapStart                        // Length key/value pairs follow
+	TokenNil
+	TokenAtom
+	TokenInteger
+	TokenFloat
+	TokenString
+	TokenBinary
+	TokenPid
+	TokenPort
+	TokenReference
+	TokenFunction
+
+	tokenCompressed TokenKind = -1 // the compressed term container
+)
+
+// Token is a single event of a term provided by a Decoder
+// (Value is the same Go type BinaryToTerm provides for the term)
+type Token struct {
+	Kind   TokenKind
+	Length int
+	Value  interface{}
+}
+
+// Decoder provides the terms of Erlang External Term Format input as
+// tokens in input order, so large terms only need constant memory
+type Decoder struct {
+	source           *decoderInput
+	input            *decoderInput
+	compressed       io.ReadCloser
+	sizeUncompressed int
+	stack            []decoderContainer
+	buffer           bytes.Buffer
+}
+
+type decoderContainer struct {
+	kind      TokenKind
+	length    int
+	remaining int
+	tail      bool
+}
+
+// decoderInput counts the bytes read
+type decoderInput struct {
+	reader io.Reader
+	bytes  io.ByteReader
+	offset int
+}
+
+// NewDecoder creates a Decoder for input that contains
+// a sequence of terms that each start with the version tag
+func NewDecoder(reader io.Reader) *Decoder {
+	bytesReader, ok := reader.(io.ByteReader)
+	if !ok {
+		bufioReader := bufio.NewReader(reader)
+		reader = bufioReader
+		bytesReader = bufioReader
+	}
+	source := &decoderInput{reader: reader, bytes: bytesReader}
+	return &Decoder{source: source, input: source}
+}
+
+// Depth provides the number of tuples, lists and maps
+// that contain the next token
+func (d *Decoder) Depth() int {
+	if len(d.stack) > 0 && d.stack[0].kind == tokenCompressed {
+		return len(d.stack) - 1
+	}
+	return len(d.stack)
+}
+
+// Next provides the next token (io.EOF is returned after the last term)
+func (d *Decoder) Next() (Token, error) {
+	if len(d.stack) == 0 {
+		err := d.termStart()
+		if err != nil {
+			return Token{}, err
+		}
+	}
+	if len(d.stack) > 0 {
+		top := &d.stack[len(d.stack)-1]
+		if top.kind == TokenListStart && top.remaining == 0 && !top.tail {
+			top.tail = true
+			top.remaining = 1
+			return Token{Kind: TokenListTail}, nil
+		}
+	}
+	if len(d.stack) > 0 {
+		d.stack[len(d.stack)-1].remaining -= 1
+	}
+	iTag := d.input.offset
+	tag, err := d.input.ReadByte()
+	if err != nil {
+		return Token{}, d.error(err, iTag, -1)
+	}
+	var token Token
+	token, err = d.token(tag)
+	if err != nil {
+		return Token{}, d.error(err, iTag, int(tag))
+	}
+	switch token.Kind {
+	case TokenTupleStart:
+		d.stack = append(d.stack, decoderContainer{kind: token.Kind, length: token.Length, remaining: token.Length})
+	case TokenListStart:
+		d.stack = append(d.stack, decoderContainer{kind: token.Kind, length: token.Length, remaining: token.Length})
+		return token, nil
+	case TokenMapStart:
+		d.stack = append(d.stack, decoderContainer{kind: token.Kind, length: token.Length * 2, remaining: token.Length * 2})
+	}
+	err = d.termEnd()
+	if err != nil {
+		return Token{}, err
+	}
+	return token, nil
+}
+
+func (d *Decoder) termStart() error {
+	iVersion := d.input.offset
+	version, err := d.input.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return d.error(err, iVersion, -1)
+	}
+	if version != tagVersion {
+		return d.error(parseErrorNew(ErrInvalidVersion, "invalid version"), iVersion, -1)
+	}
+	var tag []byte
+	tag, err = d.input.peek()
+	if err != nil {
+		return d.error(err, d.input.offset, -1)
+	}
+	if tag[0] != tagCompressedZlib {
+		return nil
+	}
+	iTag := d.input.offset
+	var header []byte
+	header, err = d.input.read(1+4, nil)
+	if err != nil {
+		return d.error(err, iTag, tagCompressedZlib)
+	}
+	sizeUncompressed := binary.BigEndian.Uint32(header[1:])
+	if sizeUncompressed == 0 {
+		return d.error(parseErrorNew(ErrCompression, "compressed data null"), iTag, tagCompressedZlib)
+	}
+	d.compressed, err = zlib.NewReader(d.source)
+	if err != nil {
+		return d.error(parseErrorNew(ErrCompression, err.Error()), iTag, tagCompressedZlib)
+	}
+	d.sizeUncompressed = int(sizeUncompressed)
+	reader := bufio.NewReader(io.LimitReader(d.compressed, int64(sizeUncompressed)))
+	d.input = &decoderInput{reader: reader, bytes: reader}
+	d.stack = append(d.stack, decoderContainer{kind: tokenCompressed, length: 1, remaining: 1})
+	return nil
+}
+
+func (d *Decoder) termEnd() error {
+	for len(d.stack) > 0 {
+		top := &d.stack[len(d.stack)-1]
+		if top.remaining > 0 || (top.kind == TokenListStart && !top.tail) {
+			return nil
+		}
+		d.stack = d.stack[:len(d.stack)-1]
+		if top.kind == tokenCompressed {
+			_, err := d.input.peek()
+			if err != io.EOF {
+				return d.error(parseErrorNew(ErrUnparsedData, "unparsed data"), d.input.offset, -1)
+			}
+			if d.input.offset != d.sizeUncompressed {
+				return d.error(parseErrorNew(ErrCompression, "compression corrupt"), d.input.offset, -1)
+			}
+			_, err = io.Copy(io.Discard, d.compressed)
+			if err == nil {
+				err = d.compressed.Close()
+			}
+			if err != nil {
+				return d.error(parseErrorNew(ErrCompression, err.Error()), d.input.offset, -1)
+			}
+			d.input = d.source
+			d.compressed = nil
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) token(tag uint8) (Token, error) {
+	switch tag {
+	case tagSmallTupleExt:
+		length, err := d.input.ReadByte()
+		return Token{Kind: TokenTupleStart, Length: int(length)}, err
+	case tagLargeTupleExt:
+		length, err := d.input.uint32()
+		return Token{Kind: TokenTupleStart, Length: int(length)}, err
+	case tagListExt:
+		length, err := d.input.uint32()
+		return Token{Kind: TokenListStart, Length: int(length)}, err
+	case tagMapExt:
+		length, err := d.input.uint32()
+		return Token{Kind: TokenMapStart, Length: int(length)}, err
+	case tagNilExt:
+		value := make([]interface{}, 0)
+		return Token{Kind: TokenNil, Value: OtpErlangList{Value: value, Improper: false}}, nil
+	case tagCompressedZlib:
+		// only valid after the version tag
+		return Token{}, parseErrorNew(ErrInvalidTag, "invalid tag")
+	case tagLocalExt:
+		// the LOCAL_EXT data length is unknown, so it can not be streamed
+		return Token{}, parseErrorNew(ErrInvalidTag, "LOCAL_EXT not supported")
+	}
+	kind := tokenKind(tag)
+	if kind == 0 {
+		return Token{}, parseErrorNew(ErrInvalidTag, "invalid tag")
+	}
+	d.buffer.Reset()
+	err := d.buffer.WriteByte(tag)
+	if err != nil {
+		return Token{}, err
+	}
+	err = d.copyTerm(tag)
+	if err != nil {
+		return Token{}, err
+	}
+	var value interface{}
+	_, value, err = binaryToTerms(0, bytes.NewReader(d.buffer.Bytes()))
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Kind: kind, Value: value}, nil
+}
+
+func tokenKind(tag uint8) TokenKind {
+	switch tag {
+	case tagAtomCacheRef, tagAtomExt, tagAtomUtf8Ext,
+		tagSmallAtomExt, tagSmallAtomUtf8Ext:
+		return TokenAtom
+	case tagSmallIntegerExt, tagIntegerExt, tagSmallBigExt, tagLargeBigExt:
+		return TokenInteger
+	case tagNewFloatExt, tagFloatExt:
+		return TokenFloat
+	case tagStringExt:
+		return TokenString
+	case tagBinaryExt, tagBitBinaryExt:
+		return TokenBinary
+	case tagNewPidExt, tagPidExt:
+		return TokenPid
+	case tagV4PortExt, tagNewPortExt, tagPortExt:
+		return TokenPort
+	case tagNewerReferenceExt, tagNewReferenceExt, tagReferenceExt:
+		return TokenReference
+	case tagNewFunExt, tagExportExt, tagFunExt:
+		return TokenFunction
+	default:
+		return 0
+	}
+}
+
+// copyTerm copies the rest of the encoded term into the buffer
+// after the tag was already copied
+func (d *Decoder) copyTerm(tag uint8) error {
+	var err error
+	switch tag {
+	case tagNewFloatExt:
+		return d.copyBytes(8)
+	case tagBitBinaryExt:
+		var j uint32
+		j, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(1 + int(j))
+	case tagAtomCacheRef:
+		fallthrough
+	case tagSmallIntegerExt:
+		return d.copyBytes(1)
+	case tagIntegerExt:
+		return d.copyBytes(4)
+	case tagFloatExt:
+		return d.copyBytes(31)
+	case tagV4PortExt:
+		err = d.copyAtom()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(8 + 4)
+	case tagNewPortExt:
+		err = d.copyAtom()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(4 + 4)
+	case tagReferenceExt:
+		fallthrough
+	case tagPortExt:
+		err = d.copyAtom()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(4 + 1)
+	case tagNewPidExt:
+		err = d.copyAtom()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(4 + 4 + 4)
+	case tagPidExt:
+		err = d.copyAtom()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(4 + 4 + 1)
+	case tagSmallTupleExt:
+		var length []byte
+		length, err = d.input.read(1, &d.buffer)
+		if err != nil {
+			return err
+		}
+		return d.copyTerms(int(length[0]))
+	case tagLargeTupleExt:
+		var length uint32
+		length, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		return d.copyTerms(int(length))
+	case tagNilExt:
+		return nil
+	case tagStringExt:
+		var j []byte
+		j, err = d.input.read(2, &d.buffer)
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(int(binary.BigEndian.Uint16(j)))
+	case tagListExt:
+		var length uint32
+		length, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		return d.copyTerms(int(length) + 1)
+	case tagBinaryExt:
+		var j uint32
+		j, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(int(j))
+	case tagSmallBigExt:
+		var j []byte
+		j, err = d.input.read(1, &d.buffer)
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(1 + int(j[0]))
+	case tagLargeBigExt:
+		var j uint32
+		j, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(1 + int(j))
+	case tagNewFunExt:
+		var length uint32
+		length, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		if length < 4 {
+			return parseErrorNew(ErrInvalidData, "invalid function size")
+		}
+		return d.copyBytes(int(length) - 4)
+	case tagExportExt:
+		err = d.copyAtom() // module
+		if err != nil {
+			return err
+		}
+		err = d.copyAtom() // function
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(2) // arity
+	case tagNewerReferenceExt:
+		fallthrough
+	case tagNewReferenceExt:
+		var j []byte
+		j, err = d.input.read(2, &d.buffer)
+		if err != nil {
+			return err
+		}
+		err = d.copyAtom()
+		if err != nil {
+			return err
+		}
+		if tag == tagNewerReferenceExt {
+			return d.copyBytes(4 + int(binary.BigEndian.Uint16(j))*4)
+		}
+		return d.copyBytes(1 + int(binary.BigEndian.Uint16(j))*4)
+	case tagMapExt:
+		var length uint32
+		length, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		return d.copyTerms(int(length) * 2)
+	case tagFunExt:
+		var numfree uint32
+		numfree, err = d.copyUint32()
+		if err != nil {
+			return err
+		}
+		// pid, module, index, uniq and free
+		return d.copyTerms(4 + int(numfree))
+	case tagAtomUtf8Ext:
+		fallthrough
+	case tagAtomExt:
+		var j []byte
+		j, err = d.input.read(2, &d.buffer)
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(int(binary.BigEndian.Uint16(j)))
+	case tagSmallAtomUtf8Ext:
+		fallthrough
+	case tagSmallAtomExt:
+		var j []byte
+		j, err = d.input.read(1, &d.buffer)
+		if err != nil {
+			return err
+		}
+		return d.copyBytes(int(j[0]))
+	default:
+		return parseErrorNew(ErrInvalidTag, "invalid tag")
+	}
+}
+
+func (d *Decoder) copyTerms(length int) error {
+	for lengthIndex := 0; lengthIndex < length; lengthIndex++ {
+		tag, err := d.input.read(1, &d.buffer)
+		if err != nil {
+			return err
+		}
+		err = d.copyTerm(tag[0])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) copyAtom() error {
+	tag, err := d.input.read(1, &d.buffer)
+	if err != nil {
+		return err
+	}
+	switch tag[0] {
+	case tagAtomUtf8Ext:
+		fallthrough
+	case tagAtomExt:
+		fallthrough
+	case tagSmallAtomUtf8Ext:
+		fallthrough
+	case tagSmallAtomExt:
+		fallthrough
+	case tagAtomCacheRef:
+		return d.copyTerm(tag[0])
+	default:
+		return parseErrorNew(ErrInvalidTag, "invalid atom tag")
+	}
+}
+
+func (d *Decoder) copyBytes(length int) error {
+	_, err := d.input.read(length, &d.buffer)
+	return err
+}
+
+func (d *Decoder) copyUint32() (uint32, error) {
+	value, err := d.input.read(4, &d.buffer)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(value), nil
+}
+
+// error provides a ParseError with the location of the token
+func (d *Decoder) error(err error, i, tag int) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	err = parseErrorAt(err, i, tag)
+	for index := len(d.stack) - 1; index >= 0; index-- {
+		container := d.stack[index]
+		element := container.length - container.remaining - 1
+		switch container.kind {
+		case TokenTupleStart:
+			err = parseErrorPath(err, pathSegment("tuple", element))
+		case TokenListStart:
+			if container.tail {
+				element = container.length
+			}
+			err = parseErrorPath(err, pathSegment("list", element))
+		case TokenMapStart:
+			if element%2 == 0 {
+				err = parseErrorPath(err, pathSegment("mapkey", element/2))
+			} else {
+				err = parseErrorPath(err, "mapvalue["+strconv.Itoa(element/2)+"]")
+			}
+		}
+	}
+	return err
+}
+
+// (decoderInput functions)
+
+func (input *decoderInput) Read(p []byte) (int, error) {
+	n, err := input.reader.Read(p)
+	input.offset += n
+	return n, err
+}
+
+func (input *decoderInput) ReadByte() (byte, error) {
+	value, err := input.bytes.ReadByte()
+	if err == nil {
+		input.offset += 1
+	}
+	return value, err
+}
+
+func (input *decoderInput) peek() ([]byte, error) {
+	if reader, ok := input.reader.(*bufio.Reader); ok {
+		return reader.Peek(1)
+	}
+	value, err := input.bytes.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if scanner, ok := input.reader.(io.ByteScanner); ok {
+		err = scanner.UnreadByte()
+		return []byte{value}, err
+	}
+	return nil, inputErrorNew("reader without io.ByteScanner")
+}
+
+// read provides length bytes that are also appended to the buffer
+// (if a buffer is provided) without requiring all of the bytes in memory
+// before they are read
+func (input *decoderInput) read(length int, buffer *bytes.Buffer) ([]byte, error) {
+	if buffer == nil {
+		value := make([]byte, length)
+		_, err := io.ReadFull(input, value)
+		return value, err
+	}
+	start := buffer.Len()
+	n, err := buffer.ReadFrom(io.LimitReader(input, int64(length)))
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != length {
+		if n == 0 {
+			return nil, io.EOF
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	return buffer.Bytes()[start:], nil
+}
+
+func (input *decoderInput) uint32() (uint32, error) {
+	value, err := input.read(4, nil)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(value), nil
+}
@@ -0,0 +1,151 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func decodeTokens(t *testing.T, reader io.Reader) []Token {
+	decoder := NewDecoder(reader)
+	var tokens []Token
+	for {
+		token, err := decoder.Next()
+		if err == io.EOF {
+			return tokens
+		}
+		if err != nil {
+			t.Fatalf("Next: %s", err.Error())
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	// {route, [1, {a, b} | tail], #{k => <<"v">>}}
+	binary := "\x83h\x03w\x05routel\x00\x00\x00\x02a\x01h\x02w\x01aw\x01bw\x04tailt\x00\x00\x00\x01w\x01km\x00\x00\x00\x01v"
+	nil1 := OtpErlangList{Value: make([]interface{}, 0), Improper: false}
+	expected := []Token{
+		{Kind: TokenTupleStart, Length: 3},
+		{Kind: TokenAtom, Value: OtpErlangAtomUTF8("route")},
+		{Kind: TokenListStart, Length: 2},
+		{Kind: TokenInteger, Value: uint8(1)},
+		{Kind: TokenTupleStart, Length: 2},
+		{Kind: TokenAtom, Value: OtpErlangAtomUTF8("a")},
+		{Kind: TokenAtom, Value: OtpErlangAtomUTF8("b")},
+		{Kind: TokenListTail},
+		{Kind: TokenAtom, Value: OtpErlangAtomUTF8("tail")},
+		{Kind: TokenMapStart, Length: 1},
+		{Kind: TokenAtom, Value: OtpErlangAtomUTF8("k")},
+		{Kind: TokenBinary, Value: OtpErlangBinary{Value: []byte("v"), Bits: 8}},
+		// second term
+		{Kind: TokenListStart, Length: 0},
+		{Kind: TokenListTail},
+		{Kind: TokenNil, Value: nil1},
+		// third term
+		{Kind: TokenTupleStart, Length: 0},
+		// fourth term
+		{Kind: TokenPid, Value: decode(t, "\x83Xd\x00\rnonode@nohost\x00\x00\x00N\x00\x00\x00\x00\x00\x00\x00\x00")},
+		{Kind: TokenString, Value: strings.Repeat("d", 20)},
+	}
+	input := binary + "\x83l\x00\x00\x00\x00j" + "\x83h\x00" +
+		"\x83Xd\x00\rnonode@nohost\x00\x00\x00N\x00\x00\x00\x00\x00\x00\x00\x00" +
+		"\x83P\x00\x00\x00\x17\x78\xda\xcb\x66\x10\x49\xc1\x02\x00\x5d\x60\x08\x50"
+	assertEqual(t, expected, decodeTokens(t, strings.NewReader(input)), "")
+	// without io.ByteReader
+	assertEqual(t, expected, decodeTokens(t, io.MultiReader(strings.NewReader(input))), "")
+}
+func TestDecoderLargeList(t *testing.T) {
+	n := 10000
+	list := OtpErlangList{Value: make([]interface{}, n)}
+	for i := 0; i < n; i++ {
+		list.Value[i] = OtpErlangTuple{i, OtpErlangAtomUTF8("value")}
+	}
+	for _, compressed := range []int{-1, 6} {
+		encoded := encode(t, list, compressed)
+		reader, writer := io.Pipe()
+		go func() {
+			writer.Write([]byte(encoded))
+			writer.Write([]byte(encoded))
+			writer.Close()
+		}()
+		decoder := NewDecoder(reader)
+		count := 0
+		maxDepth := 0
+		for {
+			token, err := decoder.Next()
+			if err == io.EOF {
+				break
+			}
+			assertEqual(t, nil, err, "")
+			if token.Kind == TokenTupleStart {
+				count += 1
+			}
+			if decoder.Depth() > maxDepth {
+				maxDepth = decoder.Depth()
+			}
+		}
+		assertEqual(t, 2*n, count, "")
+		assertEqual(t, 2, maxDepth, "")
+	}
+}
+func TestDecoderError(t *testing.T) {
+	decoder := NewDecoder(bytes.NewReader([]byte("\x83h\x02w\x01at\x00\x00\x00\x01w\x01kl\x00\x00\x00\x02a\x01m\x00\x00")))
+	var err error
+	for err == nil {
+		_, err = decoder.Next()
+	}
+	assertEqual(t, true, errors.Is(err, ErrTruncated), "")
+	assertEqual(t, "unexpected EOF: offset 21, tag 109 (BINARY_EXT), path tuple[1].mapvalue[0].list[1]", err.Error(), "")
+	decoder = NewDecoder(bytes.NewReader([]byte("\x83h\x01z")))
+	_, err = decoder.Next()
+	assertEqual(t, nil, err, "")
+	_, err = decoder.Next()
+	assertEqual(t, true, errors.Is(err, ErrInvalidTag), "")
+	decoder = NewDecoder(bytes.NewReader([]byte("\x82j")))
+	_, err = decoder.Next()
+	assertEqual(t, true, errors.Is(err, ErrInvalidVersion), "")
+	// LOCAL_EXT is rejected instead of reading the remaining input
+	decoder = NewDecoder(bytes.NewReader([]byte("\x83h\x02yjj")))
+	_, err = decoder.Next()
+	assertEqual(t, nil, err, "")
+	_, err = decoder.Next()
+	assertEqual(t, true, errors.Is(err, ErrInvalidTag), "")
+	assertEqual(t, "LOCAL_EXT not supported: offset 3, tag 121 (LOCAL_EXT), path tuple[0]", err.Error(), "")
+	// compressed data with an uncompressed size that is too large
+	decoder = NewDecoder(bytes.NewReader([]byte("\x83P\x00\x00\x00\x18\x78\xda\xcb\x66\x10\x49\xc1\x02\x00\x5d\x60\x08\x50")))
+	_, err = decoder.Next()
+	assertEqual(t, true, errors.Is(err, ErrCompression), "")
+	// compressed data with an uncompressed size that is too small
+	decoder = NewDecoder(bytes.NewReader([]byte("\x83P\x00\x00\x00\x16\x78\xda\xcb\x66\x10\x49\xc1\x02\x00\x5d\x60\x08\x50")))
+	_, err = decoder.Next()
+	assertEqual(t, true, errors.Is(err, ErrTruncated), "")
+}
@@ -30,22 +30,28 @@ import (
 	"bytes"
 	"compress/zlib"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"math"
 	"math/big"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 var undefined = "undefined" // Change with SetUndefined
 
+const compressionRatioMax = 1032 // deflate maximum
+
 const (
 	// tag values here http://www.erlang.org/doc/apps/erts/erl_ext_dist.html
 	tagVersion           = 131
 	tagCompressedZlib    = 80
 	tagNewFloatExt       = 70
 	tagBitBinaryExt      = 77
-	tagAtomCacheRef      = 78
+	tagAtomCacheRef      = 82
 	tagNewPidExt         = 88
 	tagNewPortExt        = 89
 	tagNewerReferenceExt = 90
@@ -137,6 +143,12 @@ type OtpErlangFunction struct {
 // OtpErlangTuple represents SMALL_TUPLE_EXT or LARGE_TUPLE_EXT
 type OtpErlangTuple []interface{}
 
+// RawTerm represents an encoded term (without the version tag) that is
+// kept as bytes during decoding and written verbatim during encoding,
+// similar to json.RawMessage (LOCAL_EXT is always decoded as a RawTerm
+// and is only valid as the whole term)
+type RawTerm []byte
+
 // Error structs listed alphabetically
 
 // InputError describes problems with function input parameters
@@ -164,46 +176,192 @@ func (e *OutputError) Error() string {
 }
 
 // ParseError provides specific parsing failure information
+// (errors.Is matches the Err sentinel value)
 type ParseError struct {
+	Err    error  // sentinel value (e.g., ErrTruncated)
+	Offset int    // byte offset of the term that failed (-1 if unknown)
+	Tag    int    // tag of the term that failed (-1 if unknown)
+	Path   string // path to the term that failed (e.g., tuple[2].list[14])
+	// (the Offset refers to the uncompressed data if the data is compressed)
 	message string
+	cause   error
 }
 
-func parseErrorNew(message string) error {
-	return &ParseError{message}
+// ParseError sentinel values listed alphabetically
+var (
+	ErrCompression    = errors.New("compression invalid")
+	ErrInvalidData    = errors.New("data invalid")
+	ErrInvalidTag     = errors.New("tag invalid")
+	ErrInvalidVersion = errors.New("version invalid")
+	ErrLimit          = errors.New("limit exceeded")
+	ErrNullInput      = errors.New("null input")
+	ErrPathNotFound   = errors.New("path not found")
+	ErrTruncated      = errors.New("data truncated")
+	ErrUnparsedData   = errors.New("unparsed data")
+)
+
+func parseErrorNew(err error, message string) error {
+	return &ParseError{Err: err, Offset: -1, Tag: -1, message: message}
 }
+
+// parseErrorAt provides a ParseError with the location of the term
+// at offset i (if a location was not yet provided)
+func parseErrorAt(err error, i, tag int) error {
+	switch e := err.(type) {
+	case *ParseError:
+		if e.Offset < 0 {
+			e.Offset = i
+			e.Tag = tag
+		}
+		return e
+	default:
+		sentinel := ErrInvalidData
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			sentinel = ErrTruncated
+		}
+		return &ParseError{Err: sentinel, Offset: i, Tag: tag,
+			message: err.Error(), cause: err}
+	}
+}
+
+// parseErrorPath adds a path segment for the term that contains
+// the term that failed
+func parseErrorPath(err error, segment string) error {
+	if e, ok := err.(*ParseError); ok {
+		if len(e.Path) == 0 {
+			e.Path = segment
+		} else {
+			e.Path = segment + "." + e.Path
+		}
+	}
+	return err
+}
+
 func (e *ParseError) Error() string {
-	return e.message
+	if e.Offset < 0 {
+		return e.message
+	}
+	message := e.message + ": offset " + strconv.Itoa(e.Offset)
+	if e.Tag >= 0 {
+		message += ", tag " + strconv.Itoa(e.Tag)
+		if name := tagName(e.Tag); len(name) > 0 {
+			message += " (" + name + ")"
+		}
+	}
+	if len(e.Path) > 0 {
+		message += ", path " + e.Path
+	}
+	return message
+}
+
+// Unwrap provides the sentinel value
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the io package error that caused the ParseError
+// (e.g., io.EOF or io.ErrUnexpectedEOF)
+func (e *ParseError) Is(target error) bool {
+	return e.cause != nil && e.cause == target
 }
 
 // core functionality
 
 // BinaryToTerm decodes the Erlang External Term Format into Go types
 func BinaryToTerm(data []byte) (interface{}, error) {
+	term, i, err := BinaryToTermPrefix(data)
+	if err != nil {
+		return nil, err
+	}
+	if i != len(data) {
+		return nil, parseErrorAt(parseErrorNew(ErrUnparsedData, "unparsed data"), i, int(data[i]))
+	}
+	return term, nil
+}
+
+// BinaryToTermPrefix decodes the first term in the data into Go types,
+// providing the size of the term's data (with the version tag) so
+// the data that follows can be used separately
+func BinaryToTermPrefix(data []byte) (interface{}, int, error) {
 	size := len(data)
 	if size <= 1 {
-		return nil, parseErrorNew("null input")
+		return nil, 0, parseErrorNew(ErrNullInput, "null input")
 	}
 	reader := bytes.NewReader(data)
 	version, err := reader.ReadByte()
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if version != tagVersion {
-		return nil, parseErrorNew("invalid version")
+		return nil, 0, parseErrorAt(parseErrorNew(ErrInvalidVersion, "invalid version"), 0, -1)
 	}
 	var i int
 	var term interface{}
 	i, term, err = binaryToTerms(1, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	return term, i, nil
+}
+
+// BinaryToTermRaw decodes the Erlang External Term Format into Go types
+// while keeping the subterm at each path as a RawTerm.
+// A path element is a 0-based index (int) for a tuple or a list
+// (an improper list tail is the last index) or a key for a map.
+// An empty path keeps the whole term as a RawTerm.
+func BinaryToTermRaw(data []byte, paths ...[]interface{}) (interface{}, error) {
+	size := len(data)
+	if size <= 1 {
+		return nil, parseErrorNew(ErrNullInput, "null input")
+	}
+	reader := bytes.NewReader(data)
+	version, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != tagVersion {
+		return nil, parseErrorAt(parseErrorNew(ErrInvalidVersion, "invalid version"), 0, -1)
+	}
+	if data[1] == tagCompressedZlib {
+		// raw terms can not refer to compressed data
+		_, err = reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		var iEnd int
+		var dataUncompressed []byte
+		iEnd, dataUncompressed, err = binaryToUncompressed(2, reader)
+		if err != nil {
+			return nil, parseErrorAt(err, 1, tagCompressedZlib)
+		}
+		if iEnd != len(data) {
+			return nil, parseErrorAt(parseErrorNew(ErrUnparsedData, "unparsed data"), iEnd, int(data[iEnd]))
+		}
+		var iNew int
+		var term interface{}
+		iNew, term, err = binaryToTermsRaw(0, bytes.NewReader(dataUncompressed), paths)
+		if err != nil {
+			return nil, err
+		}
+		if iNew != len(dataUncompressed) {
+			return nil, parseErrorAt(parseErrorNew(ErrUnparsedData, "unparsed data"), iNew, int(dataUncompressed[iNew]))
+		}
+		return term, nil
+	}
+	var i int
+	var term interface{}
+	i, term, err = binaryToTermsRaw(1, reader, paths)
 	if err != nil {
 		return nil, err
 	}
 	if i != size {
-		return nil, parseErrorNew("unparsed data")
+		return nil, parseErrorAt(parseErrorNew(ErrUnparsedData, "unparsed data"), i, int(data[i]))
 	}
 	return term, nil
 }
 
 // TermToBinary encodes Go types into the Erlang External Term Format
+// (a Marshaler is encoded as the term it provides)
 func TermToBinary(term interface{}, compressed int) ([]byte, error) {
 	if compressed < -1 || compressed > 9 {
 		return nil, inputErrorNew("compressed in [-1..9]")
@@ -247,14 +405,39 @@ func TermToBinary(term interface{}, compressed int) ([]byte, error) {
 	return result.Bytes(), nil
 }
 
+// TermString provides an Erlang-like text representation of the term
+func TermString(term interface{}) string {
+	return termString(term)
+}
+
+// NodeName provides the node name from the NodeTag and Node data of
+// a pid, port or reference
+func NodeName(nodeTag uint8, node []byte) string {
+	return string(nodeName(nodeTag, node))
+}
+
 // SetUndefined assigns the undefined atom name, Elixir use can set to "nil"
 func SetUndefined(value string) {
 	undefined = value
 }
 
+// Undefined provides the undefined atom name assigned with SetUndefined
+func Undefined() string {
+	return undefined
+}
+
 // BinaryToTerm implementation functions
 
 func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
+	iTag := i
+	i, term, err := binaryToTermsTag(i, reader)
+	if err != nil {
+		return i, nil, parseErrorAt(err, iTag, readerTag(reader, iTag))
+	}
+	return i, term, nil
+}
+
+func binaryToTermsTag(i int, reader *bytes.Reader) (int, interface{}, error) {
 	tag, err := reader.ReadByte()
 	if err != nil {
 		return i, nil, err
@@ -280,7 +463,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		i += 1
 		value := make([]byte, j)
 		if j > 0 {
-			_, err = reader.Read(value)
+			_, err = io.ReadFull(reader, value)
 			if err != nil {
 				return i, nil, err
 			}
@@ -309,7 +492,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		return i + 4, value, nil
 	case tagFloatExt:
 		valueRaw := make([]byte, 31)
-		_, err = reader.Read(valueRaw)
+		_, err = io.ReadFull(reader, valueRaw)
 		if err != nil {
 			return i, nil, err
 		}
@@ -336,14 +519,14 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		switch tag {
 		case tagV4PortExt:
 			id = make([]byte, 8)
-			_, err = reader.Read(id)
+			_, err = io.ReadFull(reader, id)
 			if err != nil {
 				return i, nil, err
 			}
 			i += 8
 		default:
 			id = make([]byte, 4)
-			_, err = reader.Read(id)
+			_, err = io.ReadFull(reader, id)
 			if err != nil {
 				return i, nil, err
 			}
@@ -355,14 +538,14 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 			fallthrough
 		case tagNewPortExt:
 			creation = make([]byte, 4)
-			_, err = reader.Read(creation)
+			_, err = io.ReadFull(reader, creation)
 			if err != nil {
 				return i, nil, err
 			}
 			i += 4
 		default:
 			creation = make([]byte, 1)
-			_, err = reader.Read(creation)
+			_, err = io.ReadFull(reader, creation)
 			if err != nil {
 				return i, nil, err
 			}
@@ -383,13 +566,13 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 			return i, nil, err
 		}
 		id := make([]byte, 4)
-		_, err = reader.Read(id)
+		_, err = io.ReadFull(reader, id)
 		if err != nil {
 			return i, nil, err
 		}
 		i += 4
 		serial := make([]byte, 4)
-		_, err = reader.Read(serial)
+		_, err = io.ReadFull(reader, serial)
 		if err != nil {
 			return i, nil, err
 		}
@@ -398,14 +581,14 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		switch tag {
 		case tagNewPidExt:
 			creation = make([]byte, 4)
-			_, err = reader.Read(creation)
+			_, err = io.ReadFull(reader, creation)
 			if err != nil {
 				return i, nil, err
 			}
 			i += 4
 		case tagPidExt:
 			creation = make([]byte, 1)
-			_, err = reader.Read(creation)
+			_, err = io.ReadFull(reader, creation)
 			if err != nil {
 				return i, nil, err
 			}
@@ -434,13 +617,17 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 			i += 4
 			length = int(lengthValue)
 		default:
-			return i, nil, parseErrorNew("invalid tag case")
+			return i, nil, parseErrorNew(ErrInvalidTag, "invalid tag case")
 		}
 		var tmp []interface{}
-		i, tmp, err = binaryToTermSequence(i, length, reader)
+		i, tmp, err = binaryToTermSequence(i, length, "tuple", reader)
 		if err != nil {
 			return i, nil, err
 		}
+		value, registered := registeredTuple(tmp)
+		if registered {
+			return i, value, nil
+		}
 		return i, OtpErlangTuple(tmp), nil
 	case tagNilExt:
 		value := make([]interface{}, 0)
@@ -454,7 +641,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		i += 2
 		value := make([]byte, j)
 		if j > 0 {
-			_, err = reader.Read(value)
+			_, err = io.ReadFull(reader, value)
 			if err != nil {
 				return i, nil, err
 			}
@@ -468,14 +655,14 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		}
 		i += 4
 		var tmp []interface{}
-		i, tmp, err = binaryToTermSequence(i, int(length), reader)
+		i, tmp, err = binaryToTermSequence(i, int(length), "list", reader)
 		if err != nil {
 			return i, nil, err
 		}
 		var tail interface{}
 		i, tail, err = binaryToTerms(i, reader)
 		if err != nil {
-			return i, nil, err
+			return i, nil, parseErrorPath(err, pathSegment("list", int(length)))
 		}
 		var improper bool
 		switch tail.(type) {
@@ -497,7 +684,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		i += 4
 		value := make([]byte, j)
 		if j > 0 {
-			_, err = reader.Read(value)
+			_, err = io.ReadFull(reader, value)
 			if err != nil {
 				return i, nil, err
 			}
@@ -525,7 +712,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 			i += 4
 			j = int(jValue)
 		default:
-			return i, nil, parseErrorNew("invalid tag case")
+			return i, nil, parseErrorNew(ErrInvalidTag, "invalid tag case")
 		}
 		var sign uint8
 		sign, err = reader.ReadByte()
@@ -552,18 +739,23 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		}
 		return i + j, bignum, nil
 	case tagNewFunExt:
+		// the size includes the size field
 		var length uint32
 		err = binary.Read(reader, binary.BigEndian, &length)
 		if err != nil {
 			return i, nil, err
 		}
-		i += 4
+		if length < 4 {
+			return i, nil, parseErrorNew(ErrInvalidData, "invalid function size")
+		}
 		value := make([]byte, length)
-		if length > 0 {
-			_, err = reader.Read(value)
-			if err != nil {
-				return i, nil, err
-			}
+		_, err = reader.ReadAt(value, int64(i))
+		if err != nil {
+			return i, nil, err
+		}
+		_, err = reader.Seek(int64(length-4), 1)
+		if err != nil {
+			return i, nil, err
 		}
 		return i + int(length), OtpErlangFunction{Tag: tag, Value: value}, nil
 	case tagExportExt:
@@ -582,7 +774,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 			return i, nil, err
 		}
 		if arityTag != tagSmallIntegerExt {
-			return i, nil, parseErrorNew("invalid small integer tag")
+			return i, nil, parseErrorNew(ErrInvalidTag, "invalid small integer tag")
 		}
 		i += 1
 		_, err = reader.ReadByte() // arity
@@ -616,14 +808,14 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		switch tag {
 		case tagNewerReferenceExt:
 			creation = make([]byte, 4)
-			_, err = reader.Read(creation)
+			_, err = io.ReadFull(reader, creation)
 			if err != nil {
 				return i, nil, err
 			}
 			i += 4
 		case tagNewReferenceExt:
 			creation = make([]byte, 1)
-			_, err = reader.Read(creation)
+			_, err = io.ReadFull(reader, creation)
 			if err != nil {
 				return i, nil, err
 			}
@@ -631,7 +823,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		}
 		id := make([]byte, j)
 		if j > 0 {
-			_, err = reader.Read(id)
+			_, err = io.ReadFull(reader, id)
 			if err != nil {
 				return i, nil, err
 			}
@@ -646,20 +838,21 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		i += 4
 		pairs := make(map[interface{}]interface{})
 		for lengthIndex := 0; lengthIndex < int(length); lengthIndex++ {
+			iKey := i
 			var key interface{}
 			i, key, err = binaryToTerms(i, reader)
 			if err != nil {
-				return i, nil, err
+				return i, nil, parseErrorPath(err, pathSegment("mapkey", lengthIndex))
 			}
-			if !reflect.TypeOf(key).Comparable() {
+			if !termComparable(key) {
 				// no way to solve this properly in Go while preserving
 				// the Erlang type information
-				return i, nil, parseErrorNew("map key not comparable")
+				return i, nil, parseErrorPath(parseErrorAt(parseErrorNew(ErrInvalidData, "map key not comparable"), iKey, readerTag(reader, iKey)), pathSegment("mapkey", lengthIndex))
 			}
 			var value interface{}
 			i, value, err = binaryToTerms(i, reader)
 			if err != nil {
-				return i, nil, err
+				return i, nil, parseErrorPath(err, pathSegment("map", key))
 			}
 			pairs[key] = value
 		}
@@ -688,7 +881,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		if err != nil {
 			return i, nil, err
 		}
-		i, _, err = binaryToTermSequence(i, int(numfree), reader) // free
+		i, _, err = binaryToTermSequence(i, int(numfree), "fun", reader) // free
 		if err != nil {
 			return i, nil, err
 		}
@@ -709,7 +902,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		i += 2
 		value := make([]byte, j)
 		if j > 0 {
-			_, err = reader.Read(value)
+			_, err = io.ReadFull(reader, value)
 			if err != nil {
 				return i, nil, err
 			}
@@ -729,7 +922,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		case tagAtomExt:
 			return i + int(j), OtpErlangAtom(value), nil
 		default:
-			return i, nil, parseErrorNew("invalid tag case")
+			return i, nil, parseErrorNew(ErrInvalidTag, "invalid tag case")
 		}
 	case tagSmallAtomUtf8Ext:
 		fallthrough
@@ -742,7 +935,7 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		i += 1
 		value := make([]byte, j)
 		if j > 0 {
-			_, err = reader.Read(value)
+			_, err = io.ReadFull(reader, value)
 			if err != nil {
 				return i, nil, err
 			}
@@ -762,65 +955,463 @@ func binaryToTerms(i int, reader *bytes.Reader) (int, interface{}, error) {
 		case tagSmallAtomExt:
 			return i + int(j), OtpErlangAtom(value), nil
 		default:
-			return i, nil, parseErrorNew("invalid tag case")
+			return i, nil, parseErrorNew(ErrInvalidTag, "invalid tag case")
 		}
 	case tagCompressedZlib:
-		var sizeUncompressed uint32
-		err = binary.Read(reader, binary.BigEndian, &sizeUncompressed)
+		var dataUncompressed []byte
+		i, dataUncompressed, err = binaryToUncompressed(i, reader)
 		if err != nil {
 			return i, nil, err
 		}
-		i += 4
-		if sizeUncompressed == 0 {
-			return i, nil, parseErrorNew("compressed data null")
-		}
-		j := reader.Len()
-		var compress io.ReadCloser
-		compress, err = zlib.NewReader(reader)
+		var iNew int
+		var term interface{}
+		iNew, term, err = binaryToTerms(0, bytes.NewReader(dataUncompressed))
 		if err != nil {
 			return i, nil, err
 		}
-		dataUncompressed := make([]byte, sizeUncompressed)
-		var sizeUncompressedStored int
-		sizeUncompressedStored, _ = io.ReadFull(compress, dataUncompressed)
-		err = compress.Close()
-		if err != nil {
-			return i, nil, err
+		if iNew != len(dataUncompressed) {
+			return i, nil, parseErrorAt(parseErrorNew(ErrUnparsedData, "unparsed data"), iNew, int(dataUncompressed[iNew]))
 		}
-		if int(sizeUncompressed) != sizeUncompressedStored {
-			return i, nil, parseErrorNew("compression corrupt")
+		return i, term, nil
+	case tagLocalExt:
+		// only the node that created the LOCAL_EXT data is able to decode it
+		// and its length is unknown, so it is only accepted as the whole term
+		// (a nested term never has its tag at offset 0 or 1)
+		if i > 2 {
+			return i, nil, parseErrorNew(ErrInvalidData, "LOCAL_EXT nested")
 		}
-		var iNew int
-		var term interface{}
-		iNew, term, err = binaryToTerms(0, bytes.NewReader(dataUncompressed))
+		value := make([]byte, 1+reader.Len())
+		_, err = reader.ReadAt(value, int64(i-1))
 		if err != nil {
 			return i, nil, err
 		}
-		if iNew != int(sizeUncompressed) {
-			return i, nil, parseErrorNew("unparsed data")
+		_, err = reader.Seek(0, 2)
+		if err != nil {
+			return i, nil, err
 		}
-		return i + j, term, nil
-	case tagLocalExt:
-		return i, nil, parseErrorNew("LOCAL_EXT is opaque")
+		return i - 1 + len(value), RawTerm(value), nil
 	default:
-		return i, nil, parseErrorNew("invalid tag")
+		return i, nil, parseErrorNew(ErrInvalidTag, "invalid tag")
 	}
 }
 
-func binaryToTermSequence(i, length int, reader *bytes.Reader) (int, []interface{}, error) {
+func binaryToUncompressed(i int, reader *bytes.Reader) (int, []byte, error) {
+	var sizeUncompressed uint32
+	err := binary.Read(reader, binary.BigEndian, &sizeUncompressed)
+	if err != nil {
+		return i, nil, err
+	}
+	i += 4
+	if sizeUncompressed == 0 {
+		return i, nil, parseErrorNew(ErrCompression, "compressed data null")
+	}
+	j := reader.Len()
+	var compress io.ReadCloser
+	compress, err = zlib.NewReader(reader)
+	if err != nil {
+		return i, nil, parseErrorNew(ErrCompression, err.Error())
+	}
+	// the stored size is only trusted up to the deflate compression ratio
+	// so the allocation grows with the data actually decompressed
+	capacity := int64(sizeUncompressed)
+	if capacity > int64(j)*compressionRatioMax {
+		capacity = int64(j) * compressionRatioMax
+	}
+	var dataUncompressed bytes.Buffer
+	dataUncompressed.Grow(int(capacity))
+	_, err = dataUncompressed.ReadFrom(
+		io.LimitReader(compress, int64(sizeUncompressed)+1))
+	if err != nil {
+		return i, nil, parseErrorNew(ErrCompression, err.Error())
+	}
+	err = compress.Close()
+	if err != nil {
+		return i, nil, parseErrorNew(ErrCompression, err.Error())
+	}
+	if int(sizeUncompressed) != dataUncompressed.Len() {
+		return i, nil, parseErrorNew(ErrCompression, "compression corrupt")
+	}
+	// the zlib reader reads the bytes.Reader without buffering, so
+	// any data after the compressed term remains unread
+	return i + j - reader.Len(), dataUncompressed.Bytes(), nil
+}
+
+func binaryToTermSequence(i, length int, kind string, reader *bytes.Reader) (int, []interface{}, error) {
 	sequence := make([]interface{}, length)
 	var err error
 	for lengthIndex := 0; lengthIndex < length; lengthIndex++ {
 		var element interface{}
 		i, element, err = binaryToTerms(i, reader)
 		if err != nil {
-			return i, nil, err
+			return i, nil, parseErrorPath(err, pathSegment(kind, lengthIndex))
 		}
 		sequence[lengthIndex] = element
 	}
 	return i, sequence, nil
 }
 
+func binaryToTermsRaw(i int, reader *bytes.Reader, paths [][]interface{}) (int, interface{}, error) {
+	if len(paths) == 0 {
+		return binaryToTerms(i, reader)
+	}
+	for _, path := range paths {
+		if len(path) == 0 {
+			return binaryToRawTerm(i, reader)
+		}
+	}
+	iTag := i
+	i, term, err := binaryToTermsRawTag(i, reader, paths)
+	if err != nil {
+		return i, nil, parseErrorAt(err, iTag, readerTag(reader, iTag))
+	}
+	return i, term, nil
+}
+
+func binaryToTermsRawTag(i int, reader *bytes.Reader, paths [][]interface{}) (int, interface{}, error) {
+	tag, err := reader.ReadByte()
+	if err != nil {
+		return i, nil, err
+	}
+	switch tag {
+	case tagSmallTupleExt:
+		fallthrough
+	case tagLargeTupleExt:
+		i += 1
+		var length int
+		switch tag {
+		case tagSmallTupleExt:
+			var lengthValue uint8
+			lengthValue, err = reader.ReadByte()
+			if err != nil {
+				return i, nil, err
+			}
+			i += 1
+			length = int(lengthValue)
+		case tagLargeTupleExt:
+			var lengthValue uint32
+			err = binary.Read(reader, binary.BigEndian, &lengthValue)
+			if err != nil {
+				return i, nil, err
+			}
+			i += 4
+			length = int(lengthValue)
+		default:
+			return i, nil, parseErrorNew(ErrInvalidTag, "invalid tag case")
+		}
+		tmp := make([]interface{}, length)
+		for lengthIndex := 0; lengthIndex < length; lengthIndex++ {
+			i, tmp[lengthIndex], err = binaryToTermsRaw(i, reader, pathsIndex(paths, lengthIndex))
+			if err != nil {
+				return i, nil, parseErrorPath(err, pathSegment("tuple", lengthIndex))
+			}
+		}
+		return i, OtpErlangTuple(tmp), nil
+	case tagListExt:
+		i += 1
+		var length uint32
+		err = binary.Read(reader, binary.BigEndian, &length)
+		if err != nil {
+			return i, nil, err
+		}
+		i += 4
+		tmp := make([]interface{}, length)
+		for lengthIndex := 0; lengthIndex < int(length); lengthIndex++ {
+			i, tmp[lengthIndex], err = binaryToTermsRaw(i, reader, pathsIndex(paths, lengthIndex))
+			if err != nil {
+				return i, nil, parseErrorPath(err, pathSegment("list", lengthIndex))
+			}
+		}
+		var tail interface{}
+		i, tail, err = binaryToTermsRaw(i, reader, pathsIndex(paths, int(length)))
+		if err != nil {
+			return i, nil, parseErrorPath(err, pathSegment("list", int(length)))
+		}
+		var improper bool
+		switch tail.(type) {
+		case OtpErlangList:
+			improper = (len(tail.(OtpErlangList).Value) != 0)
+		default:
+			improper = true
+		}
+		if improper {
+			tmp = append(tmp, tail)
+		}
+		return i, OtpErlangList{Value: tmp, Improper: improper}, nil
+	case tagMapExt:
+		i += 1
+		var length uint32
+		err = binary.Read(reader, binary.BigEndian, &length)
+		if err != nil {
+			return i, nil, err
+		}
+		i += 4
+		pairs := make(map[interface{}]interface{})
+		for lengthIndex := 0; lengthIndex < int(length); lengthIndex++ {
+			iKey := i
+			var key interface{}
+			i, key, err = binaryToTerms(i, reader)
+			if err != nil {
+				return i, nil, parseErrorPath(err, pathSegment("mapkey", lengthIndex))
+			}
+			if !termComparable(key) {
+				return i, nil, parseErrorPath(parseErrorAt(parseErrorNew(ErrInvalidData, "map key not comparable"), iKey, readerTag(reader, iKey)), pathSegment("mapkey", lengthIndex))
+			}
+			var value interface{}
+			i, value, err = binaryToTermsRaw(i, reader, pathsKey(paths, key))
+			if err != nil {
+				return i, nil, parseErrorPath(err, pathSegment("map", key))
+			}
+			pairs[key] = value
+		}
+		return i, OtpErlangMap(pairs), nil
+	default:
+		// the paths do not continue within this term
+		err = reader.UnreadByte()
+		if err != nil {
+			return i, nil, err
+		}
+		return binaryToTerms(i, reader)
+	}
+}
+
+func binaryToRawTerm(i int, reader *bytes.Reader) (int, interface{}, error) {
+	iOld := i
+	i, _, err := binaryToTerms(i, reader)
+	if err != nil {
+		return i, nil, err
+	}
+	value := make([]byte, i-iOld)
+	_, err = reader.ReadAt(value, int64(iOld))
+	if err != nil {
+		return i, nil, err
+	}
+	return i, RawTerm(value), nil
+}
+
+func pathsIndex(paths [][]interface{}, index int) [][]interface{} {
+	var pathsNext [][]interface{}
+	for _, path := range paths {
+		if len(path) > 0 {
+			if pathIndex, ok := path[0].(int); ok && pathIndex == index {
+				pathsNext = append(pathsNext, path[1:])
+			}
+		}
+	}
+	return pathsNext
+}
+
+func pathsKey(paths [][]interface{}, key interface{}) [][]interface{} {
+	var pathsNext [][]interface{}
+	for _, path := range paths {
+		if len(path) > 0 && termEqual(path[0], key) {
+			pathsNext = append(pathsNext, path[1:])
+		}
+	}
+	return pathsNext
+}
+
+// (BinaryToTerm error location functions)
+
+func readerTag(reader *bytes.Reader, i int) int {
+	tag := make([]byte, 1)
+	_, err := reader.ReadAt(tag, int64(i))
+	if err != nil {
+		return -1
+	}
+	return int(tag[0])
+}
+
+func pathSegment(kind string, key interface{}) string {
+	if index, ok := key.(int); ok && kind != "map" {
+		return kind + "[" + strconv.Itoa(index) + "]"
+	}
+	return kind + "[" + termString(key) + "]"
+}
+
+// termString provides Erlang syntax for a term that is similar to
+// what the Erlang shell prints
+func termString(termI interface{}) string {
+	switch term := termI.(type) {
+	case bool:
+		if term {
+			return "true"
+		}
+		return "false"
+	case nil:
+		return undefined
+	case OtpErlangAtom:
+		return string(term)
+	case OtpErlangAtomUTF8:
+		return string(term)
+	case OtpErlangAtomCacheRef:
+		return "atom_cache_ref(" + strconv.Itoa(int(term)) + ")"
+	case *big.Int:
+		return term.String()
+	case float32:
+		return strconv.FormatFloat(float64(term), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(term, 'g', -1, 64)
+	case string:
+		return strconv.Quote(term)
+	case []byte:
+		return termString(OtpErlangBinary{Value: term, Bits: 8})
+	case OtpErlangBinary:
+		if term.Bits == 8 && isPrintable(term.Value) {
+			return "<<" + strconv.Quote(string(term.Value)) + ">>"
+		}
+		elements := make([]string, len(term.Value))
+		for index, value := range term.Value {
+			elements[index] = strconv.Itoa(int(value))
+		}
+		if term.Bits != 8 && len(elements) > 0 {
+			elements[len(elements)-1] += ":" + strconv.Itoa(int(term.Bits))
+		}
+		return "<<" + strings.Join(elements, ",") + ">>"
+	case OtpErlangTuple:
+		return "{" + termStrings(term) + "}"
+	case []interface{}:
+		return "{" + termStrings(term) + "}"
+	case OtpErlangList:
+		if term.Improper && len(term.Value) > 1 {
+			last := len(term.Value) - 1
+			return "[" + termStrings(term.Value[:last]) + "|" + termString(term.Value[last]) + "]"
+		}
+		return "[" + termStrings(term.Value) + "]"
+	case OtpErlangMap:
+		return termString(map[interface{}]interface{}(term))
+	case map[interface{}]interface{}:
+		elements := make([]string, 0, len(term))
+		for key, value := range term {
+			elements = append(elements, termString(key)+" => "+termString(value))
+		}
+		sort.Strings(elements)
+		return "#{" + strings.Join(elements, ",") + "}"
+	case OtpErlangPid:
+		return "#Pid<" + string(nodeName(term.NodeTag, term.Node)) + ">"
+	case OtpErlangPort:
+		return "#Port<" + string(nodeName(term.NodeTag, term.Node)) + ">"
+	case OtpErlangReference:
+		return "#Ref<" + string(nodeName(term.NodeTag, term.Node)) + ">"
+	case OtpErlangFunction:
+		return "#Fun<>"
+	case RawTerm:
+		return "#Raw<" + strconv.Itoa(len(term)) + ">"
+	default:
+		if tuple, registered, err := registeredTerm(termI); registered && err == nil {
+			return termString(tuple)
+		}
+		return fmt.Sprint(term)
+	}
+}
+
+func termStrings(terms []interface{}) string {
+	elements := make([]string, len(terms))
+	for index, term := range terms {
+		elements[index] = termString(term)
+	}
+	return strings.Join(elements, ",")
+}
+
+func isPrintable(value []byte) bool {
+	for _, c := range value {
+		if c < ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeName provides the atom name from the Node data of a
+// pid, port or reference
+func nodeName(nodeTag uint8, node []byte) []byte {
+	switch nodeTag {
+	case tagAtomExt:
+		fallthrough
+	case tagAtomUtf8Ext:
+		if len(node) >= 2 {
+			return node[2:]
+		}
+	case tagSmallAtomExt:
+		fallthrough
+	case tagSmallAtomUtf8Ext:
+		if len(node) >= 1 {
+			return node[1:]
+		}
+	}
+	return nil
+}
+
+func tagName(tag int) string {
+	switch tag {
+	case tagCompressedZlib:
+		return "COMPRESSED"
+	case tagNewFloatExt:
+		return "NEW_FLOAT_EXT"
+	case tagBitBinaryExt:
+		return "BIT_BINARY_EXT"
+	case tagAtomCacheRef:
+		return "ATOM_CACHE_REF"
+	case tagNewPidExt:
+		return "NEW_PID_EXT"
+	case tagNewPortExt:
+		return "NEW_PORT_EXT"
+	case tagNewerReferenceExt:
+		return "NEWER_REFERENCE_EXT"
+	case tagSmallIntegerExt:
+		return "SMALL_INTEGER_EXT"
+	case tagIntegerExt:
+		return "INTEGER_EXT"
+	case tagFloatExt:
+		return "FLOAT_EXT"
+	case tagAtomExt:
+		return "ATOM_EXT"
+	case tagReferenceExt:
+		return "REFERENCE_EXT"
+	case tagPortExt:
+		return "PORT_EXT"
+	case tagPidExt:
+		return "PID_EXT"
+	case tagSmallTupleExt:
+		return "SMALL_TUPLE_EXT"
+	case tagLargeTupleExt:
+		return "LARGE_TUPLE_EXT"
+	case tagNilExt:
+		return "NIL_EXT"
+	case tagStringExt:
+		return "STRING_EXT"
+	case tagListExt:
+		return "LIST_EXT"
+	case tagBinaryExt:
+		return "BINARY_EXT"
+	case tagSmallBigExt:
+		return "SMALL_BIG_EXT"
+	case tagLargeBigExt:
+		return "LARGE_BIG_EXT"
+	case tagNewFunExt:
+		return "NEW_FUN_EXT"
+	case tagExportExt:
+		return "EXPORT_EXT"
+	case tagNewReferenceExt:
+		return "NEW_REFERENCE_EXT"
+	case tagSmallAtomExt:
+		return "SMALL_ATOM_EXT"
+	case tagMapExt:
+		return "MAP_EXT"
+	case tagFunExt:
+		return "FUN_EXT"
+	case tagAtomUtf8Ext:
+		return "ATOM_UTF8_EXT"
+	case tagSmallAtomUtf8Ext:
+		return "SMALL_ATOM_UTF8_EXT"
+	case tagV4PortExt:
+		return "V4_PORT_EXT"
+	case tagLocalExt:
+		return "LOCAL_EXT"
+	default:
+		return ""
+	}
+}
+
 // (BinaryToTerm Erlang term primitive type functions)
 
 func binaryToInteger(i int, reader *bytes.Reader) (int, interface{}, error) {
@@ -845,7 +1436,7 @@ func binaryToInteger(i int, reader *bytes.Reader) (int, interface{}, error) {
 		}
 		return i + 4, value, nil
 	default:
-		return i, nil, parseErrorNew("invalid integer tag")
+		return i, nil, parseErrorNew(ErrInvalidTag, "invalid integer tag")
 	}
 }
 
@@ -864,19 +1455,19 @@ func binaryToPid(i int, reader *bytes.Reader) (int, interface{}, error) {
 			return i, nil, err
 		}
 		id := make([]byte, 4)
-		_, err = reader.Read(id)
+		_, err = io.ReadFull(reader, id)
 		if err != nil {
 			return i, nil, err
 		}
 		i += 4
 		serial := make([]byte, 4)
-		_, err = reader.Read(serial)
+		_, err = io.ReadFull(reader, serial)
 		if err != nil {
 			return i, nil, err
 		}
 		i += 4
 		creation := make([]byte, 4)
-		_, err = reader.Read(creation)
+		_, err = io.ReadFull(reader, creation)
 		if err != nil {
 			return i, nil, err
 		}
@@ -890,26 +1481,26 @@ func binaryToPid(i int, reader *bytes.Reader) (int, interface{}, error) {
 			return i, nil, err
 		}
 		id := make([]byte, 4)
-		_, err = reader.Read(id)
+		_, err = io.ReadFull(reader, id)
 		if err != nil {
 			return i, nil, err
 		}
 		i += 4
 		serial := make([]byte, 4)
-		_, err = reader.Read(serial)
+		_, err = io.ReadFull(reader, serial)
 		if err != nil {
 			return i, nil, err
 		}
 		i += 4
 		creation := make([]byte, 1)
-		_, err = reader.Read(creation)
+		_, err = io.ReadFull(reader, creation)
 		if err != nil {
 			return i, nil, err
 		}
 		i += 1
 		return i, OtpErlangPid{NodeTag: nodeTag, Node: node, ID: id, Serial: serial, Creation: creation}, nil
 	default:
-		return i, nil, parseErrorNew("invalid pid tag")
+		return i, nil, parseErrorNew(ErrInvalidTag, "invalid pid tag")
 	}
 }
 
@@ -966,7 +1557,124 @@ func binaryToAtom(i int, reader *bytes.Reader) (int, uint8, []byte, error) {
 		}
 		return i + 1, tag, []byte{value}, nil
 	default:
-		return i, tag, nil, parseErrorNew("invalid atom tag")
+		return i, tag, nil, parseErrorNew(ErrInvalidTag, "invalid atom tag")
+	}
+}
+
+// (BinaryToTerm Erlang term comparison functions)
+
+// termEqual compares terms while ignoring differences in how
+// the same Erlang term may be represented with Go types
+func termEqual(term1, term2 interface{}) bool {
+	atom1, atom1Ok := termAtomName(term1)
+	atom2, atom2Ok := termAtomName(term2)
+	if atom1Ok || atom2Ok {
+		return atom1Ok && atom2Ok && atom1 == atom2
+	}
+	integer1, integer1Ok := termInteger(term1)
+	integer2, integer2Ok := termInteger(term2)
+	if integer1Ok || integer2Ok {
+		return integer1Ok && integer2Ok && integer1.Cmp(integer2) == 0
+	}
+	binary1, binary1Ok := termBinary(term1)
+	binary2, binary2Ok := termBinary(term2)
+	if binary1Ok || binary2Ok {
+		return binary1Ok && binary2Ok && bytes.Equal(binary1, binary2)
+	}
+	return reflect.DeepEqual(term1, term2)
+}
+
+// termComparable checks that a term is able to be a Go map key
+// (a registered struct is only comparable if its interface{} field
+// values are comparable)
+func termComparable(term interface{}) bool {
+	return valueComparable(reflect.ValueOf(term))
+}
+
+func valueComparable(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Interface:
+		if value.IsNil() {
+			return true
+		}
+		return valueComparable(value.Elem())
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if !valueComparable(value.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if !valueComparable(value.Index(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return value.Type().Comparable()
+	}
+}
+
+func termAtomName(term interface{}) (string, bool) {
+	switch value := term.(type) {
+	case OtpErlangAtom:
+		return string(value), true
+	case OtpErlangAtomUTF8:
+		return string(value), true
+	case bool:
+		if value {
+			return "true", true
+		}
+		return "false", true
+	case nil:
+		return undefined, true
+	default:
+		return "", false
+	}
+}
+
+func termInteger(term interface{}) (*big.Int, bool) {
+	switch value := term.(type) {
+	case uint8:
+		return big.NewInt(int64(value)), true
+	case uint16:
+		return big.NewInt(int64(value)), true
+	case uint32:
+		return big.NewInt(int64(value)), true
+	case uint64:
+		return new(big.Int).SetUint64(value), true
+	case int8:
+		return big.NewInt(int64(value)), true
+	case int16:
+		return big.NewInt(int64(value)), true
+	case int32:
+		return big.NewInt(int64(value)), true
+	case int64:
+		return big.NewInt(value), true
+	case int:
+		return big.NewInt(int64(value)), true
+	case *big.Int:
+		return value, true
+	default:
+		return nil, false
+	}
+}
+
+func termBinary(term interface{}) ([]byte, bool) {
+	switch value := term.(type) {
+	case []byte:
+		return value, true
+	case OtpErlangBinary:
+		if value.Bits != 8 {
+			return nil, false
+		}
+		return value.Value, true
+	default:
+		return nil, false
 	}
 }
 
@@ -994,14 +1702,7 @@ func termsToBinary(termI interface{}, buffer *bytes.Buffer) (*bytes.Buffer, erro
 	case int64:
 		return bignumToBinary(big.NewInt(term), buffer)
 	case int:
-		switch {
-		case term >= 0 && term <= math.MaxUint8:
-			return termsToBinary(uint8(term), buffer)
-		case term >= math.MinInt32 && term <= math.MaxInt32:
-			return integerToBinary(int32(term), buffer)
-		default:
-			return termsToBinary(int64(term), buffer)
-		}
+		return intToBinary(int64(term), buffer)
 	case *big.Int:
 		return bignumToBinary(term, buffer)
 	case float32:
@@ -1046,7 +1747,23 @@ func termsToBinary(termI interface{}, buffer *bytes.Buffer) (*bytes.Buffer, erro
 		return mapToBinary(term, buffer)
 	case OtpErlangList:
 		return listToBinary(term, buffer)
+	case RawTerm:
+		return rawTermToBinary(term, buffer)
+	case Marshaler:
+		// MarshalTerm provides undefined for a nil pointer
+		value, err := MarshalTerm(term)
+		if err != nil {
+			return buffer, err
+		}
+		return termsToBinary(value, buffer)
 	default:
+		tuple, registered, err := registeredTerm(termI)
+		if err != nil {
+			return buffer, err
+		}
+		if registered {
+			return termsToBinary(tuple, buffer)
+		}
 		return buffer, outputErrorNew("unknown go type")
 	}
 }
@@ -1092,25 +1809,10 @@ func stringToBinary(term string, buffer *bytes.Buffer) (*bytes.Buffer, error) {
 }
 
 func tupleToBinary(term []interface{}, buffer *bytes.Buffer) (*bytes.Buffer, error) {
-	var length int
-	var err error
-	switch length = len(term); {
-	case length <= math.MaxUint8:
-		_, err = buffer.Write([]byte{tagSmallTupleExt, byte(length)})
-		if err != nil {
-			return buffer, err
-		}
-	case uint64(length) <= math.MaxUint32:
-		err = buffer.WriteByte(tagLargeTupleExt)
-		if err != nil {
-			return buffer, err
-		}
-		err = binary.Write(buffer, binary.BigEndian, uint32(length))
-		if err != nil {
-			return buffer, err
-		}
-	default:
-		return buffer, outputErrorNew("uint32 overflow")
+	length := len(term)
+	buffer, err := tupleHeaderToBinary(length, buffer)
+	if err != nil {
+		return buffer, err
 	}
 	for i := 0; i < length; i++ {
 		buffer, err = termsToBinary(term[i], buffer)
@@ -1122,20 +1824,9 @@ func tupleToBinary(term []interface{}, buffer *bytes.Buffer) (*bytes.Buffer, err
 }
 
 func mapToBinary(term map[interface{}]interface{}, buffer *bytes.Buffer) (*bytes.Buffer, error) {
-	var length int
-	var err error
-	switch length = len(term); {
-	case uint64(length) <= math.MaxUint32:
-		err = buffer.WriteByte(tagMapExt)
-		if err != nil {
-			return buffer, err
-		}
-		err = binary.Write(buffer, binary.BigEndian, uint32(length))
-		if err != nil {
-			return buffer, err
-		}
-	default:
-		return buffer, outputErrorNew("uint32 overflow")
+	buffer, err := mapHeaderToBinary(len(term), buffer)
+	if err != nil {
+		return buffer, err
 	}
 	for key, value := range term {
 		buffer, err = termsToBinary(key, buffer)
@@ -1157,24 +1848,13 @@ func listToBinary(term OtpErlangList, buffer *bytes.Buffer) (*bytes.Buffer, erro
 	case length == 0:
 		err = buffer.WriteByte(tagNilExt)
 		return buffer, err
-	case uint64(length) <= math.MaxUint32:
-		err = buffer.WriteByte(tagListExt)
-		if err != nil {
-			return buffer, err
-		}
-		if term.Improper {
-			err = binary.Write(buffer, binary.BigEndian, uint32(length-1))
-			if err != nil {
-				return buffer, err
-			}
-		} else {
-			err = binary.Write(buffer, binary.BigEndian, uint32(length))
-			if err != nil {
-				return buffer, err
-			}
-		}
+	case term.Improper:
+		buffer, err = listHeaderToBinary(length-1, buffer)
 	default:
-		return buffer, outputErrorNew("uint32 overflow")
+		buffer, err = listHeaderToBinary(length, buffer)
+	}
+	if err != nil {
+		return buffer, err
 	}
 	for i := 0; i < length; i++ {
 		buffer, err = termsToBinary(term.Value[i], buffer)
@@ -1188,8 +1868,61 @@ func listToBinary(term OtpErlangList, buffer *bytes.Buffer) (*bytes.Buffer, erro
 	return buffer, err
 }
 
+func tupleHeaderToBinary(length int, buffer *bytes.Buffer) (*bytes.Buffer, error) {
+	switch {
+	case length <= math.MaxUint8:
+		_, err := buffer.Write([]byte{tagSmallTupleExt, byte(length)})
+		return buffer, err
+	case uint64(length) <= math.MaxUint32:
+		err := buffer.WriteByte(tagLargeTupleExt)
+		if err != nil {
+			return buffer, err
+		}
+		err = binary.Write(buffer, binary.BigEndian, uint32(length))
+		return buffer, err
+	default:
+		return buffer, outputErrorNew("uint32 overflow")
+	}
+}
+
+func mapHeaderToBinary(length int, buffer *bytes.Buffer) (*bytes.Buffer, error) {
+	if uint64(length) > math.MaxUint32 {
+		return buffer, outputErrorNew("uint32 overflow")
+	}
+	err := buffer.WriteByte(tagMapExt)
+	if err != nil {
+		return buffer, err
+	}
+	err = binary.Write(buffer, binary.BigEndian, uint32(length))
+	return buffer, err
+}
+
+func listHeaderToBinary(length int, buffer *bytes.Buffer) (*bytes.Buffer, error) {
+	if uint64(length) > math.MaxUint32 {
+		return buffer, outputErrorNew("uint32 overflow")
+	}
+	err := buffer.WriteByte(tagListExt)
+	if err != nil {
+		return buffer, err
+	}
+	err = binary.Write(buffer, binary.BigEndian, uint32(length))
+	return buffer, err
+}
+
 // (TermToBinary Erlang term primitive type functions)
 
+func intToBinary(term int64, buffer *bytes.Buffer) (*bytes.Buffer, error) {
+	switch {
+	case term >= 0 && term <= math.MaxUint8:
+		_, err := buffer.Write([]byte{tagSmallIntegerExt, uint8(term)})
+		return buffer, err
+	case term >= math.MinInt32 && term <= math.MaxInt32:
+		return integerToBinary(int32(term), buffer)
+	default:
+		return bignumToBinary(big.NewInt(term), buffer)
+	}
+}
+
 func integerToBinary(term int32, buffer *bytes.Buffer) (*bytes.Buffer, error) {
 	err := buffer.WriteByte(tagIntegerExt)
 	if err != nil {
@@ -1485,3 +2218,11 @@ func functionToBinary(term OtpErlangFunction, buffer *bytes.Buffer) (*bytes.Buff
 	_, err = buffer.Write(term.Value)
 	return buffer, err
 }
+
+func rawTermToBinary(term RawTerm, buffer *bytes.Buffer) (*bytes.Buffer, error) {
+	if len(term) == 0 {
+		return buffer, outputErrorNew("empty RawTerm")
+	}
+	_, err := buffer.Write(term)
+	return buffer, err
+}
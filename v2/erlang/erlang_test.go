@@ -28,7 +28,9 @@ package erlang
 //
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 	"reflect"
@@ -60,7 +62,7 @@ func assertDecodeError(t *testing.T, expectedError, b string, message string) {
 		t.FailNow()
 		return
 	}
-	resultError := err.Error()
+	resultError := errorMessage(err)
 	if expectedError == resultError {
 		return
 	}
@@ -73,6 +75,15 @@ func assertDecodeError(t *testing.T, expectedError, b string, message string) {
 	log.Output(2, message)
 }
 
+func errorMessage(err error) string {
+	var e *ParseError
+	if errors.As(err, &e) {
+		// the error location is checked separately
+		return e.message
+	}
+	return err.Error()
+}
+
 func decode(t *testing.T, b string) interface{} {
 	term, err := BinaryToTerm([]byte(b))
 	if err != nil {
@@ -484,3 +495,132 @@ func listOfLargeTuples(size int) []interface{} {
 	}
 	return tuples
 }
+
+func TestRawTerm(t *testing.T) {
+	// {route, <<"dest">>, [1, {a, b}]}
+	binary := "\x83h\x03w\x05routem\x00\x00\x00\x04destl\x00\x00\x00\x02a\x01h\x02w\x01aw\x01bj"
+	payload := RawTerm("l\x00\x00\x00\x02a\x01h\x02w\x01aw\x01bj")
+	term, err := BinaryToTermRaw([]byte(binary), []interface{}{2})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{OtpErlangAtomUTF8("route"), OtpErlangBinary{Value: []byte("dest"), Bits: 8}, payload}, term, "")
+	assertEqual(t, binary, encode(t, term, -1), "")
+	term, err = BinaryToTermRaw([]byte(binary), []interface{}{2, 1}, []interface{}{0})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{RawTerm("w\x05route"), OtpErlangBinary{Value: []byte("dest"), Bits: 8}, OtpErlangList{Value: []interface{}{uint8(1), RawTerm("h\x02w\x01aw\x01b")}}}, term, "")
+	term, err = BinaryToTermRaw([]byte(binary), []interface{}{})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, RawTerm(binary[1:]), term, "")
+	term, err = BinaryToTermRaw([]byte(encode(t, OtpErlangTuple{OtpErlangAtomUTF8("route"), strings.Repeat("d", 20)}, 9)), []interface{}{1})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{OtpErlangAtomUTF8("route"), RawTerm("k\x00\x14" + strings.Repeat("d", 20))}, term, "")
+	// map values are found with an equal key
+	map1 := "\x83t\x00\x00\x00\x01s\x01aa\x01"
+	term, err = BinaryToTermRaw([]byte(map1), []interface{}{OtpErlangAtomUTF8("a")})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangMap{OtpErlangAtom("a"): RawTerm("a\x01")}, term, "")
+	assertEqual(t, map1, encode(t, term, -1), "")
+	_, err = TermToBinary(RawTerm{}, -1)
+	assertEqual(t, "empty RawTerm", err.Error(), "")
+}
+
+func TestDecodeBinaryToTermLocal(t *testing.T) {
+	binary := "\x83y\x00\x01\x02\x03\x04\x05\x06\x07h\x01a\x01"
+	assertEqual(t, RawTerm(binary[1:]), decode(t, binary), "")
+	assertEqual(t, binary, encode(t, decode(t, binary), -1), "")
+	// nested LOCAL_EXT would consume the data that follows it
+	nested := "\x83h\x02y\x00\x01a\x01"
+	assertDecodeError(t, "LOCAL_EXT nested", nested, "")
+	_, err := BinaryToTerm([]byte(nested))
+	assertEqual(t, true, errors.Is(err, ErrInvalidData), "")
+	assertEqual(t, "LOCAL_EXT nested: offset 3, tag 121 (LOCAL_EXT), path tuple[0]", err.Error(), "")
+	err = Validate([]byte(nested))
+	assertEqual(t, true, errors.Is(err, ErrInvalidData), "")
+	_, err = Lookup([]byte(nested), 1)
+	assertEqual(t, true, errors.Is(err, ErrInvalidData), "")
+}
+
+func TestDecodeBinaryToTermFunction(t *testing.T) {
+	assertDecodeError(t, "invalid function size", "\x83p\x00\x00\x00\x03", "")
+	assertDecodeError(t, "EOF", "\x83p\x00\x00\x00\x05", "")
+	binary := "\x83p\x00\x00\x00\x08abcd"
+	assertEqual(t, OtpErlangFunction{Tag: 112, Value: []byte("\x00\x00\x00\x08abcd")}, decode(t, binary), "")
+	assertEqual(t, binary, encode(t, decode(t, binary), -1), "")
+}
+
+func TestParseError(t *testing.T) {
+	// {a, b, #{k => [0, 0, ... | <<"ab" (truncated)]}}
+	binary := "\x83h\x03w\x01aw\x01bt\x00\x00\x00\x01w\x01kl\x00\x00\x00\x10" + strings.Repeat("a\x00", 14) + "m\x00\x00\x00\x05ab"
+	_, err := BinaryToTerm([]byte(binary))
+	var e *ParseError
+	assertEqual(t, true, errors.As(err, &e), "")
+	assertEqual(t, true, errors.Is(err, ErrTruncated), "")
+	assertEqual(t, true, errors.Is(err, io.ErrUnexpectedEOF), "")
+	assertEqual(t, false, errors.Is(err, ErrInvalidTag), "")
+	assertEqual(t, 50, e.Offset, "")
+	assertEqual(t, tagBinaryExt, e.Tag, "")
+	assertEqual(t, "tuple[2].map[k].list[14]", e.Path, "")
+	assertEqual(t, "unexpected EOF: offset 50, tag 109 (BINARY_EXT), path tuple[2].map[k].list[14]", err.Error(), "")
+	_, err = BinaryToTermRaw([]byte(binary), []interface{}{0})
+	assertEqual(t, "unexpected EOF: offset 50, tag 109 (BINARY_EXT), path tuple[2].map[k].list[14]", err.Error(), "")
+	_, err = Lookup([]byte(binary), 0)
+	assertEqual(t, "unexpected EOF: offset 50, tag 109 (BINARY_EXT), path tuple[2].map[k].list[14]", err.Error(), "")
+	// binary map keys are only decoded by Validate and Lookup
+	binary = "\x83h\x03w\x01aw\x01bt\x00\x00\x00\x01m\x00\x00\x00\x01kl\x00\x00\x00\x10" + strings.Repeat("a\x00", 14) + "z"
+	err = Validate([]byte(binary))
+	assertEqual(t, true, errors.Is(err, ErrInvalidTag), "")
+	assertEqual(t, "invalid tag: offset 53, tag 122, path tuple[2].map[<<\"k\">>].list[14]", err.Error(), "")
+	_, err = BinaryToTerm([]byte(binary))
+	assertEqual(t, "map key not comparable: offset 14, tag 109 (BINARY_EXT), path tuple[2].mapkey[0]", err.Error(), "")
+
+	_, err = BinaryToTerm([]byte("\x83"))
+	assertEqual(t, true, errors.Is(err, ErrNullInput), "")
+	assertEqual(t, "null input", err.Error(), "")
+	_, err = BinaryToTerm([]byte("\x82j"))
+	assertEqual(t, true, errors.Is(err, ErrInvalidVersion), "")
+	_, err = BinaryToTerm([]byte("\x83jj"))
+	assertEqual(t, true, errors.Is(err, ErrUnparsedData), "")
+	assertEqual(t, "unparsed data: offset 2, tag 106 (NIL_EXT)", err.Error(), "")
+	_, err = BinaryToTerm([]byte("\x83P\x00\x00\x00\x01\x00"))
+	assertEqual(t, true, errors.Is(err, ErrCompression), "")
+	err = ValidateLimits([]byte("\x83h\x02jj"), Limits{TupleArity: 1})
+	assertEqual(t, true, errors.Is(err, ErrLimit), "")
+	_, err = Lookup([]byte("\x83h\x01h\x01j"), 0, 1)
+	assertEqual(t, true, errors.Is(err, ErrPathNotFound), "")
+	assertEqual(t, "path not found: offset 3, tag 104 (SMALL_TUPLE_EXT), path tuple[0].tuple[1]", err.Error(), "")
+}
+
+func TestDecodeBinaryToTermPrefix(t *testing.T) {
+	term, i, err := BinaryToTermPrefix([]byte("\x83a\x01\x83a\x02"))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, uint8(1), term, "")
+	assertEqual(t, 3, i, "")
+	// a compressed term ends with its zlib stream
+	compressed := encode(t, strings.Repeat("a", 64), 6)
+	assertEqual(t, byte(tagCompressedZlib), compressed[1], "")
+	term, i, err = BinaryToTermPrefix([]byte(compressed + "\x83a\x02"))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, strings.Repeat("a", 64), term, "")
+	assertEqual(t, len(compressed), i, "")
+	assertDecodeError(t, "unparsed data", compressed+"a\x02", "")
+	_, err = BinaryToTermRaw([]byte(compressed+"a\x02"), []interface{}{})
+	assertEqual(t, true, errors.Is(err, ErrUnparsedData), "")
+	err = Validate([]byte(compressed + "a\x02"))
+	assertEqual(t, true, errors.Is(err, ErrUnparsedData), "")
+}
+
+func TestNodeName(t *testing.T) {
+	assertEqual(t, "nonode@nohost", NodeName(tagSmallAtomUtf8Ext, []byte("\x0dnonode@nohost")), "")
+	assertEqual(t, "nonode@nohost", NodeName(tagAtomExt, []byte("\x00\x0dnonode@nohost")), "")
+	assertEqual(t, "", NodeName(tagNilExt, []byte("\x0dnonode@nohost")), "")
+}
+
+func TestAtomCacheRef(t *testing.T) {
+	// ATOM_CACHE_REF is tag 82 (not 78)
+	assertEqual(t, OtpErlangAtomCacheRef(5), decode(t, "\x83R\x05"), "")
+	assertEqual(t, "\x83R\x05", encode(t, OtpErlangAtomCacheRef(5), -1), "")
+	assertEqual(t, OtpErlangTuple{OtpErlangAtomCacheRef(0), uint8(1)},
+		decode(t, "\x83h\x02R\x00a\x01"), "")
+	assertEqual(t, nil, Validate([]byte("\x83R\x05")), "")
+	_, err := BinaryToTerm([]byte("\x83N\x05"))
+	assertEqual(t, true, errors.Is(err, ErrInvalidTag), "")
+}
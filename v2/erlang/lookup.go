@@ -0,0 +1,718 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"unicode/utf8"
+)
+
+// Lookup decodes only the subterm at the path within
+// the Erlang External Term Format, skipping all other subterms.
+// A path element is a 0-based index (int) for a tuple or a list
+// (an improper list tail is the last index) or a key for a map.
+func Lookup(data []byte, path ...interface{}) (interface{}, error) {
+	raw, err := LookupRaw(data, path...)
+	if err != nil {
+		return nil, err
+	}
+	var term interface{}
+	_, term, err = binaryToTerms(0, bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// LookupRaw provides the subterm at the path within
+// the Erlang External Term Format without decoding it
+// (the RawTerm refers to the data unless the data is compressed)
+func LookupRaw(data []byte, path ...interface{}) (RawTerm, error) {
+	size := len(data)
+	if size <= 1 {
+		return nil, parseErrorNew(ErrNullInput, "null input")
+	}
+	if data[0] != tagVersion {
+		return nil, parseErrorNew(ErrInvalidVersion, "invalid version")
+	}
+	i := 1
+	if data[1] == tagCompressedZlib {
+		var end int
+		var err error
+		end, data, err = binaryToUncompressed(2, bytes.NewReader(data[2:]))
+		if err != nil {
+			return nil, err
+		}
+		if end != size {
+			return nil, parseErrorNew(ErrUnparsedData, "unparsed data")
+		}
+		size = len(data)
+		i = 0
+	}
+	scanner := &termScanner{data: data}
+	end, err := scanner.skip(i)
+	if err != nil {
+		return nil, err
+	}
+	if end != size {
+		return nil, parseErrorNew(ErrUnparsedData, "unparsed data")
+	}
+	return scanner.lookup(i, path)
+}
+
+// termScanner skips over encoded terms without allocating
+// (limits are only checked when validating)
+type termScanner struct {
+	data     []byte
+	limits   *Limits
+	depth    int
+	validate bool
+}
+
+func (s *termScanner) lookup(i int, path []interface{}) (RawTerm, error) {
+	var location string
+	for pathIndex, key := range path {
+		iTerm := i
+		tag, err := s.uint8(i)
+		if err != nil {
+			return nil, err
+		}
+		var kind string
+		switch tag {
+		case tagSmallTupleExt:
+			fallthrough
+		case tagLargeTupleExt:
+			var length int
+			kind = "tuple"
+			i, length, err = s.tupleHeader(i)
+			if err != nil {
+				return nil, err
+			}
+			index, ok := key.(int)
+			if !ok || index < 0 || index >= length {
+				return nil, s.pathNotFound(iTerm, location, kind, key)
+			}
+			i, err = s.skipSequence(i, index, "tuple")
+			if err != nil {
+				return nil, err
+			}
+		case tagStringExt:
+			kind = "list"
+			var length uint16
+			length, err = s.uint16(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			index, ok := key.(int)
+			if !ok || index < 0 || index >= int(length) ||
+				pathIndex != len(path)-1 {
+				return nil, s.pathNotFound(iTerm, location, kind, key)
+			}
+			// an element is a small integer that is not within the data
+			return RawTerm{tagSmallIntegerExt, s.data[i+3+index]}, nil
+		case tagListExt:
+			kind = "list"
+			var length uint32
+			length, err = s.uint32(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			index, ok := key.(int)
+			if !ok || index < 0 || index > int(length) {
+				return nil, s.pathNotFound(iTerm, location, kind, key)
+			}
+			i, err = s.skipSequence(i+5, index, "list")
+			if err != nil {
+				return nil, err
+			}
+			if index == int(length) {
+				// only an improper list tail is an element
+				var tail uint8
+				tail, err = s.uint8(i)
+				if err != nil {
+					return nil, err
+				}
+				if tail == tagNilExt {
+					return nil, s.pathNotFound(iTerm, location, kind, key)
+				}
+			}
+		case tagMapExt:
+			kind = "map"
+			var length uint32
+			length, err = s.uint32(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			i += 5
+			found := false
+			for lengthIndex := 0; lengthIndex < int(length); lengthIndex++ {
+				var iValue int
+				iValue, err = s.skip(i)
+				if err != nil {
+					return nil, err
+				}
+				var equal bool
+				equal, err = s.keyEqual(i, iValue, key)
+				if err != nil {
+					return nil, err
+				}
+				i = iValue
+				if equal {
+					found = true
+					break
+				}
+				i, err = s.skip(i)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if !found {
+				return nil, s.pathNotFound(iTerm, location, kind, key)
+			}
+		default:
+			return nil, s.pathNotFound(iTerm, location, "term", key)
+		}
+		location = pathJoin(location, pathSegment(kind, key))
+	}
+	end, err := s.skip(i)
+	if err != nil {
+		return nil, err
+	}
+	return RawTerm(s.data[i:end]), nil
+}
+
+func (s *termScanner) pathNotFound(i int, location, kind string, key interface{}) error {
+	err := parseErrorNew(ErrPathNotFound, "path not found")
+	err = parseErrorAt(err, i, int(s.data[i]))
+	err.(*ParseError).Path = pathJoin(location, pathSegment(kind, key))
+	return err
+}
+
+func pathJoin(location, segment string) string {
+	if len(location) == 0 {
+		return segment
+	}
+	return location + "." + segment
+}
+
+// keyEqual compares the encoded term in data[i:end] with a Go term
+func (s *termScanner) keyEqual(i, end int, key interface{}) (bool, error) {
+	tag := s.data[i]
+	if name, ok := termAtomName(key); ok {
+		switch tag {
+		case tagAtomExt:
+			fallthrough
+		case tagAtomUtf8Ext:
+			return string(s.data[i+3:end]) == name, nil
+		case tagSmallAtomExt:
+			fallthrough
+		case tagSmallAtomUtf8Ext:
+			return string(s.data[i+2:end]) == name, nil
+		default:
+			return false, nil
+		}
+	}
+	if integer, ok := termInteger(key); ok {
+		switch tag {
+		case tagSmallIntegerExt:
+			return integer.IsInt64() && integer.Int64() == int64(s.data[i+1]), nil
+		case tagIntegerExt:
+			value := int32(binary.BigEndian.Uint32(s.data[i+1:]))
+			return integer.IsInt64() && integer.Int64() == int64(value), nil
+		case tagSmallBigExt:
+			fallthrough
+		case tagLargeBigExt:
+			_, value, err := binaryToTerms(0, bytes.NewReader(s.data[i:end]))
+			if err != nil {
+				return false, err
+			}
+			return integer.Cmp(value.(*big.Int)) == 0, nil
+		default:
+			return false, nil
+		}
+	}
+	if value, ok := termBinary(key); ok {
+		if tag != tagBinaryExt {
+			return false, nil
+		}
+		return bytes.Equal(s.data[i+5:end], value), nil
+	}
+	_, value, err := binaryToTerms(0, bytes.NewReader(s.data[i:end]))
+	if err != nil {
+		return false, err
+	}
+	return termEqual(key, value), nil
+}
+
+func (s *termScanner) tupleHeader(i int) (int, int, error) {
+	if s.data[i] == tagSmallTupleExt {
+		length, err := s.uint8(i + 1)
+		return i + 2, int(length), err
+	}
+	length, err := s.uint32(i + 1)
+	return i + 5, int(length), err
+}
+
+func (s *termScanner) skipSequence(i, length int, kind string) (int, error) {
+	var err error
+	iKey := i
+	for lengthIndex := 0; lengthIndex < length; lengthIndex++ {
+		iElement := i
+		i, err = s.skip(i)
+		if err != nil {
+			if kind != "map" {
+				return i, parseErrorPath(err, pathSegment(kind, lengthIndex))
+			}
+			if lengthIndex%2 == 0 {
+				return i, parseErrorPath(err, pathSegment("mapkey", lengthIndex/2))
+			}
+			// only decode the key to describe the error
+			_, key, errKey := binaryToTerms(0, bytes.NewReader(s.data[iKey:iElement]))
+			if errKey != nil {
+				key = RawTerm(s.data[iKey:iElement])
+			}
+			return i, parseErrorPath(err, pathSegment("map", key))
+		}
+		iKey = iElement
+	}
+	return i, nil
+}
+
+// skip provides the index after the encoded term that starts at index i
+func (s *termScanner) skip(i int) (int, error) {
+	end, err := s.skipTag(i)
+	if err != nil {
+		tag := -1
+		if i < len(s.data) {
+			tag = int(s.data[i])
+		}
+		return end, parseErrorAt(err, i, tag)
+	}
+	return end, nil
+}
+
+func (s *termScanner) skipTag(i int) (int, error) {
+	tag, err := s.uint8(i)
+	if err != nil {
+		return i, err
+	}
+	i += 1
+	switch tag {
+	case tagNewFloatExt:
+		return s.bytes(i, 8)
+	case tagBitBinaryExt:
+		var j uint32
+		j, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		if s.validate {
+			err = s.limit(int(j), s.limits.BinarySize, "binary size")
+			if err != nil {
+				return i, err
+			}
+			var bits uint8
+			bits, err = s.uint8(i + 4)
+			if err != nil {
+				return i, err
+			}
+			if bits < 1 || bits > 8 {
+				return i, parseErrorNew(ErrInvalidData, "invalid bits")
+			}
+		}
+		return s.bytes(i+5, int(j))
+	case tagAtomCacheRef:
+		fallthrough
+	case tagSmallIntegerExt:
+		return s.bytes(i, 1)
+	case tagIntegerExt:
+		return s.bytes(i, 4)
+	case tagFloatExt:
+		return s.bytes(i, 31)
+	case tagV4PortExt:
+		i, err = s.skipAtom(i)
+		if err != nil {
+			return i, err
+		}
+		return s.bytes(i, 8+4)
+	case tagNewPortExt:
+		i, err = s.skipAtom(i)
+		if err != nil {
+			return i, err
+		}
+		return s.bytes(i, 4+4)
+	case tagReferenceExt:
+		fallthrough
+	case tagPortExt:
+		i, err = s.skipAtom(i)
+		if err != nil {
+			return i, err
+		}
+		return s.bytes(i, 4+1)
+	case tagNewPidExt:
+		i, err = s.skipAtom(i)
+		if err != nil {
+			return i, err
+		}
+		return s.bytes(i, 4+4+4)
+	case tagPidExt:
+		i, err = s.skipAtom(i)
+		if err != nil {
+			return i, err
+		}
+		return s.bytes(i, 4+4+1)
+	case tagSmallTupleExt:
+		var length uint8
+		length, err = s.uint8(i)
+		if err != nil {
+			return i, err
+		}
+		if s.validate {
+			err = s.limit(int(length), s.limits.TupleArity, "tuple arity")
+			if err != nil {
+				return i, err
+			}
+		}
+		return s.skipNested(i+1, int(length), "tuple")
+	case tagLargeTupleExt:
+		var length uint32
+		length, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		if s.validate {
+			err = s.limit(int(length), s.limits.TupleArity, "tuple arity")
+			if err != nil {
+				return i, err
+			}
+		}
+		return s.skipNested(i+4, int(length), "tuple")
+	case tagNilExt:
+		return i, nil
+	case tagStringExt:
+		var j uint16
+		j, err = s.uint16(i)
+		if err != nil {
+			return i, err
+		}
+		if s.validate {
+			err = s.limit(int(j), s.limits.ListLength, "list length")
+			if err != nil {
+				return i, err
+			}
+		}
+		return s.bytes(i+2, int(j))
+	case tagListExt:
+		var length uint32
+		length, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		if s.validate {
+			err = s.limit(int(length), s.limits.ListLength, "list length")
+			if err != nil {
+				return i, err
+			}
+		}
+		// the elements and the tail
+		return s.skipNested(i+4, int(length)+1, "list")
+	case tagBinaryExt:
+		var j uint32
+		j, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		if s.validate {
+			err = s.limit(int(j), s.limits.BinarySize, "binary size")
+			if err != nil {
+				return i, err
+			}
+		}
+		return s.bytes(i+4, int(j))
+	case tagSmallBigExt:
+		var j uint8
+		j, err = s.uint8(i)
+		if err != nil {
+			return i, err
+		}
+		return s.skipBignum(i+1, int(j))
+	case tagLargeBigExt:
+		var j uint32
+		j, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		return s.skipBignum(i+4, int(j))
+	case tagNewFunExt:
+		// the size includes the size field
+		var length uint32
+		length, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		if length < 4 {
+			return i, parseErrorNew(ErrInvalidData, "invalid function size")
+		}
+		return s.bytes(i, int(length))
+	case tagExportExt:
+		i, err = s.skipAtom(i) // module
+		if err != nil {
+			return i, err
+		}
+		i, err = s.skipAtom(i) // function
+		if err != nil {
+			return i, err
+		}
+		var arityTag uint8
+		arityTag, err = s.uint8(i)
+		if err != nil {
+			return i, err
+		}
+		if arityTag != tagSmallIntegerExt {
+			return i, parseErrorNew(ErrInvalidTag, "invalid small integer tag")
+		}
+		return s.bytes(i+1, 1)
+	case tagNewerReferenceExt:
+		fallthrough
+	case tagNewReferenceExt:
+		var j uint16
+		j, err = s.uint16(i)
+		if err != nil {
+			return i, err
+		}
+		i, err = s.skipAtom(i + 2)
+		if err != nil {
+			return i, err
+		}
+		if tag == tagNewerReferenceExt {
+			return s.bytes(i, 4+int(j)*4)
+		}
+		return s.bytes(i, 1+int(j)*4)
+	case tagMapExt:
+		var length uint32
+		length, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		if s.validate {
+			err = s.limit(int(length), s.limits.MapSize, "map size")
+			if err != nil {
+				return i, err
+			}
+		}
+		return s.skipNested(i+4, int(length)*2, "map")
+	case tagFunExt:
+		var numfree uint32
+		numfree, err = s.uint32(i)
+		if err != nil {
+			return i, err
+		}
+		i += 4
+		var pidTag uint8
+		pidTag, err = s.uint8(i)
+		if err != nil {
+			return i, err
+		}
+		if pidTag != tagNewPidExt && pidTag != tagPidExt {
+			return i, parseErrorNew(ErrInvalidTag, "invalid pid tag")
+		}
+		i, err = s.skip(i) // pid
+		if err != nil {
+			return i, err
+		}
+		i, err = s.skipAtom(i) // module
+		if err != nil {
+			return i, err
+		}
+		for integerIndex := 0; integerIndex < 2; integerIndex++ {
+			// index and uniq
+			var integerTag uint8
+			integerTag, err = s.uint8(i)
+			if err != nil {
+				return i, err
+			}
+			if integerTag != tagSmallIntegerExt && integerTag != tagIntegerExt {
+				return i, parseErrorNew(ErrInvalidTag, "invalid integer tag")
+			}
+			i, err = s.skip(i)
+			if err != nil {
+				return i, err
+			}
+		}
+		return s.skipNested(i, int(numfree), "fun") // free
+	case tagAtomUtf8Ext:
+		fallthrough
+	case tagAtomExt:
+		var j uint16
+		j, err = s.uint16(i)
+		if err != nil {
+			return i, err
+		}
+		return s.skipAtomName(i+2, int(j), tag == tagAtomUtf8Ext)
+	case tagSmallAtomUtf8Ext:
+		fallthrough
+	case tagSmallAtomExt:
+		var j uint8
+		j, err = s.uint8(i)
+		if err != nil {
+			return i, err
+		}
+		return s.skipAtomName(i+1, int(j), tag == tagSmallAtomUtf8Ext)
+	case tagCompressedZlib:
+		if s.validate {
+			// only valid after the version tag
+			return i, parseErrorNew(ErrInvalidTag, "invalid tag")
+		}
+		// consumes the remaining data
+		return len(s.data), nil
+	case tagLocalExt:
+		// only valid as the whole term, consuming the remaining data
+		// (a nested term never has its tag at offset 0 or 1)
+		if i > 2 {
+			return i, parseErrorNew(ErrInvalidData, "LOCAL_EXT nested")
+		}
+		return len(s.data), nil
+	default:
+		return i, parseErrorNew(ErrInvalidTag, "invalid tag")
+	}
+}
+
+func (s *termScanner) skipNested(i, length int, kind string) (int, error) {
+	if !s.validate {
+		return s.skipSequence(i, length, kind)
+	}
+	s.depth += 1
+	err := s.limit(s.depth, s.limits.Depth, "depth")
+	if err != nil {
+		return i, err
+	}
+	i, err = s.skipSequence(i, length, kind)
+	s.depth -= 1
+	return i, err
+}
+
+func (s *termScanner) skipBignum(i, length int) (int, error) {
+	if s.validate {
+		err := s.limit(length, s.limits.BignumSize, "bignum size")
+		if err != nil {
+			return i, err
+		}
+		var sign uint8
+		sign, err = s.uint8(i)
+		if err != nil {
+			return i, err
+		}
+		if sign > 1 {
+			return i, parseErrorNew(ErrInvalidData, "invalid bignum sign")
+		}
+	}
+	return s.bytes(i, 1+length)
+}
+
+func (s *termScanner) skipAtomName(i, length int, utf8Name bool) (int, error) {
+	end, err := s.bytes(i, length)
+	if err != nil {
+		return i, err
+	}
+	if s.validate {
+		err = s.limit(length, s.limits.AtomLength, "atom length")
+		if err != nil {
+			return i, err
+		}
+		if utf8Name && !utf8.Valid(s.data[i:end]) {
+			return i, parseErrorNew(ErrInvalidData, "invalid atom utf8")
+		}
+	}
+	return end, nil
+}
+
+func (s *termScanner) skipAtom(i int) (int, error) {
+	tag, err := s.uint8(i)
+	if err != nil {
+		return i, err
+	}
+	switch tag {
+	case tagAtomUtf8Ext:
+		fallthrough
+	case tagAtomExt:
+		fallthrough
+	case tagSmallAtomUtf8Ext:
+		fallthrough
+	case tagSmallAtomExt:
+		fallthrough
+	case tagAtomCacheRef:
+		return s.skip(i)
+	default:
+		return i + 1, parseErrorNew(ErrInvalidTag, "invalid atom tag")
+	}
+}
+
+// (termScanner primitive type functions)
+
+func (s *termScanner) limit(value, limit int, name string) error {
+	if limit > 0 && value > limit {
+		return parseErrorNew(ErrLimit, name+" limit exceeded")
+	}
+	return nil
+}
+
+func (s *termScanner) bytes(i, length int) (int, error) {
+	if length < 0 || length > len(s.data)-i {
+		if i >= len(s.data) {
+			return i, io.EOF
+		}
+		return i, io.ErrUnexpectedEOF
+	}
+	return i + length, nil
+}
+
+func (s *termScanner) uint8(i int) (uint8, error) {
+	_, err := s.bytes(i, 1)
+	if err != nil {
+		return 0, err
+	}
+	return s.data[i], nil
+}
+
+func (s *termScanner) uint16(i int) (uint16, error) {
+	_, err := s.bytes(i, 2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(s.data[i:]), nil
+}
+
+func (s *termScanner) uint32(i int) (uint32, error) {
+	_, err := s.bytes(i, 4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(s.data[i:]), nil
+}
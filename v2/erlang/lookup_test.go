@@ -0,0 +1,127 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func lookup(t *testing.T, b string, path ...interface{}) interface{} {
+	term, err := Lookup([]byte(b), path...)
+	if err != nil {
+		t.Fatalf("Lookup(%v): %s", path, errorMessage(err))
+	}
+	return term
+}
+
+func TestLookupTuple(t *testing.T) {
+	// {route, <<"dest">>, [1, {a, b} | tail]}
+	binary := "\x83h\x03w\x05routem\x00\x00\x00\x04destl\x00\x00\x00\x02a\x01h\x02w\x01aw\x01bw\x04tail"
+	assertEqual(t, OtpErlangAtomUTF8("route"), lookup(t, binary, 0), "")
+	assertEqual(t, OtpErlangBinary{Value: []byte("dest"), Bits: 8}, lookup(t, binary, 1), "")
+	assertEqual(t, uint8(1), lookup(t, binary, 2, 0), "")
+	assertEqual(t, OtpErlangAtomUTF8("b"), lookup(t, binary, 2, 1, 1), "")
+	assertEqual(t, OtpErlangAtomUTF8("tail"), lookup(t, binary, 2, 2), "")
+	raw, err := LookupRaw([]byte(binary), 2, 1)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, RawTerm("h\x02w\x01aw\x01b"), raw, "")
+	raw, err = LookupRaw([]byte(binary))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, RawTerm(binary[1:]), raw, "")
+	_, err = Lookup([]byte(binary), 3)
+	assertEqual(t, "path not found", errorMessage(err), "")
+	_, err = Lookup([]byte(binary), 0, 0)
+	assertEqual(t, "path not found", errorMessage(err), "")
+	_, err = Lookup([]byte(binary), OtpErlangAtom("route"))
+	assertEqual(t, "path not found", errorMessage(err), "")
+	_, err = Lookup([]byte(binary[:len(binary)-1]), 0)
+	assertEqual(t, "unexpected EOF", errorMessage(err), "")
+	_, err = Lookup([]byte(binary+"j"), 0)
+	assertEqual(t, "unparsed data", errorMessage(err), "")
+}
+func TestLookupList(t *testing.T) {
+	assertEqual(t, uint8('e'), lookup(t, "\x83k\x00\x04test", 1), "")
+	_, err := Lookup([]byte("\x83k\x00\x04test"), 4)
+	assertEqual(t, "path not found", errorMessage(err), "")
+	_, err = Lookup([]byte("\x83l\x00\x00\x00\x01a\x01j"), 1)
+	assertEqual(t, "path not found", errorMessage(err), "")
+	n := 256
+	encoded := encode(t, OtpErlangList{Value: listOfLargeTuples(n)}, 1)
+	assertEqual(t, OtpErlangAtom("couchdb@this-is-a-long-hostname-somewhere-over-the-rainbow-in-the.cloudapp.net"), lookup(t, encoded, n-1, 2, 2), "")
+}
+func TestLookupMap(t *testing.T) {
+	map1 := make(OtpErlangMap)
+	map1[OtpErlangAtomUTF8("key")] = OtpErlangTuple{uint8(1), uint8(2)}
+	map1[uint8(7)] = "seven"
+	map1[big.NewInt(0).Lsh(big.NewInt(1), 70)] = "big"
+	map1["k"] = OtpErlangList{Value: []interface{}{uint8(14)}}
+	map1[1.5] = "float"
+	binary := encode(t, map1, -1)
+	assertEqual(t, uint8(2), lookup(t, binary, OtpErlangAtom("key"), 1), "")
+	assertEqual(t, uint8(2), lookup(t, binary, OtpErlangAtomUTF8("key"), 1), "")
+	assertEqual(t, "seven", lookup(t, binary, 7), "")
+	assertEqual(t, "seven", lookup(t, binary, int32(7)), "")
+	assertEqual(t, "big", lookup(t, binary, big.NewInt(0).Lsh(big.NewInt(1), 70)), "")
+	assertEqual(t, uint8(14), lookup(t, binary, "k", 0), "")
+	assertEqual(t, "float", lookup(t, binary, 1.5), "")
+	_, err := Lookup([]byte(binary), OtpErlangAtomUTF8("missing"))
+	assertEqual(t, "path not found", errorMessage(err), "")
+	// binary keys are not comparable after decoding
+	binary = "\x83t\x00\x00\x00\x01m\x00\x00\x00\x01kl\x00\x00\x00\x01a\x0ej"
+	assertEqual(t, uint8(14), lookup(t, binary, []byte("k"), 0), "")
+	assertEqual(t, uint8(14), lookup(t, binary, OtpErlangBinary{Value: []byte("k"), Bits: 8}, 0), "")
+}
+func TestLookupCompressed(t *testing.T) {
+	term := OtpErlangTuple{OtpErlangAtomUTF8("route"), strings.Repeat("d", 20)}
+	assertEqual(t, strings.Repeat("d", 20), lookup(t, encode(t, term, 9), 1), "")
+	assertEqual(t, strings.Repeat("d", 20), lookup(t, "\x83P\x00\x00\x00\x17\x78\xda\xcb\x66\x10\x49\xc1\x02\x00\x5d\x60\x08\x50"), "")
+}
+func TestLookupSkip(t *testing.T) {
+	binaries := []string{
+		"\x83gd\x00\rnonode@nohost\x00\x00\x00N\x00\x00\x00\x00\x00",
+		"\x83Xd\x00\rnonode@nohost\x00\x00\x00N\x00\x00\x00\x00\x00\x00\x00\x00",
+		"\x83fd\x00\rnonode@nohost\x00\x00\x00\x06\x00",
+		"\x83Yd\x00\rnonode@nohost\x00\x00\x00\x06\x00\x00\x00\x00",
+		"\x83r\x00\x03d\x00\rnonode@nohost\x00\x00\x03\xe8N\xe7h\x00\x02\xa4\xc8S@",
+		"\x83Z\x00\x03d\x00\rnonode@nohost\x00\x00\x00\x00\x00\x01\xac\x03\xc7\x00\x00\x04\xbb\xb2\xca\xee",
+		"\x83\x71\x64\x00\x05\x6C\x69\x73\x74\x73\x64\x00\x06\x6D\x65\x6D\x62\x65\x72\x61\x02",
+		"\x83p\x00\x00\x00\x08abcd",
+		"\x83o\x00\x00\x00\x06\x01\x01\x02\x03\x04\x05\x06",
+		"\x83n\x06\x00\x01\x02\x03\x04\x05\x06",
+		"\x83F?\xf8\x00\x00\x00\x00\x00\x00",
+		"\x83\x74\x00\x00\x00\x01\x77\x0A\x65\x76\x65\x72\x79\x74\x68\x69\x6E\x67\x4D\x00\x00\x00\x01\x06\xA8",
+		"\x83i\x00\x00\x00\x02jj",
+		"\x83y\x00\x01\x02\x03",
+	}
+	for _, binary := range binaries {
+		raw, err := LookupRaw([]byte(binary))
+		assertEqual(t, nil, err, "")
+		assertEqual(t, RawTerm(binary[1:]), raw, "")
+	}
+}
@@ -0,0 +1,610 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Marshaler is implemented by Go types that provide their own term
+type Marshaler interface {
+	MarshalErlang() (interface{}, error)
+}
+
+// Unmarshaler is implemented by Go types that store a term themselves
+type Unmarshaler interface {
+	UnmarshalErlang(term interface{}) error
+}
+
+// UnmarshalError describes a term that can not be stored in a Go value
+type UnmarshalError struct {
+	Term string       // Erlang text of the term
+	Type reflect.Type // Go type of the value
+	Path string       // path to the value (e.g., .Users[2].Name)
+}
+
+func (e *UnmarshalError) Error() string {
+	message := "cannot unmarshal " + e.Term + " into Go value of type " +
+		e.Type.String()
+	if len(e.Path) > 0 {
+		message += " at " + e.Path
+	}
+	return message
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType   = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	bytesType         = reflect.TypeOf([]byte(nil))
+	bigIntType        = reflect.TypeOf((*big.Int)(nil))
+	recordType        = reflect.TypeOf(Record{})
+	structFieldsCache sync.Map // map[reflect.Type][]structField
+	structRecordCache sync.Map // map[reflect.Type]string
+)
+
+// Marshal encodes a Go value in the Erlang External Term Format
+// (see MarshalTerm)
+func Marshal(value interface{}) ([]byte, error) {
+	term, err := MarshalTerm(value)
+	if err != nil {
+		return nil, err
+	}
+	return TermToBinary(term, -1)
+}
+
+// Unmarshal decodes the Erlang External Term Format into the
+// Go value pointed to (see UnmarshalTerm)
+func Unmarshal(data []byte, value interface{}) error {
+	term, err := BinaryToTerm(data)
+	if err != nil {
+		return err
+	}
+	return UnmarshalTerm(term, value)
+}
+
+// MarshalTerm converts a Go value into the terms that TermToBinary encodes.
+// Structs become maps with atom keys, slices and arrays become lists,
+// a []byte becomes a binary and a nil pointer becomes the undefined atom.
+// The struct field tag `erlang:"name,omitempty"` provides the atom name
+// (the default is the field name in snake_case and "-" omits the field).
+// A struct that embeds Record becomes a record tuple instead of a map
+// (omitempty is ignored).
+func MarshalTerm(value interface{}) (interface{}, error) {
+	return marshalValue(reflect.ValueOf(value))
+}
+
+// UnmarshalTerm stores a term in the Go value pointed to, with the
+// conversions of MarshalTerm (strings accept binaries, atoms and
+// lists of characters)
+func UnmarshalTerm(term interface{}, value interface{}) error {
+	pointer := reflect.ValueOf(value)
+	if pointer.Kind() != reflect.Ptr || pointer.IsNil() {
+		return inputErrorNew("non-nil pointer required")
+	}
+	return unmarshalValue(term, pointer.Elem(), "")
+}
+
+// SnakeCase converts a Go identifier (e.g., UserID) into the
+// Erlang atom name (e.g., user_id) that Marshal uses by default
+func SnakeCase(name string) string {
+	characters := []rune(name)
+	var result strings.Builder
+	for i, character := range characters {
+		if unicode.IsUpper(character) {
+			if i > 0 && (!unicode.IsUpper(characters[i-1]) ||
+				(i+1 < len(characters) && unicode.IsLower(characters[i+1]))) &&
+				characters[i-1] != '_' {
+				result.WriteByte('_')
+			}
+			character = unicode.ToLower(character)
+		}
+		result.WriteRune(character)
+	}
+	return result.String()
+}
+
+// MarshalTerm implementation functions
+
+func marshalValue(value reflect.Value) (interface{}, error) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+	if value.Type().Implements(marshalerType) {
+		if value.Kind() == reflect.Ptr && value.IsNil() {
+			return nil, nil
+		}
+		return value.Interface().(Marshaler).MarshalErlang()
+	}
+	switch term := value.Interface().(type) {
+	case OtpErlangAtom, OtpErlangAtomUTF8, OtpErlangAtomCacheRef,
+		OtpErlangBinary, OtpErlangPid, OtpErlangPort, OtpErlangReference,
+		OtpErlangFunction, RawTerm, *big.Int:
+		return term, nil
+	case OtpErlangTuple:
+		tuple := make(OtpErlangTuple, len(term))
+		for i, element := range term {
+			result, err := MarshalTerm(element)
+			if err != nil {
+				return nil, err
+			}
+			tuple[i] = result
+		}
+		return tuple, nil
+	case OtpErlangList:
+		list := make([]interface{}, len(term.Value))
+		for i, element := range term.Value {
+			result, err := MarshalTerm(element)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = result
+		}
+		return OtpErlangList{Value: list, Improper: term.Improper}, nil
+	}
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		integer := value.Int()
+		if integer >= math.MinInt32 && integer <= math.MaxInt32 {
+			return int(integer), nil
+		}
+		return integer, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		integer := value.Uint()
+		if integer <= math.MaxInt32 {
+			return int(integer), nil
+		}
+		return integer, nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Interface, reflect.Ptr:
+		if value.IsNil() {
+			return nil, nil
+		}
+		return marshalValue(value.Elem())
+	case reflect.Slice:
+		if value.IsNil() {
+			return OtpErlangList{Value: []interface{}{}}, nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return OtpErlangBinary{Value: value.Bytes(), Bits: 8}, nil
+		}
+		return marshalList(value)
+	case reflect.Array:
+		return marshalList(value)
+	case reflect.Map:
+		result := make(OtpErlangMap, value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			key, err := marshalValue(iterator.Key())
+			if err != nil {
+				return nil, err
+			}
+			if !termComparable(key) {
+				return nil, inputErrorNew("unsupported map key type " +
+					value.Type().Key().String())
+			}
+			element, err := marshalValue(iterator.Value())
+			if err != nil {
+				return nil, err
+			}
+			result[key] = element
+		}
+		return result, nil
+	case reflect.Struct:
+		fields := structFields(value.Type())
+		if name, ok := structRecord(value.Type()); ok {
+			return marshalRecord(value, name, fields)
+		}
+		result := make(OtpErlangMap, len(fields))
+		for _, field := range fields {
+			element := value.FieldByIndex(field.index)
+			if field.omitEmpty && element.IsZero() {
+				continue
+			}
+			term, err := marshalValue(element)
+			if err != nil {
+				return nil, err
+			}
+			result[OtpErlangAtom(field.name)] = term
+		}
+		return result, nil
+	default:
+		return nil, inputErrorNew("unsupported type " + value.Type().String())
+	}
+}
+
+func marshalRecord(value reflect.Value, name string, fields []structField) (interface{}, error) {
+	tuple := make(OtpErlangTuple, len(fields)+1)
+	tuple[0] = OtpErlangAtom(name)
+	for i, field := range fields {
+		term, err := marshalValue(value.FieldByIndex(field.index))
+		if err != nil {
+			return nil, &RecordError{Record: name, Field: field.name, Err: err}
+		}
+		tuple[i+1] = term
+	}
+	return tuple, nil
+}
+
+func marshalList(value reflect.Value) (interface{}, error) {
+	length := value.Len()
+	list := make([]interface{}, length)
+	for i := 0; i < length; i++ {
+		term, err := marshalValue(value.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		list[i] = term
+	}
+	return OtpErlangList{Value: list}, nil
+}
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields provides the exported fields of a struct type
+// (fields of embedded structs without a tag name are included)
+func structFields(t reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.([]structField)
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("erlang")
+		if tag == "-" || (field.Anonymous && field.Type == recordType) {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		name := options[0]
+		if field.Anonymous && len(name) == 0 &&
+			field.Type.Kind() == reflect.Struct {
+			for _, embedded := range structFields(field.Type) {
+				embedded.index = append([]int{i}, embedded.index...)
+				fields = append(fields, embedded)
+			}
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		if len(name) == 0 {
+			name = SnakeCase(field.Name)
+		}
+		f := structField{name: name, index: []int{i}}
+		for _, option := range options[1:] {
+			if option == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+		fields = append(fields, f)
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// structRecord provides the record name of a struct type that
+// embeds Record (or the tag of a registered struct type)
+func structRecord(t reflect.Type) (string, bool) {
+	if tag, ok := registeredTag(t); ok {
+		return tag, true
+	}
+	if name, ok := structRecordCache.Load(t); ok {
+		return name.(string), len(name.(string)) > 0
+	}
+	var name string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type == recordType {
+			name = strings.Split(field.Tag.Get("erlang"), ",")[0]
+			if len(name) == 0 {
+				name = SnakeCase(t.Name())
+			}
+			break
+		}
+	}
+	structRecordCache.Store(t, name)
+	return name, len(name) > 0
+}
+
+// UnmarshalTerm implementation functions
+
+func unmarshalValue(term interface{}, value reflect.Value, path string) error {
+	if value.CanAddr() && value.Addr().Type().Implements(unmarshalerType) {
+		return value.Addr().Interface().(Unmarshaler).UnmarshalErlang(term)
+	}
+	if value.Kind() == reflect.Ptr && value.Type().Implements(unmarshalerType) {
+		if term == nil {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return value.Interface().(Unmarshaler).UnmarshalErlang(term)
+	}
+	if term != nil {
+		termType := reflect.TypeOf(term)
+		if termType.AssignableTo(value.Type()) &&
+			(value.Kind() == reflect.Interface || termType != bytesType) {
+			value.Set(reflect.ValueOf(term))
+			return nil
+		}
+	}
+	switch value.Kind() {
+	case reflect.Interface:
+		if term == nil {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+	case reflect.Ptr:
+		if term == nil {
+			value.Set(reflect.Zero(value.Type()))
+			return nil
+		}
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return unmarshalValue(term, value.Elem(), path)
+	case reflect.Bool:
+		if boolean, ok := term.(bool); ok {
+			value.SetBool(boolean)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		if integer, ok := termInteger(term); ok && integer.IsInt64() {
+			i := integer.Int64()
+			if !value.OverflowInt(i) {
+				value.SetInt(i)
+				return nil
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		if integer, ok := termInteger(term); ok && integer.IsUint64() {
+			i := integer.Uint64()
+			if !value.OverflowUint(i) {
+				value.SetUint(i)
+				return nil
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		var float float64
+		ok := true
+		switch number := term.(type) {
+		case float64:
+			float = number
+		case float32:
+			float = float64(number)
+		default:
+			var integer *big.Int
+			integer, ok = termInteger(term)
+			if ok {
+				float, _ = new(big.Float).SetInt(integer).Float64()
+			}
+		}
+		if ok && !value.OverflowFloat(float) {
+			value.SetFloat(float)
+			return nil
+		}
+	case reflect.String:
+		if text, ok := termText(term); ok {
+			value.SetString(text)
+			return nil
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if data, ok := termBytes(term); ok {
+				value.SetBytes(data)
+				return nil
+			}
+		}
+		if elements, ok := termElements(term); ok {
+			slice := reflect.MakeSlice(value.Type(), len(elements), len(elements))
+			for i, element := range elements {
+				err := unmarshalValue(element, slice.Index(i),
+					path+"["+strconv.Itoa(i)+"]")
+				if err != nil {
+					return err
+				}
+			}
+			value.Set(slice)
+			return nil
+		}
+	case reflect.Array:
+		if elements, ok := termElements(term); ok && len(elements) == value.Len() {
+			for i, element := range elements {
+				err := unmarshalValue(element, value.Index(i),
+					path+"["+strconv.Itoa(i)+"]")
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.Map:
+		if pairs, ok := term.(OtpErlangMap); ok {
+			result := reflect.MakeMapWithSize(value.Type(), len(pairs))
+			keyType := value.Type().Key()
+			elementType := value.Type().Elem()
+			for keyTerm, elementTerm := range pairs {
+				key := reflect.New(keyType).Elem()
+				err := unmarshalValue(keyTerm, key, path)
+				if err != nil {
+					return err
+				}
+				element := reflect.New(elementType).Elem()
+				err = unmarshalValue(elementTerm, element,
+					path+"["+TermString(keyTerm)+"]")
+				if err != nil {
+					return err
+				}
+				result.SetMapIndex(key, element)
+			}
+			value.Set(result)
+			return nil
+		}
+	case reflect.Struct:
+		if name, ok := structRecord(value.Type()); ok && term != nil {
+			return unmarshalRecord(term, value, name, path)
+		}
+		if pairs, ok := term.(OtpErlangMap); ok {
+			return unmarshalStruct(pairs, value, path)
+		}
+	}
+	if term == nil {
+		// the undefined atom leaves the value unchanged
+		return nil
+	}
+	return &UnmarshalError{Term: TermString(term), Type: value.Type(), Path: path}
+}
+
+func unmarshalStruct(pairs OtpErlangMap, value reflect.Value, path string) error {
+	fields := structFields(value.Type())
+	for keyTerm, elementTerm := range pairs {
+		name, ok := termText(keyTerm)
+		if !ok {
+			continue
+		}
+		for _, field := range fields {
+			if field.name != name {
+				continue
+			}
+			element := value.FieldByIndex(field.index)
+			err := unmarshalValue(elementTerm, element,
+				path+"."+value.Type().FieldByIndex(field.index).Name)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func unmarshalRecord(term interface{}, value reflect.Value, name, path string) error {
+	fields := structFields(value.Type())
+	tuple, err := RecordCheck(term, name, len(fields)+1)
+	if err != nil {
+		return err
+	}
+	for i, field := range fields {
+		element := value.FieldByIndex(field.index)
+		err = unmarshalValue(tuple[i+1], element,
+			path+"."+value.Type().FieldByIndex(field.index).Name)
+		if err != nil {
+			return &RecordError{Record: name, Field: field.name, Err: err}
+		}
+	}
+	return nil
+}
+
+// termText provides the text of a string, binary, atom or
+// list of characters term
+func termText(term interface{}) (string, bool) {
+	switch text := term.(type) {
+	case string:
+		return text, true
+	case nil:
+		return "", false
+	case OtpErlangBinary:
+		if text.Bits != 8 {
+			return "", false
+		}
+		return string(text.Value), true
+	case []byte:
+		return string(text), true
+	case OtpErlangList:
+		if text.Improper {
+			return "", false
+		}
+		var result strings.Builder
+		for _, element := range text.Value {
+			character, ok := termInteger(element)
+			if !ok || !character.IsInt64() ||
+				character.Int64() < 0 || character.Int64() > utf8.MaxRune {
+				return "", false
+			}
+			result.WriteRune(rune(character.Int64()))
+		}
+		return result.String(), true
+	default:
+		return termAtomName(term)
+	}
+}
+
+// termBytes provides a copy of the data of a binary or string term
+func termBytes(term interface{}) ([]byte, bool) {
+	switch data := term.(type) {
+	case OtpErlangBinary:
+		if data.Bits != 8 {
+			return nil, false
+		}
+		return append([]byte{}, data.Value...), true
+	case []byte:
+		return append([]byte{}, data...), true
+	case string:
+		return []byte(data), true
+	default:
+		return nil, false
+	}
+}
+
+// termElements provides the elements of a proper list term
+// (a string is a list of bytes)
+func termElements(term interface{}) ([]interface{}, bool) {
+	switch list := term.(type) {
+	case OtpErlangList:
+		if list.Improper {
+			return nil, false
+		}
+		return list.Value, true
+	case string:
+		elements := make([]interface{}, len(list))
+		for i := 0; i < len(list); i++ {
+			elements[i] = list[i]
+		}
+		return elements, true
+	default:
+		return nil, false
+	}
+}
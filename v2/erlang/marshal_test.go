@@ -0,0 +1,204 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type marshalAddress struct {
+	City string
+	Zip  int `erlang:"postal_code"`
+}
+
+type marshalUser struct {
+	marshalAddress
+	UserID  int64
+	Name    string
+	Email   []byte `erlang:",omitempty"`
+	Tags    []string
+	Scores  map[string]float64
+	Manager *marshalUser
+	Ignored int `erlang:"-"`
+	Any     interface{}
+	Pid     *OtpErlangPid
+	private int
+}
+
+type marshalCelsius float64
+
+func (c marshalCelsius) MarshalErlang() (interface{}, error) {
+	return OtpErlangTuple{OtpErlangAtom("celsius"), float64(c)}, nil
+}
+
+func (c *marshalCelsius) UnmarshalErlang(term interface{}) error {
+	tuple, ok := term.(OtpErlangTuple)
+	if !ok || len(tuple) != 2 {
+		return errors.New("celsius")
+	}
+	*c = marshalCelsius(tuple[1].(float64))
+	return nil
+}
+
+func TestMarshal(t *testing.T) {
+	pid := OtpErlangPid{NodeTag: 100, Node: []byte("\x00\x0dnonode@nohost"),
+		ID: []byte{0, 0, 0, 83}, Serial: []byte{0, 0, 0, 0}, Creation: []byte{0}}
+	user := marshalUser{
+		marshalAddress: marshalAddress{City: "Boston", Zip: 2134},
+		UserID:         1 << 40,
+		Name:           "Ann",
+		Tags:           []string{"a", "b"},
+		Scores:         map[string]float64{"x": 1.5},
+		Manager:        &marshalUser{Name: "Bob", Tags: []string{}},
+		Ignored:        1,
+		Any:            OtpErlangAtom("ok"),
+		Pid:            &pid,
+	}
+	term, err := MarshalTerm(user)
+	assertEqual(t, nil, err, "")
+	pairs := term.(OtpErlangMap)
+	assertEqual(t, 9, len(pairs), "")
+	assertEqual(t, "Boston", pairs[OtpErlangAtom("city")], "")
+	assertEqual(t, 2134, pairs[OtpErlangAtom("postal_code")], "")
+	assertEqual(t, int64(1<<40), pairs[OtpErlangAtom("user_id")], "")
+	assertEqual(t, nil, pairs[OtpErlangAtom("manager")].(OtpErlangMap)[OtpErlangAtom("manager")], "")
+	assertEqual(t, OtpErlangList{Value: []interface{}{"a", "b"}}, pairs[OtpErlangAtom("tags")], "")
+
+	data, err := Marshal(user)
+	assertEqual(t, nil, err, "")
+	var result marshalUser
+	err = Unmarshal(data, &result)
+	assertEqual(t, nil, err, "")
+	user.Ignored = 0
+	user.Manager.Scores = map[string]float64{}
+	assertEqual(t, user, result, "")
+
+	// binaries and atoms are accepted as strings
+	var names []string
+	err = UnmarshalTerm(OtpErlangList{Value: []interface{}{
+		OtpErlangBinary{Value: []byte("x"), Bits: 8}, OtpErlangAtom("y"),
+		OtpErlangList{Value: []interface{}{int32(0x100)}}}}, &names)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, []string{"x", "y", "Ā"}, names, "")
+
+	var temperature marshalCelsius = 21.5
+	data, err = Marshal([]marshalCelsius{temperature})
+	assertEqual(t, nil, err, "")
+	var temperatures []marshalCelsius
+	err = Unmarshal(data, &temperatures)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, []marshalCelsius{temperature}, temperatures, "")
+	// TermToBinary encodes a Marshaler directly
+	data, err = TermToBinary(OtpErlangList{Value: []interface{}{
+		temperature, (*marshalCelsius)(nil)}}, -1)
+	assertEqual(t, nil, err, "")
+	term, err = BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangList{Value: []interface{}{
+		OtpErlangTuple{OtpErlangAtom("celsius"), 21.5}, nil}}, term, "")
+
+	_, err = MarshalTerm(make(chan int))
+	assertEqual(t, true, err != nil, "")
+}
+
+type marshalPoint struct {
+	Record `erlang:"point"`
+	X, Y   int
+}
+
+type marshalShape struct {
+	Record
+	Name   string
+	Points []marshalPoint
+	Center *marshalPoint `erlang:"center,omitempty"`
+}
+
+func TestMarshalRecord(t *testing.T) {
+	shape := marshalShape{Name: "line",
+		Points: []marshalPoint{{X: 1, Y: 2}, {X: 3, Y: -4}}}
+	term, err := MarshalTerm(shape)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{OtpErlangAtom("marshal_shape"), "line",
+		OtpErlangList{Value: []interface{}{
+			OtpErlangTuple{OtpErlangAtom("point"), 1, 2},
+			OtpErlangTuple{OtpErlangAtom("point"), 3, -4},
+		}}, nil}, term, "")
+
+	data, err := Marshal(shape)
+	assertEqual(t, nil, err, "")
+	var result marshalShape
+	err = Unmarshal(data, &result)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, shape, result, "")
+
+	var point marshalPoint
+	err = UnmarshalTerm(OtpErlangTuple{OtpErlangAtom("point"), uint8(1)}, &point)
+	assertEqual(t, "#point{}: arity 2 != 3", err.Error(), "")
+	err = UnmarshalTerm(OtpErlangTuple{OtpErlangAtom("pixel"), uint8(1), uint8(2)}, &point)
+	assertEqual(t, "#point{}: tag pixel", err.Error(), "")
+	err = UnmarshalTerm(OtpErlangTuple{OtpErlangAtomUTF8("point"), uint8(1), "y"}, &point)
+	assertEqual(t, "#point.y: cannot unmarshal \"y\" into Go value of type int at .Y",
+		err.Error(), "")
+	var recordError *RecordError
+	assertEqual(t, true, errors.As(err, &recordError), "")
+	assertEqual(t, "y", recordError.Field, "")
+	err = UnmarshalTerm(OtpErlangTuple{OtpErlangAtom("marshal_shape"), "s",
+		OtpErlangList{}, OtpErlangTuple{OtpErlangAtom("point"), "x", uint8(2)}},
+		&result)
+	assertEqual(t, "#marshal_shape.center: #point.x: cannot unmarshal \"x\" "+
+		"into Go value of type int at .Center.X", err.Error(), "")
+}
+
+func TestUnmarshalError(t *testing.T) {
+	var user marshalUser
+	err := UnmarshalTerm(OtpErlangMap{
+		OtpErlangAtom("tags"): OtpErlangList{Value: []interface{}{
+			"a", uint8(1)}}}, &user)
+	assertEqual(t, &UnmarshalError{Term: "1", Type: reflect.TypeOf(""),
+		Path: ".Tags[1]"}, err, "")
+	assertEqual(t, "cannot unmarshal 1 into Go value of type string at .Tags[1]",
+		err.Error(), "")
+
+	var small int8
+	err = UnmarshalTerm(int32(300), &small)
+	assertEqual(t, &UnmarshalError{Term: "300", Type: reflect.TypeOf(small)}, err, "")
+	var large uint64
+	value, _ := new(big.Int).SetString("18446744073709551615", 10)
+	err = UnmarshalTerm(value, &large)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, uint64(18446744073709551615), large, "")
+	err = UnmarshalTerm(int32(1), user)
+	assertEqual(t, true, err != nil, "")
+}
+
+func TestSnakeCase(t *testing.T) {
+	assertEqual(t, "user_id", SnakeCase("UserID"), "")
+	assertEqual(t, "http_server", SnakeCase("HTTPServer"), "")
+	assertEqual(t, "name", SnakeCase("Name"), "")
+	assertEqual(t, "a_b", SnakeCase("A_B"), "")
+}
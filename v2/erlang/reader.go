@@ -0,0 +1,438 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// TermReader decodes a term in the Erlang External Term Format one
+// element at a time (used by code that cmd/erlgen generates).
+// Values are decoded as BinaryToTerm provides them and accepted with
+// the same rules as UnmarshalTerm (e.g., a string accepts a binary,
+// an atom or a list of characters).
+type TermReader struct {
+	scanner termScanner
+	i       int
+	text    []byte // STRING_EXT list elements not yet read
+}
+
+// NewTermReader creates a TermReader for the data of a single term
+// (with the version tag and optional compression)
+func NewTermReader(data []byte) (*TermReader, error) {
+	if len(data) <= 1 {
+		return nil, parseErrorNew(ErrNullInput, "null input")
+	}
+	if data[0] != tagVersion {
+		return nil, parseErrorAt(parseErrorNew(ErrInvalidVersion, "invalid version"), 0, -1)
+	}
+	i := 1
+	if data[1] == tagCompressedZlib {
+		end, dataUncompressed, err := binaryToUncompressed(2, bytes.NewReader(data[2:]))
+		if err != nil {
+			return nil, parseErrorAt(err, 1, tagCompressedZlib)
+		}
+		if end != len(data) {
+			return nil, parseErrorAt(parseErrorNew(ErrUnparsedData, "unparsed data"), end, int(data[end]))
+		}
+		data = dataUncompressed
+		i = 0
+	}
+	return &TermReader{scanner: termScanner{data: data}, i: i}, nil
+}
+
+// End checks that all the data was read
+func (r *TermReader) End() error {
+	if r.text != nil {
+		return r.error(parseErrorNew(ErrInvalidData, "list not ended"))
+	}
+	if r.i != len(r.scanner.data) {
+		return r.error(parseErrorNew(ErrUnparsedData, "unparsed data"))
+	}
+	return nil
+}
+
+// Undefined reads the undefined atom if it is the next term
+// (a nil value)
+func (r *TermReader) Undefined() bool {
+	term, i, ok := r.atom()
+	if !ok || term != nil {
+		return false
+	}
+	r.i = i
+	return true
+}
+
+// Int reads an integer that fits in a signed integer of
+// bitSize bits (0 is the int size)
+func (r *TermReader) Int(bitSize int) (int64, error) {
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	integer, i, err := r.integer()
+	if err != nil {
+		return 0, err
+	}
+	if !integer.IsInt64() {
+		return 0, r.error(parseErrorNew(ErrInvalidData, "integer overflow"))
+	}
+	value := integer.Int64()
+	if bitSize < 64 && (value < -1<<(bitSize-1) || value >= 1<<(bitSize-1)) {
+		return 0, r.error(parseErrorNew(ErrInvalidData, "integer overflow"))
+	}
+	r.i = i
+	return value, nil
+}
+
+// Uint reads an integer that fits in an unsigned integer of
+// bitSize bits (0 is the uint size)
+func (r *TermReader) Uint(bitSize int) (uint64, error) {
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	integer, i, err := r.integer()
+	if err != nil {
+		return 0, err
+	}
+	if !integer.IsUint64() {
+		return 0, r.error(parseErrorNew(ErrInvalidData, "integer overflow"))
+	}
+	value := integer.Uint64()
+	if bitSize < 64 && value >= 1<<bitSize {
+		return 0, r.error(parseErrorNew(ErrInvalidData, "integer overflow"))
+	}
+	r.i = i
+	return value, nil
+}
+
+// Float reads a float (or an integer) that fits in a float of
+// bitSize bits
+func (r *TermReader) Float(bitSize int) (float64, error) {
+	term, i, err := r.next()
+	if err != nil {
+		return 0, err
+	}
+	value, ok := term.(float64)
+	if !ok {
+		var integer *big.Int
+		integer, ok = termInteger(term)
+		if !ok {
+			return 0, r.error(parseErrorNew(ErrInvalidTag, "invalid float"))
+		}
+		value, _ = new(big.Float).SetInt(integer).Float64()
+	}
+	if bitSize == 32 && math.Abs(value) > math.MaxFloat32 && !math.IsInf(value, 0) {
+		return 0, r.error(parseErrorNew(ErrInvalidData, "float overflow"))
+	}
+	r.i = i
+	return value, nil
+}
+
+// Bool reads the true or false atom
+func (r *TermReader) Bool() (bool, error) {
+	term, i, _ := r.atom()
+	value, ok := term.(bool)
+	if !ok {
+		return false, r.error(parseErrorNew(ErrInvalidTag, "invalid boolean"))
+	}
+	r.i = i
+	return value, nil
+}
+
+// String reads the text of a string, binary, atom or
+// list of characters
+func (r *TermReader) String() (string, error) {
+	if r.text != nil {
+		return "", r.error(parseErrorNew(ErrInvalidTag, "invalid string"))
+	}
+	term, i, err := r.next()
+	if err != nil {
+		return "", err
+	}
+	value, ok := termText(term)
+	if !ok {
+		return "", r.error(parseErrorNew(ErrInvalidTag, "invalid string"))
+	}
+	r.i = i
+	return value, nil
+}
+
+// Bytes reads the data of a binary, string or list of bytes
+func (r *TermReader) Bytes() ([]byte, error) {
+	if r.text != nil {
+		return nil, r.error(parseErrorNew(ErrInvalidTag, "invalid binary"))
+	}
+	term, i, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := termBytes(term)
+	if !ok {
+		var elements []interface{}
+		elements, ok = termElements(term)
+		value = make([]byte, len(elements))
+		for j := 0; ok && j < len(elements); j++ {
+			var element *big.Int
+			element, ok = termInteger(elements[j])
+			ok = ok && element.IsUint64() && element.Uint64() <= math.MaxUint8
+			if ok {
+				value[j] = uint8(element.Uint64())
+			}
+		}
+	}
+	if !ok {
+		return nil, r.error(parseErrorNew(ErrInvalidTag, "invalid binary"))
+	}
+	r.i = i
+	return value, nil
+}
+
+// TupleHeader reads the start of a tuple, providing the arity
+// of the elements that follow
+func (r *TermReader) TupleHeader() (int, error) {
+	if r.text == nil {
+		switch r.peek() {
+		case tagSmallTupleExt, tagLargeTupleExt:
+			i, arity, err := r.scanner.tupleHeader(r.i)
+			if err != nil {
+				return 0, r.error(err)
+			}
+			r.i = i
+			return arity, nil
+		}
+	}
+	return 0, r.error(parseErrorNew(ErrInvalidTag, "invalid tuple"))
+}
+
+// Tuple reads the start of a tuple with arity elements that follow
+func (r *TermReader) Tuple(arity int) error {
+	i := r.i
+	length, err := r.TupleHeader()
+	if err != nil {
+		return err
+	}
+	if length != arity {
+		r.i = i
+		return r.error(parseErrorNew(ErrInvalidData, "tuple arity "+
+			strconv.Itoa(length)+" != "+strconv.Itoa(arity)))
+	}
+	return nil
+}
+
+// Record reads the start of a record tuple, checking the record name
+// tag and arity (arity includes the tag element)
+func (r *TermReader) Record(name string, arity int) error {
+	i := r.i
+	err := r.Tuple(arity)
+	if err == nil {
+		tag, iTag, ok := r.atom()
+		if tagName, _ := termAtomName(tag); ok && tagName == name {
+			r.i = iTag
+			return nil
+		}
+		err = r.error(parseErrorNew(ErrInvalidData, "invalid record tag"))
+		r.i = i
+	}
+	return &RecordError{Record: name, Err: err}
+}
+
+// ListHeader reads the start of a proper list, providing the length
+// of the elements that follow before ListEnd
+func (r *TermReader) ListHeader() (int, error) {
+	if r.text == nil {
+		switch r.peek() {
+		case tagNilExt:
+			r.i += 1
+			r.text = []byte{}
+			return 0, nil
+		case tagStringExt:
+			length, err := r.scanner.uint16(r.i + 1)
+			if err == nil {
+				_, err = r.scanner.bytes(r.i+3, int(length))
+			}
+			if err != nil {
+				return 0, r.error(err)
+			}
+			start := r.i + 3
+			r.i = start + int(length)
+			r.text = r.scanner.data[start:r.i]
+			return int(length), nil
+		case tagListExt:
+			length, err := r.scanner.uint32(r.i + 1)
+			if err != nil {
+				return 0, r.error(err)
+			}
+			r.i += 5
+			return int(length), nil
+		}
+	}
+	return 0, r.error(parseErrorNew(ErrInvalidTag, "invalid list"))
+}
+
+// ListEnd reads the end of a proper list after its elements
+func (r *TermReader) ListEnd() error {
+	if r.text != nil {
+		if len(r.text) > 0 {
+			return r.error(parseErrorNew(ErrInvalidData, "list elements not read"))
+		}
+		r.text = nil
+		return nil
+	}
+	if r.peek() != tagNilExt {
+		return r.error(parseErrorNew(ErrInvalidData, "improper list"))
+	}
+	r.i += 1
+	return nil
+}
+
+// MapHeader reads the start of a map, providing the size
+// of the key/value pairs that follow
+func (r *TermReader) MapHeader() (int, error) {
+	if r.text == nil && r.peek() == tagMapExt {
+		size, err := r.scanner.uint32(r.i + 1)
+		if err != nil {
+			return 0, r.error(err)
+		}
+		r.i += 5
+		return int(size), nil
+	}
+	return 0, r.error(parseErrorNew(ErrInvalidTag, "invalid map"))
+}
+
+// Key reads a map key that is a struct field name
+// (a key that is not text is skipped and provides an empty name)
+func (r *TermReader) Key() (string, error) {
+	i := r.i
+	name, err := r.String()
+	if err != nil {
+		r.i = i
+		return "", r.Skip()
+	}
+	return name, nil
+}
+
+// Term reads any term as BinaryToTerm provides it
+func (r *TermReader) Term() (interface{}, error) {
+	term, i, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	r.i = i
+	return term, nil
+}
+
+// Skip reads any term without decoding it
+func (r *TermReader) Skip() error {
+	if r.text != nil {
+		_, _, err := r.next()
+		return err
+	}
+	i, err := r.scanner.skip(r.i)
+	if err != nil {
+		return err
+	}
+	r.i = i
+	return nil
+}
+
+// TermReader implementation functions
+
+func (r *TermReader) peek() uint8 {
+	if r.i < len(r.scanner.data) {
+		return r.scanner.data[r.i]
+	}
+	return 0
+}
+
+func (r *TermReader) error(err error) error {
+	tag := -1
+	if r.i < len(r.scanner.data) {
+		tag = int(r.scanner.data[r.i])
+	}
+	return parseErrorAt(err, r.i, tag)
+}
+
+// next decodes the next term as BinaryToTerm provides it and
+// the index after it (a STRING_EXT list element is consumed immediately)
+func (r *TermReader) next() (interface{}, int, error) {
+	if r.text != nil {
+		if len(r.text) == 0 {
+			return nil, r.i, r.error(parseErrorNew(ErrInvalidData, "list length exceeded"))
+		}
+		value := r.text[0]
+		r.text = r.text[1:]
+		return value, r.i, nil
+	}
+	return r.decode(len(r.scanner.data))
+}
+
+// atom decodes the next term if it is an atom
+// (nil for undefined and a bool for true or false)
+func (r *TermReader) atom() (interface{}, int, bool) {
+	if r.text != nil {
+		return nil, r.i, false
+	}
+	end, err := r.scanner.skipAtom(r.i)
+	if err != nil {
+		return nil, r.i, false
+	}
+	term, _, err := r.decode(end)
+	if err != nil {
+		return nil, r.i, false
+	}
+	return term, end, true
+}
+
+// decode decodes the term at the current index within data[:end]
+// (binaryToTerms reads some data at absolute offsets)
+func (r *TermReader) decode(end int) (interface{}, int, error) {
+	reader := bytes.NewReader(r.scanner.data[:end])
+	_, err := reader.Seek(int64(r.i), io.SeekStart)
+	if err != nil {
+		return nil, r.i, err
+	}
+	i, term, err := binaryToTerms(r.i, reader)
+	if err != nil {
+		return nil, r.i, err
+	}
+	return term, i, nil
+}
+
+// integer decodes the next term if it is an integer
+func (r *TermReader) integer() (*big.Int, int, error) {
+	term, i, err := r.next()
+	if err != nil {
+		return nil, r.i, err
+	}
+	integer, ok := termInteger(term)
+	if !ok {
+		return nil, r.i, r.error(parseErrorNew(ErrInvalidTag, "invalid integer"))
+	}
+	return integer, i, nil
+}
@@ -0,0 +1,171 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestTermReader(t *testing.T) {
+	data, err := TermToBinary(OtpErlangTuple{
+		uint8(1), int32(-2), new(big.Int).SetUint64(math.MaxUint64),
+		1.5, uint8(2), true, nil,
+		OtpErlangAtom("atom"), []byte("binary"), "text",
+		OtpErlangList{Value: []interface{}{int32(1024), uint8('a')}},
+		"\x01\x02",
+		OtpErlangMap{OtpErlangAtom("key"): OtpErlangList{}},
+		OtpErlangTuple{uint8(1), "a"},
+		OtpErlangMap{uint8(1): uint8(2)},
+	}, 6)
+	assertEqual(t, nil, err, "")
+	r, err := NewTermReader(data)
+	assertEqual(t, nil, err, "")
+	arity, err := r.TupleHeader()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 15, arity, "")
+	integer, err := r.Int(8)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, int64(1), integer, "")
+	_, err = r.Uint(0)
+	assertEqual(t, true, errors.Is(err, ErrInvalidData), "")
+	integer, err = r.Int(0)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, int64(-2), integer, "")
+	_, err = r.Int(64)
+	assertEqual(t, "integer overflow: offset 9, tag 110 (SMALL_BIG_EXT)", err.Error(), "")
+	unsigned, err := r.Uint(64)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, uint64(math.MaxUint64), unsigned, "")
+	float, err := r.Float(64)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 1.5, float, "")
+	float, err = r.Float(32)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 2.0, float, "")
+	assertEqual(t, false, r.Undefined(), "")
+	boolean, err := r.Bool()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, true, boolean, "")
+	assertEqual(t, true, r.Undefined(), "")
+	text, err := r.String()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "atom", text, "")
+	binary, err := r.Bytes()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, []byte("binary"), binary, "")
+	text, err = r.String()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "text", text, "")
+	text, err = r.String()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "Ѐa", text, "")
+
+	// a STRING_EXT list read as integers
+	length, err := r.ListHeader()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 2, length, "")
+	_, err = r.String()
+	assertEqual(t, true, errors.Is(err, ErrInvalidTag), "")
+	assertEqual(t, ErrInvalidData, r.End().(*ParseError).Err, "")
+	integer, err = r.Int(8)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, int64(1), integer, "")
+	term, err := r.Term()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, uint8(2), term, "")
+	assertEqual(t, nil, r.ListEnd(), "")
+
+	size, err := r.MapHeader()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 1, size, "")
+	key, err := r.Key()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "key", key, "")
+	length, err = r.ListHeader()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 0, length, "")
+	assertEqual(t, nil, r.ListEnd(), "")
+
+	term, err = r.Term()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{uint8(1), "a"}, term, "")
+	size, err = r.MapHeader()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 1, size, "")
+	key, err = r.Key()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "", key, "")
+	assertEqual(t, nil, r.Skip(), "")
+	assertEqual(t, nil, r.End(), "")
+
+	_, err = NewTermReader([]byte{tagVersion})
+	assertEqual(t, ErrNullInput, err.(*ParseError).Err, "")
+	r, err = NewTermReader([]byte{tagVersion, tagNilExt, tagNilExt})
+	assertEqual(t, nil, err, "")
+	_, err = r.Bytes()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, ErrUnparsedData, r.End().(*ParseError).Err, "")
+	_, err = r.TupleHeader()
+	assertEqual(t, "invalid tuple: offset 2, tag 106 (NIL_EXT)", err.Error(), "")
+
+	// terms after the first are decoded at their offset
+	bignum := new(big.Int).SetUint64(math.MaxUint64)
+	data, err = TermToBinary(OtpErlangTuple{uint8(1), bignum}, -1)
+	assertEqual(t, nil, err, "")
+	r, err = NewTermReader(data)
+	assertEqual(t, nil, err, "")
+	_, err = r.TupleHeader()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, r.Skip(), "")
+	term, err = r.Term()
+	assertEqual(t, nil, err, "")
+	assertEqual(t, bignum, term, "")
+	assertEqual(t, nil, r.End(), "")
+}
+
+func TestTermReaderRecord(t *testing.T) {
+	data, err := TermToBinary(OtpErlangTuple{
+		OtpErlangAtom("point"), uint8(1), uint8(2)}, -1)
+	assertEqual(t, nil, err, "")
+	r, err := NewTermReader(data)
+	assertEqual(t, nil, err, "")
+	err = r.Tuple(2)
+	assertEqual(t, "tuple arity 3 != 2: offset 1, tag 104 (SMALL_TUPLE_EXT)", err.Error(), "")
+	err = r.Record("user", 3)
+	assertEqual(t, "#user{}: invalid record tag: offset 3, tag 115 (SMALL_ATOM_EXT)", err.Error(), "")
+	var recordError *RecordError
+	assertEqual(t, true, errors.As(err, &recordError), "")
+	assertEqual(t, true, errors.Is(err, ErrInvalidData), "")
+	assertEqual(t, nil, r.Record("point", 3), "")
+	for _, expect := range []int64{1, 2} {
+		integer, err := r.Int(0)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, expect, integer, "")
+	}
+	assertEqual(t, nil, r.End(), "")
+}
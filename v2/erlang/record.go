@@ -0,0 +1,91 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"strconv"
+)
+
+// Record marks a struct as an Erlang record when it is embedded
+// (the struct field tag provides the record name and the default is
+// the struct name in snake_case), e.g.:
+//
+//	type User struct {
+//		erlang.Record `erlang:"user"`
+//		Name          string
+//		Age           int
+//	}
+//
+// MarshalTerm provides {user, Name, Age} with the fields in declaration
+// order and UnmarshalTerm checks the record name tag and arity
+type Record struct{}
+
+// RecordError describes a record tuple that does not match the record
+type RecordError struct {
+	Record string // record name
+	Field  string // field name (empty if the tuple did not match)
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	if len(e.Field) == 0 {
+		return "#" + e.Record + "{}: " + e.Err.Error()
+	}
+	return "#" + e.Record + "." + e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap provides the cause of the error
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// RecordCheck checks the record name tag and arity of a
+// record tuple (arity includes the tag element)
+func RecordCheck(term interface{}, name string, arity int) (OtpErlangTuple, error) {
+	tuple, ok := term.(OtpErlangTuple)
+	if !ok {
+		return nil, &RecordError{Record: name,
+			Err: inputErrorNew("not a tuple: " + TermString(term))}
+	}
+	if len(tuple) != arity {
+		return nil, &RecordError{Record: name,
+			Err: inputErrorNew("arity " + strconv.Itoa(len(tuple)) +
+				" != " + strconv.Itoa(arity))}
+	}
+	var tag string
+	switch atom := tuple[0].(type) {
+	case OtpErlangAtom:
+		tag = string(atom)
+	case OtpErlangAtomUTF8:
+		tag = string(atom)
+	default:
+		tag = TermString(tuple[0])
+	}
+	if tag != name {
+		return nil, &RecordError{Record: name,
+			Err: inputErrorNew("tag " + tag)}
+	}
+	return tuple, nil
+}
@@ -0,0 +1,53 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"testing"
+)
+
+func TestRecordCheck(t *testing.T) {
+	point := OtpErlangTuple{OtpErlangAtom("point"), uint8(1), uint8(2)}
+	tuple, err := RecordCheck(point, "point", 3)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, point, tuple, "")
+	_, err = RecordCheck(OtpErlangTuple{OtpErlangAtomUTF8("point"),
+		uint8(1), uint8(2)}, "point", 3)
+	assertEqual(t, nil, err, "")
+
+	_, err = RecordCheck(point, "point", 4)
+	assertEqual(t, "#point{}: arity 3 != 4", err.Error(), "")
+	_, err = RecordCheck(point, "user", 3)
+	assertEqual(t, "#user{}: tag point", err.Error(), "")
+	_, err = RecordCheck([]byte("point"), "point", 3)
+	assertEqual(t, "#point{}: not a tuple: <<\"point\">>", err.Error(), "")
+	var recordError *RecordError
+	assertEqual(t, true, errors.As(err, &recordError), "")
+	assertEqual(t, "point", recordError.Record, "")
+
+	err = &RecordError{Record: "point", Field: "x", Err: errors.New("bad")}
+	assertEqual(t, "#point.x: bad", err.Error(), "")
+}
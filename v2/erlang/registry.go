@@ -0,0 +1,169 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// tupleRegistry is replaced (not modified) when a type is registered
+type tupleRegistry struct {
+	tags  map[string]reflect.Type
+	types map[reflect.Type]string
+}
+
+var (
+	registryMutex sync.Mutex
+	registry      atomic.Value // *tupleRegistry
+)
+
+// RegisterTuple registers the Go type of the value (a struct or a
+// pointer to a struct) for tuples with the atom tag, like gob.Register.
+// BinaryToTerm provides a tuple {tag, Field1, ...} with the registered
+// arity as the Go type (anywhere in the term, unless the tuple elements
+// do not convert to the struct fields) and TermToBinary encodes
+// the Go type as the tuple, with the struct fields in declaration order
+// (like a struct that embeds Record).
+// RegisterTuple panics if the tag or the type is already registered
+// differently.
+func RegisterTuple(tag string, value interface{}) {
+	t := reflect.TypeOf(value)
+	structType := t
+	if structType != nil && structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if len(tag) == 0 {
+		panic("erlang: RegisterTuple with an empty tag")
+	}
+	if structType == nil || structType.Kind() != reflect.Struct {
+		panic("erlang: RegisterTuple with a non-struct type")
+	}
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	current := tupleRegistryGet()
+	if registered, ok := current.tags[tag]; ok {
+		if registered == t {
+			return
+		}
+		panic("erlang: RegisterTuple duplicate tag " + tag +
+			" for type " + t.String())
+	}
+	if registered, ok := current.types[structType]; ok {
+		panic("erlang: RegisterTuple duplicate type " + t.String() +
+			" for tag " + registered)
+	}
+	next := &tupleRegistry{
+		tags:  make(map[string]reflect.Type, len(current.tags)+1),
+		types: make(map[reflect.Type]string, len(current.types)+1),
+	}
+	for key, value := range current.tags {
+		next.tags[key] = value
+	}
+	for key, value := range current.types {
+		next.types[key] = value
+	}
+	next.tags[tag] = t
+	next.types[structType] = tag
+	registry.Store(next)
+}
+
+func tupleRegistryGet() *tupleRegistry {
+	current, ok := registry.Load().(*tupleRegistry)
+	if !ok {
+		return &tupleRegistry{}
+	}
+	return current
+}
+
+// registeredTag provides the tag of a registered struct type
+func registeredTag(t reflect.Type) (string, bool) {
+	current, ok := registry.Load().(*tupleRegistry)
+	if !ok {
+		return "", false
+	}
+	tag, ok := current.types[t]
+	return tag, ok
+}
+
+// registeredTuple provides the registered Go value of a tuple
+// (ok is false if the tuple tag and arity are not registered or
+// the tuple elements do not convert to the struct fields,
+// so the tuple is kept as an OtpErlangTuple)
+func registeredTuple(tuple OtpErlangTuple) (interface{}, bool) {
+	current, ok := registry.Load().(*tupleRegistry)
+	if !ok || len(tuple) == 0 {
+		return nil, false
+	}
+	tag, ok := termAtomName(tuple[0])
+	if !ok {
+		return nil, false
+	}
+	t, ok := current.tags[tag]
+	if !ok {
+		return nil, false
+	}
+	structType := t
+	if t.Kind() == reflect.Ptr {
+		structType = t.Elem()
+	}
+	if len(structFields(structType))+1 != len(tuple) {
+		return nil, false
+	}
+	value := reflect.New(structType)
+	err := unmarshalRecord(tuple, value.Elem(), tag, "")
+	if err != nil {
+		return nil, false
+	}
+	if t.Kind() == reflect.Ptr {
+		return value.Interface(), true
+	}
+	return value.Elem().Interface(), true
+}
+
+// registeredTerm provides the tuple of a registered Go value
+// (ok is false if the Go type is not registered)
+func registeredTerm(term interface{}) (interface{}, bool, error) {
+	if _, ok := registry.Load().(*tupleRegistry); !ok {
+		return nil, false, nil
+	}
+	value := reflect.ValueOf(term)
+	if value.Kind() == reflect.Ptr {
+		if _, ok := registeredTag(value.Type().Elem()); !ok {
+			return nil, false, nil
+		}
+		if value.IsNil() {
+			return nil, true, nil
+		}
+		value = value.Elem()
+	}
+	tag, ok := registeredTag(value.Type())
+	if !ok {
+		return nil, false, nil
+	}
+	tuple, err := marshalRecord(value, tag, structFields(value.Type()))
+	return tuple, true, err
+}
@@ -0,0 +1,142 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"testing"
+)
+
+type registryPoint struct {
+	X, Y int
+}
+
+type registryShape struct {
+	Name   string
+	Points []registryPoint
+	Labels map[string]interface{}
+}
+
+type registryAny struct {
+	Value interface{}
+}
+
+type registryNode struct {
+	Value int
+	Next  *registryNode
+}
+
+func TestRegisterTuple(t *testing.T) {
+	RegisterTuple("registry_point", registryPoint{})
+	RegisterTuple("registry_point", registryPoint{})
+	RegisterTuple("registry_shape", registryShape{})
+	RegisterTuple("registry_node", &registryNode{})
+
+	data, err := TermToBinary(OtpErlangList{Value: []interface{}{
+		OtpErlangTuple{OtpErlangAtom("registry_point"), uint8(1), int32(-2)},
+		OtpErlangTuple{OtpErlangAtom("registry_point"), uint8(1)},
+		OtpErlangMap{OtpErlangAtom("key"): OtpErlangTuple{
+			OtpErlangAtomUTF8("registry_point"), uint8(3), uint8(4)}},
+		OtpErlangTuple{OtpErlangAtom("registry_node"), uint8(1),
+			OtpErlangTuple{OtpErlangAtom("registry_node"), uint8(2), nil}},
+	}}, -1)
+	assertEqual(t, nil, err, "")
+	term, err := BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangList{Value: []interface{}{
+		registryPoint{X: 1, Y: -2},
+		// a different arity is not the registered tuple
+		OtpErlangTuple{OtpErlangAtom("registry_point"), uint8(1)},
+		OtpErlangMap{OtpErlangAtom("key"): registryPoint{X: 3, Y: 4}},
+		&registryNode{Value: 1, Next: &registryNode{Value: 2}},
+	}}, term, "")
+
+	shape := registryShape{Name: "line",
+		Points: []registryPoint{{X: 1, Y: 2}, {X: 3, Y: 4}},
+		Labels: map[string]interface{}{"origin": registryPoint{}}}
+	data, err = TermToBinary(shape, -1)
+	assertEqual(t, nil, err, "")
+	term, err = BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, shape, term, "")
+	assertEqual(t, `{registry_shape,"line",[{registry_point,1,2},`+
+		`{registry_point,3,4}],#{"origin" => {registry_point,0,0}}}`,
+		TermString(term), "")
+	data, err = TermToBinary([]interface{}{(*registryNode)(nil)}, -1)
+	assertEqual(t, nil, err, "")
+	term, err = BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{nil}, term, "")
+
+	// an unrelated tuple with the same tag and arity is not converted
+	data, err = TermToBinary([]interface{}{
+		OtpErlangTuple{OtpErlangAtom("registry_point"), uint8(1), "y"},
+		OtpErlangTuple{OtpErlangAtom("registry_point"), uint8(1), uint8(2)},
+	}, -1)
+	assertEqual(t, nil, err, "")
+	term, err = BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{
+		OtpErlangTuple{OtpErlangAtom("registry_point"), uint8(1), "y"},
+		registryPoint{X: 1, Y: 2},
+	}, term, "")
+
+	// a registered struct map key needs comparable field values
+	RegisterTuple("registry_any", registryAny{})
+	term, err = BinaryToTerm([]byte("\x83t\x00\x00\x00\x02" +
+		"h\x02w\x0cregistry_anya\x01a\x02w\x09undefineda\x03"))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangMap{registryAny{Value: uint8(1)}: uint8(2),
+		nil: uint8(3)}, term, "")
+	_, err = BinaryToTerm([]byte("\x83t\x00\x00\x00\x01" +
+		"h\x02w\x0cregistry_anyl\x00\x00\x00\x01a\x01ja\x02"))
+	assertEqual(t, true, errors.Is(err, ErrInvalidData), "")
+	assertEqual(t, "map key not comparable: offset 6, tag 104 (SMALL_TUPLE_EXT), path mapkey[0]",
+		err.Error(), "")
+
+	var point registryPoint
+	err = UnmarshalTerm(OtpErlangTuple{
+		OtpErlangAtom("registry_point"), uint8(5), uint8(6)}, &point)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, registryPoint{X: 5, Y: 6}, point, "")
+	term, err = MarshalTerm(point)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{OtpErlangAtom("registry_point"), 5, 6}, term, "")
+
+	assertPanic(t, func() { RegisterTuple("registry_point", registryShape{}) })
+	assertPanic(t, func() { RegisterTuple("other", registryPoint{}) })
+	assertPanic(t, func() { RegisterTuple("", registryPoint{}) })
+	assertPanic(t, func() { RegisterTuple("integer", 1) })
+}
+
+func assertPanic(t *testing.T, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("no panic")
+		}
+	}()
+	f()
+}
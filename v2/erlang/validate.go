@@ -0,0 +1,140 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"bytes"
+)
+
+// DepthDefault is the Depth limit used when Limits.Depth is 0
+// (decoding is recursive, so the nesting is always limited unless
+// Limits.Depth is negative)
+const DepthDefault = 1024
+
+var limitsDefault Limits // Change with SetLimits
+
+// Limits restricts the Erlang External Term Format data that is valid
+// (a limit that is not positive is unlimited, except Depth which uses
+// DepthDefault when it is 0)
+type Limits struct {
+	Size             int // data bytes, including the version tag
+	SizeUncompressed int // data bytes after decompression
+	Depth            int // nesting of tuples, lists, maps and functions
+	AtomLength       int // atom bytes
+	BinarySize       int // binary bytes
+	BignumSize       int // bignum bytes
+	ListLength       int // list elements
+	MapSize          int // map pairs
+	TupleArity       int // tuple elements
+}
+
+// SetLimits assigns the limits that Validate checks
+func SetLimits(value Limits) {
+	limitsDefault = value
+}
+
+// Validate checks that the data is a single Erlang External Term Format
+// term within the limits (assigned with SetLimits) without decoding it
+func Validate(data []byte) error {
+	return ValidateLimits(data, limitsDefault)
+}
+
+// ValidateLimits checks that the data is a single Erlang External Term Format
+// term within the limits provided without decoding it
+func ValidateLimits(data []byte, limits Limits) error {
+	_, err := validateLimits(data, limits)
+	return err
+}
+
+// BinaryToTermLimits decodes the Erlang External Term Format into Go types
+// after ValidateLimits checks the data (compressed data is only
+// decompressed once)
+func BinaryToTermLimits(data []byte, limits Limits) (interface{}, error) {
+	dataUncompressed, err := validateLimits(data, limits)
+	if err != nil {
+		return nil, err
+	}
+	if dataUncompressed == nil {
+		return BinaryToTerm(data)
+	}
+	_, term, err := binaryToTerms(0, bytes.NewReader(dataUncompressed))
+	if err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+// validateLimits provides the decompressed data (without the version tag)
+// if the data is compressed
+func validateLimits(data []byte, limits Limits) ([]byte, error) {
+	if limits.Depth == 0 {
+		limits.Depth = DepthDefault
+	}
+	size := len(data)
+	if size <= 1 {
+		return nil, parseErrorNew(ErrNullInput, "null input")
+	}
+	if limits.Size > 0 && size > limits.Size {
+		return nil, parseErrorNew(ErrLimit, "size limit exceeded")
+	}
+	if data[0] != tagVersion {
+		return nil, parseErrorNew(ErrInvalidVersion, "invalid version")
+	}
+	i := 1
+	var dataUncompressed []byte
+	if data[1] == tagCompressedZlib {
+		scanner := &termScanner{data: data}
+		sizeUncompressed, err := scanner.uint32(2)
+		if err != nil {
+			return nil, err
+		}
+		if limits.SizeUncompressed > 0 &&
+			uint64(sizeUncompressed) > uint64(limits.SizeUncompressed) {
+			return nil, parseErrorNew(ErrLimit, "size uncompressed limit exceeded")
+		}
+		var end int
+		end, dataUncompressed, err = binaryToUncompressed(2, bytes.NewReader(data[2:]))
+		if err != nil {
+			return nil, err
+		}
+		if end != size {
+			return nil, parseErrorNew(ErrUnparsedData, "unparsed data")
+		}
+		data = dataUncompressed
+		size = len(data)
+		i = 0
+	}
+	scanner := &termScanner{data: data, limits: &limits, validate: true}
+	end, err := scanner.skip(i)
+	if err != nil {
+		return nil, err
+	}
+	if end != size {
+		return nil, parseErrorNew(ErrUnparsedData, "unparsed data")
+	}
+	return dataUncompressed, nil
+}
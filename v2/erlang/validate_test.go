@@ -0,0 +1,109 @@
+package erlang
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"strings"
+	"testing"
+)
+
+func assertValidateError(t *testing.T, expectedError, b string, limits Limits) {
+	err := ValidateLimits([]byte(b), limits)
+	if err == nil {
+		t.Fatalf("no error to compare with \"%s\"", expectedError)
+	}
+	assertEqual(t, expectedError, errorMessage(err), "")
+}
+
+func TestValidate(t *testing.T) {
+	n := 256
+	binaries := []string{
+		"\x83j",
+		"\x83k\x00\x04test",
+		"\x83l\x00\x00\x00\x01jv\x00\x04tail",
+		"\x83t\x00\x00\x00\x01d\x00\x01aa\x01",
+		"\x83Z\x00\x03d\x00\rnonode@nohost\x00\x00\x00\x00\x00\x01\xac\x03\xc7\x00\x00\x04\xbb\xb2\xca\xee",
+		"\x83P\x00\x00\x00\x17\x78\xda\xcb\x66\x10\x49\xc1\x02\x00\x5d\x60\x08\x50",
+		encode(t, OtpErlangList{Value: listOfLargeTuples(n)}, 1),
+	}
+	for _, binary := range binaries {
+		assertEqual(t, nil, Validate([]byte(binary)), "")
+	}
+	assertValidateError(t, "null input", "\x83", Limits{})
+	assertValidateError(t, "invalid version", "\x82j", Limits{})
+	assertValidateError(t, "invalid tag", "\x83z", Limits{})
+	assertValidateError(t, "EOF", "\x83h\x01", Limits{})
+	assertValidateError(t, "unexpected EOF", "\x83m\x00\x00\x00\x04dat", Limits{})
+	assertValidateError(t, "unparsed data", "\x83jj", Limits{})
+	assertValidateError(t, "invalid bits", "\x83M\x00\x00\x00\x01\x09\xA8", Limits{})
+	assertValidateError(t, "invalid bignum sign", "\x83n\x01\x02\x01", Limits{})
+	assertValidateError(t, "invalid atom utf8", "\x83w\x01\xff", Limits{})
+	assertValidateError(t, "invalid tag", "\x83h\x01P\x00\x00\x00\x01", Limits{})
+}
+func TestBinaryToTermLimits(t *testing.T) {
+	compressed := "\x83P\x00\x00\x00\x17\x78\xda\xcb\x66\x10\x49\xc1\x02\x00\x5d\x60\x08\x50"
+	term, err := BinaryToTermLimits([]byte(compressed), Limits{ListLength: 20})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, decode(t, compressed), term, "")
+	term, err = BinaryToTermLimits([]byte("\x83h\x01a\x01"), Limits{TupleArity: 1})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, OtpErlangTuple{uint8(1)}, term, "")
+	_, err = BinaryToTermLimits([]byte(compressed), Limits{ListLength: 19})
+	assertEqual(t, "list length limit exceeded", errorMessage(err), "")
+}
+
+func TestValidateLimits(t *testing.T) {
+	assertValidateError(t, "size limit exceeded", "\x83k\x00\x04test", Limits{Size: 7})
+	assertEqual(t, nil, ValidateLimits([]byte("\x83k\x00\x04test"), Limits{Size: 8}), "")
+	compressed := "\x83P\x00\x00\x00\x17\x78\xda\xcb\x66\x10\x49\xc1\x02\x00\x5d\x60\x08\x50"
+	assertValidateError(t, "size uncompressed limit exceeded", compressed, Limits{SizeUncompressed: 22})
+	assertEqual(t, nil, ValidateLimits([]byte(compressed), Limits{SizeUncompressed: 23}), "")
+	// the stored uncompressed size does not determine the allocation
+	assertValidateError(t, "compression corrupt", "\x83P\xff\xff\xff\xff"+compressed[6:], Limits{})
+	assertValidateError(t, "depth limit exceeded", "\x83h\x01l\x00\x00\x00\x01h\x00j", Limits{Depth: 2})
+	assertEqual(t, nil, ValidateLimits([]byte("\x83h\x01l\x00\x00\x00\x01h\x00j"), Limits{Depth: 3}), "")
+	// the zero value still limits the depth, unless it is negative
+	nested := "\x83" + strings.Repeat("h\x01", DepthDefault) + "j"
+	assertEqual(t, nil, ValidateLimits([]byte(nested), Limits{}), "")
+	assertValidateError(t, "depth limit exceeded", "\x83h\x01"+nested[1:], Limits{})
+	assertEqual(t, nil, ValidateLimits([]byte("\x83h\x01"+nested[1:]), Limits{Depth: -1}), "")
+	assertValidateError(t, "atom length limit exceeded", "\x83w\x04test", Limits{AtomLength: 3})
+	assertValidateError(t, "atom length limit exceeded", "\x83v\x00\x04test", Limits{AtomLength: 3})
+	assertValidateError(t, "binary size limit exceeded", "\x83m\x00\x00\x00\x04data", Limits{BinarySize: 3})
+	assertValidateError(t, "bignum size limit exceeded", "\x83n\x06\x00\x01\x02\x03\x04\x05\x06", Limits{BignumSize: 5})
+	assertValidateError(t, "list length limit exceeded", "\x83k\x00\x04test", Limits{ListLength: 3})
+	assertValidateError(t, "list length limit exceeded", "\x83l\x00\x00\x00\x02jjj", Limits{ListLength: 1})
+	assertValidateError(t, "map size limit exceeded", "\x83t\x00\x00\x00\x02a\x01a\x01a\x02a\x02", Limits{MapSize: 1})
+	assertValidateError(t, "tuple arity limit exceeded", "\x83h\x02jj", Limits{TupleArity: 1})
+	assertValidateError(t, "tuple arity limit exceeded", "\x83i\x00\x00\x00\x02jj", Limits{TupleArity: 1})
+	// a large declared length is rejected before reading the data
+	assertValidateError(t, "binary size limit exceeded", "\x83m\xff\xff\xff\xff", Limits{BinarySize: 1024})
+	SetLimits(Limits{AtomLength: 255})
+	defer SetLimits(Limits{})
+	assertEqual(t, nil, Validate([]byte("\x83w\xff"+strings.Repeat("X", 255))), "")
+	assertEqual(t, "atom length limit exceeded", errorMessage(Validate([]byte("\x83v\x01\x00"+strings.Repeat("X", 256)))), "")
+}
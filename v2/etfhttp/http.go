@@ -0,0 +1,212 @@
+// Package etfhttp provides net/http support for application/x-erlang-binary
+package etfhttp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// ContentType is the media type of term_to_binary data
+const ContentType = "application/x-erlang-binary"
+
+// SizeDefault is the body size limit if Limits.Size is not set
+const SizeDefault = 16 * 1024 * 1024
+
+// ErrContentType is returned when the content type is not ContentType
+var ErrContentType = errors.New("content type is not " + ContentType)
+
+// StatusError is returned when a response status is not 2xx
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "http status " + e.Status
+}
+
+// DecodeRequest decodes the request body into a term
+// (the request body is validated with the limits before decoding,
+// with SizeDefault if Limits.Size is not set)
+func DecodeRequest(r *http.Request, limits erlang.Limits) (interface{}, error) {
+	return decode(r.Header, r.Body, limits)
+}
+
+// DecodeResponse decodes the response body into a term
+// (the response body is validated with the limits before decoding,
+// with SizeDefault if Limits.Size is not set)
+func DecodeResponse(resp *http.Response, limits erlang.Limits) (interface{}, error) {
+	return decode(resp.Header, resp.Body, limits)
+}
+
+// Encode provides the term_to_binary data of a Go value
+// (see erlang.MarshalTerm) with the compression level (-1 is none)
+func Encode(value interface{}, compressed int) ([]byte, error) {
+	term, err := erlang.MarshalTerm(value)
+	if err != nil {
+		return nil, err
+	}
+	return erlang.TermToBinary(term, compressed)
+}
+
+// WriteResponse writes a Go value as a ContentType response
+func WriteResponse(w http.ResponseWriter, status int, value interface{}, compressed int) error {
+	data, err := Encode(value, compressed)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// NewRequest creates a ContentType request with a Go value as the body
+// (a nil value provides no body)
+func NewRequest(method, url string, value interface{}, compressed int) (*http.Request, error) {
+	var body io.Reader
+	if value != nil {
+		data, err := Encode(value, compressed)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	r, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", ContentType)
+	}
+	r.Header.Set("Accept", ContentType)
+	return r, nil
+}
+
+// Handler is an http.Handler that decodes the ContentType request body
+// and encodes the result as the ContentType response body
+type Handler struct {
+	Limits     erlang.Limits
+	Compressed int // -1 is no compression
+	Handle     func(r *http.Request, request interface{}) (interface{}, error)
+}
+
+// NewHandler creates a Handler with the SizeDefault limit and without compression
+func NewHandler(handle func(r *http.Request, request interface{}) (interface{}, error)) *Handler {
+	return &Handler{Compressed: -1, Handle: handle}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := DecodeRequest(r, h.Limits)
+	if err != nil {
+		switch {
+		case err == ErrContentType:
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, erlang.ErrLimit):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	response, err := h.Handle(r, request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = WriteResponse(w, http.StatusOK, response, h.Compressed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Client sends ContentType requests with an http.Client
+// (any http.RoundTripper may be used as the http.Client Transport)
+type Client struct {
+	HTTP       *http.Client // nil uses http.DefaultClient
+	Limits     erlang.Limits
+	Compressed int // -1 is no compression
+}
+
+// NewClient creates a Client with the SizeDefault limit and without compression
+func NewClient(client *http.Client) *Client {
+	return &Client{HTTP: client, Compressed: -1}
+}
+
+// Do sends the request Go value and stores the response term in the
+// Go value pointed to by response (see erlang.UnmarshalTerm),
+// if response is not nil
+func (c *Client) Do(method, url string, request, response interface{}) error {
+	r, err := NewRequest(method, url, request, c.Compressed)
+	if err != nil {
+		return err
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		io.Copy(io.Discard, resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if response == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	term, err := DecodeResponse(resp, c.Limits)
+	if err != nil {
+		return err
+	}
+	return erlang.UnmarshalTerm(term, response)
+}
+
+func decode(header http.Header, body io.Reader, limits erlang.Limits) (interface{}, error) {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType != ContentType {
+		return nil, ErrContentType
+	}
+	if limits.Size <= 0 {
+		limits.Size = SizeDefault
+	}
+	data, err := io.ReadAll(io.LimitReader(body, int64(limits.Size)+1))
+	if err != nil {
+		return nil, err
+	}
+	return erlang.BinaryToTermLimits(data, limits)
+}
@@ -0,0 +1,126 @@
+package etfhttp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+type greeting struct {
+	Name  string
+	Count int
+}
+
+func TestHandlerClient(t *testing.T) {
+	handler := NewHandler(func(r *http.Request, request interface{}) (interface{}, error) {
+		var value greeting
+		err := erlang.UnmarshalTerm(request, &value)
+		if err != nil {
+			return nil, err
+		}
+		if value.Count < 0 {
+			return nil, errors.New("negative count")
+		}
+		return greeting{Name: "hello " + value.Name, Count: value.Count + 1}, nil
+	})
+	handler.Limits = erlang.Limits{Size: 64, ListLength: 8}
+	handler.Compressed = 6
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	var response greeting
+	err := client.Do("POST", server.URL, greeting{Name: "erlang", Count: 1}, &response)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, greeting{Name: "hello erlang", Count: 2}, response, "")
+
+	err = client.Do("POST", server.URL, greeting{Count: -1}, &response)
+	assertEqual(t, &StatusError{StatusCode: 500, Status: "500 Internal Server Error"}, err, "")
+	err = client.Do("POST", server.URL, greeting{Name: strings.Repeat("x", 64)}, &response)
+	assertEqual(t, &StatusError{StatusCode: 413, Status: "413 Request Entity Too Large"}, err, "")
+	err = client.Do("POST", server.URL, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, &response)
+	assertEqual(t, &StatusError{StatusCode: 413, Status: "413 Request Entity Too Large"}, err, "")
+
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader("{}"))
+	assertEqual(t, nil, err, "")
+	resp.Body.Close()
+	assertEqual(t, http.StatusUnsupportedMediaType, resp.StatusCode, "")
+	resp, err = http.Post(server.URL, ContentType, strings.NewReader("\x83j\x00"))
+	assertEqual(t, nil, err, "")
+	resp.Body.Close()
+	assertEqual(t, http.StatusBadRequest, resp.StatusCode, "")
+}
+
+func TestWriteResponse(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := WriteResponse(recorder, http.StatusCreated, erlang.OtpErlangAtom("ok"), -1)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, http.StatusCreated, recorder.Code, "")
+	assertEqual(t, ContentType, recorder.Header().Get("Content-Type"), "")
+	assertEqual(t, "\x83s\x02ok", recorder.Body.String(), "")
+	term, err := DecodeResponse(recorder.Result(), erlang.Limits{})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, erlang.OtpErlangAtom("ok"), term, "")
+}
+
+func TestDecodeSizeDefault(t *testing.T) {
+	// a body is never read beyond SizeDefault when Limits.Size is not set
+	body := io.MultiReader(strings.NewReader("\x83m\x7f\xff\xff\xff"),
+		zeroReader{})
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Set("Content-Type", ContentType)
+	_, err := DecodeRequest(r, erlang.Limits{})
+	assertEqual(t, true, errors.Is(err, erlang.ErrLimit), "")
+	assertEqual(t, "size limit exceeded", err.Error(), "")
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
@@ -0,0 +1,553 @@
+// Package etfjson converts between Erlang terms and JSON
+package etfjson
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// JSON object tags for terms without a JSON type
+const (
+	TagAtom         = "__atom__"
+	TagBinary       = "__binary__"    // base64
+	TagBitstring    = "__bitstring__" // [base64, bits]
+	TagString       = "__string__"    // STRING_EXT (list of bytes)
+	TagTuple        = "__tuple__"
+	TagImproperList = "__improper_list__" // elements with the tail last
+	TagMap          = "__map__"           // [[Key, Value], ...]
+	TagPid          = "__pid__"           // base64 term_to_binary
+	TagPort         = "__port__"          // base64 term_to_binary
+	TagReference    = "__reference__"     // base64 term_to_binary
+	TagFunction     = "__function__"      // base64 term_to_binary
+)
+
+// ErrTerm is returned when a term has no JSON representation
+var ErrTerm = errors.New("term not supported")
+
+// ErrJSON is returned when a tagged JSON object is invalid
+var ErrJSON = errors.New("invalid tagged JSON object")
+
+// Converter provides the JSON representation choices
+// (the zero value is the most readable JSON)
+//
+// Maps become objects when all keys are atoms or strings, otherwise
+// maps become {"__map__": [[Key, Value], ...]}.  Object keys become
+// STRING_EXT keys (Go strings) because binaries are not valid Go map keys.
+type Converter struct {
+	AtomObjects  bool // atoms as {"__atom__": Name} instead of strings
+	BinaryBase64 bool // binaries as base64 strings instead of UTF-8 strings
+	TupleObjects bool // tuples as {"__tuple__": [...]} instead of arrays
+	Proplists    bool // lists of {Key, Value} as objects
+	StringLists  bool // STRING_EXT as {"__string__": Text} instead of strings
+	AtomKeys     bool // object keys become atom keys instead of strings
+	lossless     bool
+}
+
+// Lossless provides a Converter that round-trips every term
+func Lossless() Converter {
+	return Converter{
+		AtomObjects:  true,
+		TupleObjects: true,
+		StringLists:  true,
+		lossless:     true,
+	}
+}
+
+// Marshal provides the JSON of a term
+func (c Converter) Marshal(term interface{}) ([]byte, error) {
+	value, err := c.ToJSON(term)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// Unmarshal provides the term of JSON
+// (strings and object keys become binaries, arrays become lists)
+func (c Converter) Unmarshal(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return c.FromJSON(value)
+}
+
+// ToJSON provides the encoding/json representation of a term
+func (c Converter) ToJSON(termI interface{}) (interface{}, error) {
+	switch term := termI.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return term, nil
+	case uint8, int32, int, int64:
+		return term, nil
+	case *big.Int:
+		return json.Number(term.String()), nil
+	case float64:
+		return floatNumber(term)
+	case float32:
+		return floatNumber(float64(term))
+	case erlang.OtpErlangAtom:
+		return c.atom(string(term)), nil
+	case erlang.OtpErlangAtomUTF8:
+		return c.atom(string(term)), nil
+	case string:
+		if c.StringLists {
+			return tagged(TagString, latin1(term)), nil
+		}
+		return latin1(term), nil
+	case []byte:
+		return c.binary(term), nil
+	case erlang.OtpErlangBinary:
+		if term.Bits != 8 {
+			return tagged(TagBitstring, []interface{}{
+				base64.StdEncoding.EncodeToString(term.Value), term.Bits}), nil
+		}
+		return c.binary(term.Value), nil
+	case erlang.OtpErlangTuple:
+		elements, err := c.elements(term)
+		if err != nil {
+			return nil, err
+		}
+		if c.TupleObjects {
+			return tagged(TagTuple, elements), nil
+		}
+		return elements, nil
+	case erlang.OtpErlangList:
+		if term.Improper {
+			elements, err := c.elements(term.Value)
+			if err != nil {
+				return nil, err
+			}
+			return tagged(TagImproperList, elements), nil
+		}
+		if c.Proplists {
+			if object, ok, err := c.proplist(term.Value); ok || err != nil {
+				return object, err
+			}
+		}
+		return c.elements(term.Value)
+	case erlang.OtpErlangMap:
+		return c.mapping(term)
+	case erlang.OtpErlangPid:
+		return opaque(TagPid, term)
+	case erlang.OtpErlangPort:
+		return opaque(TagPort, term)
+	case erlang.OtpErlangReference:
+		return opaque(TagReference, term)
+	case erlang.OtpErlangFunction:
+		return opaque(TagFunction, term)
+	default:
+		return nil, ErrTerm
+	}
+}
+
+// FromJSON provides the term of an encoding/json representation
+// (from json.Decoder with UseNumber or json.Unmarshal)
+func (c Converter) FromJSON(valueI interface{}) (interface{}, error) {
+	switch value := valueI.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return value, nil
+	case json.Number:
+		return number(string(value))
+	case float64:
+		return value, nil
+	case string:
+		return erlang.OtpErlangBinary{Value: []byte(value), Bits: 8}, nil
+	case []interface{}:
+		elements, err := c.fromElements(value)
+		if err != nil {
+			return nil, err
+		}
+		return erlang.OtpErlangList{Value: elements}, nil
+	case map[string]interface{}:
+		if len(value) == 1 {
+			for tag, tagValue := range value {
+				if strings.HasPrefix(tag, "__") {
+					if term, ok, err := c.fromTagged(tag, tagValue); ok {
+						return term, err
+					}
+				}
+			}
+		}
+		term := make(erlang.OtpErlangMap, len(value))
+		for key, element := range value {
+			elementTerm, err := c.FromJSON(element)
+			if err != nil {
+				return nil, err
+			}
+			term[c.key(key)] = elementTerm
+		}
+		return term, nil
+	default:
+		return nil, ErrJSON
+	}
+}
+
+func (c Converter) atom(name string) interface{} {
+	if c.AtomObjects {
+		return tagged(TagAtom, name)
+	}
+	return name
+}
+
+func (c Converter) binary(value []byte) interface{} {
+	if c.BinaryBase64 {
+		return base64.StdEncoding.EncodeToString(value)
+	}
+	if !utf8.Valid(value) {
+		return tagged(TagBinary, base64.StdEncoding.EncodeToString(value))
+	}
+	return string(value)
+}
+
+func (c Converter) key(name string) interface{} {
+	if c.AtomKeys {
+		return erlang.OtpErlangAtom(name)
+	}
+	return name
+}
+
+// keyText provides the object key of a map key
+func (c Converter) keyText(key interface{}) (string, bool) {
+	switch term := key.(type) {
+	case erlang.OtpErlangAtom:
+		return string(term), !c.lossless || c.AtomKeys
+	case erlang.OtpErlangAtomUTF8:
+		return string(term), !c.lossless || c.AtomKeys
+	case string:
+		return latin1(term), !c.lossless || !c.AtomKeys
+	default:
+		return "", false
+	}
+}
+
+func (c Converter) elements(terms []interface{}) ([]interface{}, error) {
+	elements := make([]interface{}, len(terms))
+	for i, term := range terms {
+		element, err := c.ToJSON(term)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = element
+	}
+	return elements, nil
+}
+
+func (c Converter) mapping(term erlang.OtpErlangMap) (interface{}, error) {
+	object := make(map[string]interface{}, len(term))
+	for key, element := range term {
+		name, ok := c.keyText(key)
+		if !ok {
+			object = nil
+			break
+		}
+		if _, exists := object[name]; exists {
+			// an atom key and a string key with the same text
+			object = nil
+			break
+		}
+		value, err := c.ToJSON(element)
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+	if object != nil && !(len(object) == 1 && isTagged(object)) {
+		return object, nil
+	}
+	pairs := make([]interface{}, 0, len(term))
+	for key, element := range term {
+		keyValue, err := c.ToJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := c.ToJSON(element)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, []interface{}{keyValue, value})
+	}
+	return tagged(TagMap, pairs), nil
+}
+
+// proplist provides an object if all elements are {Key, Value}
+// with unique atom, string or binary keys
+func (c Converter) proplist(terms []interface{}) (interface{}, bool, error) {
+	if len(terms) == 0 {
+		return nil, false, nil
+	}
+	object := make(map[string]interface{}, len(terms))
+	for _, termI := range terms {
+		term, ok := termI.(erlang.OtpErlangTuple)
+		if !ok || len(term) != 2 {
+			return nil, false, nil
+		}
+		var name string
+		switch key := term[0].(type) {
+		case erlang.OtpErlangAtom:
+			name = string(key)
+		case erlang.OtpErlangAtomUTF8:
+			name = string(key)
+		case string:
+			name = latin1(key)
+		case erlang.OtpErlangBinary:
+			if key.Bits != 8 || !utf8.Valid(key.Value) {
+				return nil, false, nil
+			}
+			name = string(key.Value)
+		default:
+			return nil, false, nil
+		}
+		if _, exists := object[name]; exists {
+			return nil, false, nil
+		}
+		value, err := c.ToJSON(term[1])
+		if err != nil {
+			return nil, true, err
+		}
+		object[name] = value
+	}
+	return object, true, nil
+}
+
+func (c Converter) fromElements(values []interface{}) ([]interface{}, error) {
+	elements := make([]interface{}, len(values))
+	for i, value := range values {
+		element, err := c.FromJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = element
+	}
+	return elements, nil
+}
+
+// fromTagged provides the term of a tagged object
+// (false is returned if the tag is unknown)
+func (c Converter) fromTagged(tag string, value interface{}) (interface{}, bool, error) {
+	switch tag {
+	case TagAtom:
+		name, ok := value.(string)
+		if !ok {
+			return nil, true, ErrJSON
+		}
+		return erlang.OtpErlangAtom(name), true, nil
+	case TagBinary:
+		data, err := base64Value(value)
+		if err != nil {
+			return nil, true, err
+		}
+		return erlang.OtpErlangBinary{Value: data, Bits: 8}, true, nil
+	case TagBitstring:
+		values, ok := value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, true, ErrJSON
+		}
+		data, err := base64Value(values[0])
+		if err != nil {
+			return nil, true, err
+		}
+		bits, err := c.FromJSON(values[1])
+		if err != nil {
+			return nil, true, err
+		}
+		bitsValue, ok := bits.(uint8)
+		if !ok || bitsValue < 1 || bitsValue > 8 || len(data) == 0 {
+			return nil, true, ErrJSON
+		}
+		return erlang.OtpErlangBinary{Value: data, Bits: bitsValue}, true, nil
+	case TagString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, true, ErrJSON
+		}
+		characters := make([]byte, 0, len(text))
+		for _, character := range text {
+			if character > 0xff {
+				return nil, true, ErrJSON
+			}
+			characters = append(characters, byte(character))
+		}
+		return string(characters), true, nil
+	case TagTuple, TagImproperList, TagMap:
+		values, ok := value.([]interface{})
+		if !ok {
+			return nil, true, ErrJSON
+		}
+		elements, err := c.fromElements(values)
+		if err != nil {
+			return nil, true, err
+		}
+		switch tag {
+		case TagTuple:
+			return erlang.OtpErlangTuple(elements), true, nil
+		case TagImproperList:
+			if len(elements) < 2 {
+				return nil, true, ErrJSON
+			}
+			return erlang.OtpErlangList{Value: elements, Improper: true}, true, nil
+		}
+		term := make(erlang.OtpErlangMap, len(elements))
+		for _, element := range elements {
+			pair, ok := element.(erlang.OtpErlangList)
+			if !ok || len(pair.Value) != 2 || pair.Improper {
+				return nil, true, ErrJSON
+			}
+			key := pair.Value[0]
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				return nil, true, ErrTerm
+			}
+			term[key] = pair.Value[1]
+		}
+		return term, true, nil
+	case TagPid, TagPort, TagReference, TagFunction:
+		data, err := base64Value(value)
+		if err != nil {
+			return nil, true, err
+		}
+		term, err := erlang.BinaryToTerm(data)
+		if err != nil {
+			return nil, true, err
+		}
+		var ok bool
+		switch tag {
+		case TagPid:
+			_, ok = term.(erlang.OtpErlangPid)
+		case TagPort:
+			_, ok = term.(erlang.OtpErlangPort)
+		case TagReference:
+			_, ok = term.(erlang.OtpErlangReference)
+		case TagFunction:
+			_, ok = term.(erlang.OtpErlangFunction)
+		}
+		if !ok {
+			return nil, true, ErrJSON
+		}
+		return term, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// isTagged checks if the object would be decoded as a tagged object
+func isTagged(object map[string]interface{}) bool {
+	for tag := range object {
+		switch tag {
+		case TagAtom, TagBinary, TagBitstring, TagString, TagTuple,
+			TagImproperList, TagMap, TagPid, TagPort, TagReference,
+			TagFunction:
+			return true
+		}
+	}
+	return false
+}
+
+func tagged(tag string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{tag: value}
+}
+
+func opaque(tag string, term interface{}) (interface{}, error) {
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return nil, err
+	}
+	return tagged(tag, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func base64Value(value interface{}) ([]byte, error) {
+	text, ok := value.(string)
+	if !ok {
+		return nil, ErrJSON
+	}
+	data, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, ErrJSON
+	}
+	return data, nil
+}
+
+// floatNumber keeps a decimal point so the number remains a float
+func floatNumber(value float64) (interface{}, error) {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil, ErrTerm
+	}
+	text := strconv.FormatFloat(value, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".eE") {
+		text += ".0"
+	}
+	return json.Number(text), nil
+}
+
+// number provides the same integer types as erlang.BinaryToTerm
+func number(text string) (interface{}, error) {
+	if strings.ContainsAny(text, ".eE") {
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, ErrJSON
+		}
+		return value, nil
+	}
+	value, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return nil, ErrJSON
+	}
+	if value.IsInt64() {
+		integer := value.Int64()
+		switch {
+		case integer >= 0 && integer <= math.MaxUint8:
+			return uint8(integer), nil
+		case integer >= math.MinInt32 && integer <= math.MaxInt32:
+			return int32(integer), nil
+		}
+	}
+	return value, nil
+}
+
+// latin1 provides the UTF-8 text of STRING_EXT characters
+func latin1(characters string) string {
+	var text strings.Builder
+	for i := 0; i < len(characters); i++ {
+		text.WriteRune(rune(characters[i]))
+	}
+	return text.String()
+}
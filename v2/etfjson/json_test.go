@@ -0,0 +1,132 @@
+package etfjson
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func TestReadable(t *testing.T) {
+	term := erlang.OtpErlangMap{
+		erlang.OtpErlangAtom("name"): erlang.OtpErlangBinary{Value: []byte("Ann"), Bits: 8},
+		erlang.OtpErlangAtom("tags"): erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangAtom("a"), "bc"}},
+		erlang.OtpErlangAtom("point"): erlang.OtpErlangTuple{uint8(1), 2.0},
+		erlang.OtpErlangAtom("opts"): erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangTuple{erlang.OtpErlangAtom("active"), true},
+			erlang.OtpErlangTuple{erlang.OtpErlangBinary{Value: []byte("mode"), Bits: 8}, nil}}},
+		erlang.OtpErlangAtom("data"): erlang.OtpErlangBinary{Value: []byte{0xff}, Bits: 8},
+		erlang.OtpErlangAtom("ids"):  erlang.OtpErlangMap{uint8(1): int32(-1)},
+	}
+	c := Converter{Proplists: true}
+	data, err := c.Marshal(term)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, `{"data":{"__binary__":"/w=="},"ids":{"__map__":[[1,-1]]},`+
+		`"name":"Ann","opts":{"active":true,"mode":null},"point":[1,2.0],`+
+		`"tags":["a","bc"]}`, string(data), "")
+
+	c = Converter{AtomObjects: true, BinaryBase64: true, TupleObjects: true}
+	data, err = c.Marshal(erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"),
+		erlang.OtpErlangBinary{Value: []byte("hi"), Bits: 8}})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, `{"__tuple__":[{"__atom__":"ok"},"aGk="]}`, string(data), "")
+
+	c = Converter{AtomKeys: true}
+	result, err := c.Unmarshal([]byte(`{"a":[1,"x",1.5,123456789012345678901234567890]}`))
+	assertEqual(t, nil, err, "")
+	bignum, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assertEqual(t, erlang.OtpErlangMap{
+		erlang.OtpErlangAtom("a"): erlang.OtpErlangList{Value: []interface{}{
+			uint8(1), erlang.OtpErlangBinary{Value: []byte("x"), Bits: 8},
+			1.5, bignum}}}, result, "")
+}
+
+func TestLossless(t *testing.T) {
+	pid := erlang.OtpErlangPid{NodeTag: 100,
+		Node: []byte("\x00\x0dnonode@nohost"), ID: []byte{0, 0, 0, 83},
+		Serial: []byte{0, 0, 0, 0}, Creation: []byte{0}}
+	ref := erlang.OtpErlangReference{NodeTag: 100,
+		Node: []byte("\x00\x0dnonode@nohost"), ID: []byte{0, 0, 0, 1, 0, 0, 0, 2},
+		Creation: []byte{0}}
+	bignum, _ := new(big.Int).SetString("-4294967296", 10)
+	data, err := erlang.TermToBinary(erlang.OtpErlangTuple{
+		erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangBinary{Value: []byte("\xc3\xa9"), Bits: 8},
+			erlang.OtpErlangBinary{Value: []byte{0x20}, Bits: 3},
+			"abc",
+			erlang.OtpErlangList{Value: []interface{}{
+				erlang.OtpErlangAtom("a"), erlang.OtpErlangAtom("b")},
+				Improper: true}}},
+		erlang.OtpErlangMap{erlang.OtpErlangAtom("a"): uint8(1),
+			"k": uint8(2), "__atom__": uint8(3)},
+		pid, ref, 1.0e300, -1.0, bignum, nil}, -1)
+	assertEqual(t, nil, err, "")
+	term, err := erlang.BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	c := Lossless()
+	text, err := c.Marshal(term)
+	assertEqual(t, nil, err, "")
+	result, err := c.Unmarshal(text)
+	assertEqual(t, nil, err, string(text))
+	assertEqual(t, term, result, string(text))
+
+	// atom keys become an object with AtomKeys
+	c.AtomKeys = true
+	text, err = c.Marshal(erlang.OtpErlangMap{erlang.OtpErlangAtom("a"): uint8(1)})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, `{"a":1}`, string(text), "")
+}
+
+func TestInvalid(t *testing.T) {
+	c := Lossless()
+	for _, text := range []string{
+		`{"__atom__":1}`,
+		`{"__string__":"Ā"}`,
+		`{"__improper_list__":[1]}`,
+		`{"__map__":[[1]]}`,
+		`{"__pid__":"g2Fh"}`,
+	} {
+		_, err := c.Unmarshal([]byte(text))
+		assertEqual(t, ErrJSON, err, text)
+	}
+	_, err := c.Marshal(erlang.OtpErlangAtomCacheRef(1))
+	assertEqual(t, ErrTerm, err, "")
+}
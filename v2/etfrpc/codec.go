@@ -0,0 +1,211 @@
+// Package etfrpc provides net/rpc codecs that use the Erlang External Term Format
+package etfrpc
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/rpc"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+	"github.com/okeuday/erlang_go/v2/port"
+)
+
+// Each message is a term_to_binary tuple with a 4 byte length header
+// ({packet, 4} for gen_tcp), a {request, Seq, ServiceMethod, Args}
+// request gets a {response, Seq, ServiceMethod, Reply} or
+// {error, Seq, ServiceMethod, Error} response
+// (ServiceMethod and Error are binaries)
+const (
+	packet       = 4
+	atomRequest  = "request"
+	atomResponse = "response"
+	atomError    = "error"
+)
+
+// ErrMessage is returned when a message is not a valid tuple
+var ErrMessage = errors.New("invalid message")
+
+type clientCodec struct {
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	body   interface{}
+}
+
+// NewClientCodec creates a net/rpc ClientCodec on the connection
+func NewClientCodec(conn io.ReadWriteCloser) rpc.ClientCodec {
+	return &clientCodec{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// NewClient creates a net/rpc Client on the connection
+func NewClient(conn io.ReadWriteCloser) *rpc.Client {
+	return rpc.NewClientWithCodec(NewClientCodec(conn))
+}
+
+// Dial connects a net/rpc Client to the network address
+func Dial(network, address string) (*rpc.Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+func (c *clientCodec) WriteRequest(request *rpc.Request, body interface{}) error {
+	return writeMessage(c.conn, atomRequest,
+		request.Seq, request.ServiceMethod, body)
+}
+
+func (c *clientCodec) ReadResponseHeader(response *rpc.Response) error {
+	c.body = nil
+	name, seq, serviceMethod, body, err := readMessage(c.reader)
+	if err != nil {
+		return err
+	}
+	response.Seq = seq
+	response.ServiceMethod = serviceMethod
+	switch name {
+	case atomResponse:
+		c.body = body
+	case atomError:
+		err = erlang.UnmarshalTerm(body, &response.Error)
+		if err != nil || len(response.Error) == 0 {
+			response.Error = erlang.TermString(body)
+		}
+	default:
+		return ErrMessage
+	}
+	return nil
+}
+
+func (c *clientCodec) ReadResponseBody(body interface{}) error {
+	if body == nil {
+		return nil
+	}
+	return erlang.UnmarshalTerm(c.body, body)
+}
+
+func (c *clientCodec) Close() error {
+	return c.conn.Close()
+}
+
+type serverCodec struct {
+	conn   io.ReadWriteCloser
+	reader *bufio.Reader
+	body   interface{}
+}
+
+// NewServerCodec creates a net/rpc ServerCodec on the connection
+func NewServerCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	return &serverCodec{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// ServeConn handles net/rpc requests from the connection with
+// the DefaultServer until the connection is closed
+func ServeConn(conn io.ReadWriteCloser) {
+	rpc.ServeCodec(NewServerCodec(conn))
+}
+
+func (c *serverCodec) ReadRequestHeader(request *rpc.Request) error {
+	c.body = nil
+	name, seq, serviceMethod, body, err := readMessage(c.reader)
+	if err != nil {
+		return err
+	}
+	if name != atomRequest {
+		return ErrMessage
+	}
+	request.Seq = seq
+	request.ServiceMethod = serviceMethod
+	c.body = body
+	return nil
+}
+
+func (c *serverCodec) ReadRequestBody(body interface{}) error {
+	if body == nil {
+		return nil
+	}
+	return erlang.UnmarshalTerm(c.body, body)
+}
+
+func (c *serverCodec) WriteResponse(response *rpc.Response, body interface{}) error {
+	if len(response.Error) > 0 {
+		return writeMessage(c.conn, atomError,
+			response.Seq, response.ServiceMethod, []byte(response.Error))
+	}
+	return writeMessage(c.conn, atomResponse,
+		response.Seq, response.ServiceMethod, body)
+}
+
+func (c *serverCodec) Close() error {
+	return c.conn.Close()
+}
+
+func writeMessage(writer io.Writer, name string, seq uint64,
+	serviceMethod string, body interface{}) error {
+	term, err := erlang.MarshalTerm(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom(name), seq,
+		erlang.OtpErlangBinary{Value: []byte(serviceMethod), Bits: 8},
+		body})
+	if err != nil {
+		return err
+	}
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return err
+	}
+	return port.WritePacket(writer, packet, data)
+}
+
+func readMessage(reader io.Reader) (string, uint64, string, interface{}, error) {
+	data, err := port.ReadPacket(reader, packet)
+	if err != nil {
+		return "", 0, "", nil, err
+	}
+	term, err := erlang.BinaryToTerm(data)
+	if err != nil {
+		return "", 0, "", nil, err
+	}
+	tuple, ok := term.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) != 4 {
+		return "", 0, "", nil, ErrMessage
+	}
+	var name, serviceMethod string
+	var seq uint64
+	err = erlang.UnmarshalTerm(tuple[0], &name)
+	if err == nil {
+		err = erlang.UnmarshalTerm(tuple[1], &seq)
+	}
+	if err == nil {
+		err = erlang.UnmarshalTerm(tuple[2], &serviceMethod)
+	}
+	if err != nil {
+		return "", 0, "", nil, ErrMessage
+	}
+	return name, seq, serviceMethod, tuple[3], nil
+}
@@ -0,0 +1,123 @@
+package etfrpc
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"reflect"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+	"github.com/okeuday/erlang_go/v2/port"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+type Args struct {
+	A, B int
+}
+
+type Quotient struct {
+	Quo, Rem int
+}
+
+type Arith int
+
+func (a *Arith) Multiply(args *Args, reply *int) error {
+	*reply = args.A * args.B
+	return nil
+}
+
+func (a *Arith) Divide(args *Args, quotient *Quotient) error {
+	if args.B == 0 {
+		return errors.New("divide by zero")
+	}
+	quotient.Quo = args.A / args.B
+	quotient.Rem = args.A % args.B
+	return nil
+}
+
+func server(t *testing.T) net.Conn {
+	server := rpc.NewServer()
+	err := server.Register(new(Arith))
+	assertEqual(t, nil, err, "")
+	conn1, conn2 := net.Pipe()
+	go server.ServeCodec(NewServerCodec(conn1))
+	return conn2
+}
+
+func TestClient(t *testing.T) {
+	client := NewClient(server(t))
+	defer client.Close()
+	var product int
+	err := client.Call("Arith.Multiply", &Args{7, 8}, &product)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, 56, product, "")
+	var quotient Quotient
+	err = client.Call("Arith.Divide", &Args{17, 5}, &quotient)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, Quotient{3, 2}, quotient, "")
+	err = client.Call("Arith.Divide", &Args{1, 0}, &quotient)
+	assertEqual(t, rpc.ServerError("divide by zero"), err, "")
+	err = client.Call("Arith.Unknown", &Args{1, 0}, &quotient)
+	assertEqual(t, rpc.ServerError("rpc: can't find method Arith.Unknown"), err, "")
+}
+
+func TestErlangClient(t *testing.T) {
+	conn := server(t)
+	defer conn.Close()
+	// gen_tcp:send(Socket, term_to_binary({request, 1, <<"Arith.Divide">>,
+	//                                      #{a => 17, b => 5}}))
+	request, err := erlang.TermToBinary(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("request"), uint8(1),
+		erlang.OtpErlangBinary{Value: []byte("Arith.Divide"), Bits: 8},
+		erlang.OtpErlangMap{
+			erlang.OtpErlangAtom("a"): uint8(17),
+			erlang.OtpErlangAtom("b"): uint8(5)}}, -1)
+	assertEqual(t, nil, err, "")
+	go port.WritePacket(conn, packet, request)
+	data, err := port.ReadPacket(conn, packet)
+	assertEqual(t, nil, err, "")
+	response, err := erlang.BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("response"), uint8(1),
+		erlang.OtpErlangBinary{Value: []byte("Arith.Divide"), Bits: 8},
+		erlang.OtpErlangMap{
+			erlang.OtpErlangAtom("quo"): uint8(3),
+			erlang.OtpErlangAtom("rem"): uint8(2)}}, response, "")
+}
@@ -0,0 +1,309 @@
+// Package etftcp provides a server for gen_tcp {packet, 4} term_to_binary clients
+package etftcp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+	"github.com/okeuday/erlang_go/v2/port"
+)
+
+const (
+	packet          = 4
+	inFlightDefault = 16
+)
+
+// Server errors
+var (
+	ErrServerClosed = errors.New("server closed")
+	ErrPacketSize   = errors.New("packet size limit exceeded")
+)
+
+// Server handles requests from gen_tcp sockets that use
+// [binary, {packet, 4}] with term_to_binary data
+//
+// A request is Request or {Ref, Request} with Ref as a reference.
+// Request is dispatched on the atom name (or a tuple with the atom name
+// as the first element) to the port.Handler registered with Handle.
+// Requests with a Ref are handled concurrently (up to MaxInFlight for
+// each connection) with a {Ref, Reply} reply while requests without
+// a Ref are handled in order with a Reply reply.
+//
+// Reply is {ok, HandlerReply} or {error, Reason}:
+//   - {badarg, Message} when the request is not a valid term
+//   - {undef, Name} when no handler is registered
+//   - port.Error.Reason or the error message when the handler fails
+type Server struct {
+	MaxInFlight int           // concurrent requests for each connection
+	Limits      erlang.Limits // Limits.Size limits the packet size
+	Compressed  int           // reply compression level (-1 is none)
+
+	mutex     sync.Mutex
+	handlers  map[string]port.Handler
+	listeners map[net.Listener]struct{}
+	conns     map[*serverConn]struct{}
+	closed    bool
+	wait      sync.WaitGroup
+}
+
+type serverConn struct {
+	server   *Server
+	conn     net.Conn
+	mutex    sync.Mutex
+	inFlight chan struct{}
+	wait     sync.WaitGroup
+}
+
+// NewServer creates a Server
+func NewServer() *Server {
+	return &Server{
+		MaxInFlight: inFlightDefault,
+		Compressed:  -1,
+		handlers:    make(map[string]port.Handler),
+		listeners:   make(map[net.Listener]struct{}),
+		conns:       make(map[*serverConn]struct{}),
+	}
+}
+
+// Handle registers the handler for requests that are the atom name
+// or a tuple with the atom name as the first element
+func (s *Server) Handle(name string, handler port.Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[name] = handler
+}
+
+// Serve handles connections from the listener until the listener fails
+// (ErrServerClosed is returned after Shutdown or Close)
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listeners[listener] = struct{}{}
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.listeners, listener)
+		s.mutex.Unlock()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		if !s.ServeConn(conn) {
+			return ErrServerClosed
+		}
+	}
+}
+
+// ServeConn handles requests from a single connection in a new goroutine
+// (false is returned if the server is closed)
+func (s *Server) ServeConn(conn net.Conn) bool {
+	maxInFlight := s.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+	c := &serverConn{
+		server:   s,
+		conn:     conn,
+		inFlight: make(chan struct{}, maxInFlight),
+	}
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		conn.Close()
+		return false
+	}
+	s.conns[c] = struct{}{}
+	s.wait.Add(1)
+	s.mutex.Unlock()
+	go c.serve()
+	return true
+}
+
+// Shutdown stops accepting connections and stops reading requests,
+// then waits for the requests in flight to be replied to before closing
+// the connections (the connections are closed when the context is done)
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for c := range s.conns {
+		// interrupt the connection read
+		c.conn.SetReadDeadline(time.Now())
+	}
+	s.mutex.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.wait.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Close()
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting connections and closes all connections
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	return nil
+}
+
+func (c *serverConn) serve() {
+	s := c.server
+	defer func() {
+		// wait for the requests in flight before closing
+		c.wait.Wait()
+		c.conn.Close()
+		s.mutex.Lock()
+		delete(s.conns, c)
+		s.mutex.Unlock()
+		s.wait.Done()
+	}()
+	reader := bufio.NewReader(c.conn)
+	for {
+		data, err := c.read(reader)
+		if err != nil {
+			return
+		}
+		request, err := decode(data, s.Limits)
+		if err != nil {
+			err = c.send(port.ReplyError(erlang.OtpErlangTuple{
+				erlang.OtpErlangAtom("badarg"), []byte(err.Error())}))
+			if err != nil {
+				return
+			}
+			continue
+		}
+		if tuple, ok := request.(erlang.OtpErlangTuple); ok && len(tuple) == 2 {
+			if ref, isRef := tuple[0].(erlang.OtpErlangReference); isRef {
+				// blocks reading when MaxInFlight requests are handled
+				c.inFlight <- struct{}{}
+				c.wait.Add(1)
+				go func() {
+					defer c.wait.Done()
+					reply := s.request(tuple[1])
+					<-c.inFlight
+					c.send(erlang.OtpErlangTuple{ref, reply})
+				}()
+				continue
+			}
+		}
+		err = c.send(s.request(request))
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (c *serverConn) read(reader io.Reader) ([]byte, error) {
+	var header [packet]byte
+	_, err := io.ReadFull(reader, header[:])
+	if err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	limit := c.server.Limits.Size
+	if limit > 0 && uint64(length) > uint64(limit) {
+		return nil, ErrPacketSize
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *serverConn) send(term interface{}) error {
+	data, err := erlang.TermToBinary(term, c.server.Compressed)
+	if err != nil {
+		data, err = erlang.TermToBinary(port.ReplyError(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("badarg"), []byte(err.Error())}), -1)
+		if err != nil {
+			return err
+		}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return port.WritePacket(c.conn, packet, data)
+}
+
+func (s *Server) request(request interface{}) interface{} {
+	name, ok := port.RequestName(request)
+	if !ok {
+		return port.ReplyError(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("badarg"), []byte("invalid request")})
+	}
+	s.mutex.Lock()
+	handler, ok := s.handlers[name]
+	s.mutex.Unlock()
+	if !ok {
+		return port.ReplyError(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("undef"), erlang.OtpErlangAtom(name)})
+	}
+	return port.Reply(handler, request)
+}
+
+func decode(data []byte, limits erlang.Limits) (interface{}, error) {
+	err := erlang.ValidateLimits(data, limits)
+	if err != nil {
+		return nil, err
+	}
+	return erlang.BinaryToTerm(data)
+}
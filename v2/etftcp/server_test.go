@@ -0,0 +1,157 @@
+package etftcp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+	"github.com/okeuday/erlang_go/v2/port"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func ref(id byte) erlang.OtpErlangReference {
+	return erlang.OtpErlangReference{NodeTag: 119,
+		Node: []byte("\x0dnonode@nohost"), ID: []byte{0, 0, 0, id},
+		Creation: []byte{0, 0, 0, 0}}
+}
+
+func send(t *testing.T, conn net.Conn, term interface{}) {
+	t.Helper()
+	data, err := erlang.TermToBinary(term, -1)
+	assertEqual(t, nil, err, "")
+	err = port.WritePacket(conn, packet, data)
+	assertEqual(t, nil, err, "")
+}
+
+func receive(t *testing.T, conn net.Conn) interface{} {
+	t.Helper()
+	data, err := port.ReadPacket(conn, packet)
+	assertEqual(t, nil, err, "")
+	term, err := erlang.BinaryToTerm(data)
+	assertEqual(t, nil, err, "")
+	return term
+}
+
+func start(t *testing.T) (*Server, net.Conn, chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assertEqual(t, nil, err, "")
+	server := NewServer()
+	server.Limits = erlang.Limits{Size: 1024}
+	release := make(chan struct{})
+	server.Handle("echo", func(request interface{}) (interface{}, error) {
+		return request.(erlang.OtpErlangTuple)[1], nil
+	})
+	server.Handle("wait", func(request interface{}) (interface{}, error) {
+		<-release
+		return erlang.OtpErlangAtom("done"), nil
+	})
+	server.Handle("fail", func(request interface{}) (interface{}, error) {
+		return nil, &port.Error{Reason: erlang.OtpErlangAtom("failed")}
+	})
+	go server.Serve(listener)
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assertEqual(t, nil, err, "")
+	return server, conn, release
+}
+
+func TestServer(t *testing.T) {
+	server, conn, release := start(t)
+	defer server.Close()
+	defer conn.Close()
+
+	// a correlated request does not block later requests
+	send(t, conn, erlang.OtpErlangTuple{ref(1), erlang.OtpErlangAtom("wait")})
+	send(t, conn, erlang.OtpErlangTuple{ref(2),
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("echo"), uint8(2)}})
+	assertEqual(t, erlang.OtpErlangTuple{ref(2), erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), uint8(2)}},
+		receive(t, conn), "")
+	send(t, conn, erlang.OtpErlangTuple{erlang.OtpErlangAtom("echo"), uint8(3)})
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), uint8(3)}, receive(t, conn), "")
+	close(release)
+	assertEqual(t, erlang.OtpErlangTuple{ref(1),
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), erlang.OtpErlangAtom("done")}},
+		receive(t, conn), "")
+
+	send(t, conn, erlang.OtpErlangAtom("fail"))
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"),
+		erlang.OtpErlangAtom("failed")}, receive(t, conn), "")
+	send(t, conn, erlang.OtpErlangTuple{ref(3), erlang.OtpErlangAtom("unknown")})
+	assertEqual(t, erlang.OtpErlangTuple{ref(3),
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"),
+			erlang.OtpErlangTuple{erlang.OtpErlangAtom("undef"),
+				erlang.OtpErlangAtom("unknown")}}}, receive(t, conn), "")
+
+	// packets over the size limit close the connection
+	err := port.WritePacket(conn, packet, make([]byte, 1025))
+	assertEqual(t, nil, err, "")
+	_, err = port.ReadPacket(conn, packet)
+	assertEqual(t, true, err != nil, "")
+}
+
+func TestShutdown(t *testing.T) {
+	server, conn, release := start(t)
+	defer conn.Close()
+	send(t, conn, erlang.OtpErlangTuple{ref(1), erlang.OtpErlangAtom("wait")})
+	send(t, conn, erlang.OtpErlangTuple{erlang.OtpErlangAtom("echo"), uint8(1)})
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), uint8(1)}, receive(t, conn), "")
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown before the request in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	assertEqual(t, erlang.OtpErlangTuple{ref(1),
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), erlang.OtpErlangAtom("done")}},
+		receive(t, conn), "")
+	assertEqual(t, nil, <-shutdown, "")
+	_, err := port.ReadPacket(conn, packet)
+	assertEqual(t, true, err != nil, "")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, true, errors.Is(server.Serve(listener), ErrServerClosed), "")
+}
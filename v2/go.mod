@@ -1 +1,3 @@
 module github.com/okeuday/erlang_go/v2
+
+go 1.19
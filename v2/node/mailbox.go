@@ -0,0 +1,423 @@
+package node
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"sync"
+
+	"github.com/okeuday/erlang_go/v2/dist"
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// Mailbox is a process of a Node that receives messages with a channel
+// (exit signals are received as {'EXIT', Pid, Reason} messages and
+// monitors provide {'DOWN', Ref, process, Object, Reason} messages)
+type Mailbox struct {
+	node        *Node
+	pid         erlang.OtpErlangPid
+	key         string
+	name        string
+	links       map[string]erlang.OtpErlangPid
+	monitors    map[string]monitor // monitors of other processes
+	monitoredBy map[string]monitor // monitors by other processes
+	closed      bool
+	queueMutex  sync.Mutex
+	queueCond   *sync.Cond
+	queue       []interface{}
+	queueClosed bool
+	out         chan interface{}
+	done        chan struct{}
+}
+
+type monitor struct {
+	ref    erlang.OtpErlangReference
+	pid    erlang.OtpErlangPid // the other process, if it is a pid
+	to     interface{}         // the pid or registered name atom monitored
+	object interface{}         // the 'DOWN' message Object
+	node   string
+}
+
+func mailboxNew(n *Node, pid erlang.OtpErlangPid) *Mailbox {
+	m := &Mailbox{
+		node:        n,
+		pid:         pid,
+		key:         pidKey(pid),
+		links:       make(map[string]erlang.OtpErlangPid),
+		monitors:    make(map[string]monitor),
+		monitoredBy: make(map[string]monitor),
+		out:         make(chan interface{}),
+		done:        make(chan struct{}),
+	}
+	m.queueCond = sync.NewCond(&m.queueMutex)
+	go m.pump()
+	return m
+}
+
+// Pid provides the mailbox pid
+func (m *Mailbox) Pid() erlang.OtpErlangPid {
+	return m.pid
+}
+
+// Name provides the registered name of the mailbox
+func (m *Mailbox) Name() string {
+	return m.name
+}
+
+// Receive provides the channel of received messages
+// (the channel is closed after the mailbox exits)
+func (m *Mailbox) Receive() <-chan interface{} {
+	return m.out
+}
+
+// Send sends the message to a pid, a local registered name or
+// a {Name, Node} tuple
+func (m *Mailbox) Send(to interface{}, message interface{}) error {
+	n := m.node
+	switch destination := to.(type) {
+	case erlang.OtpErlangPid:
+		node := pidNode(destination)
+		if node != n.name {
+			return n.send(node, dist.Send{To: destination, Message: message})
+		}
+		n.mutex.Lock()
+		target := n.mailboxes[pidKey(destination)]
+		if target != nil {
+			target.deliver(message)
+		}
+		n.mutex.Unlock()
+		return nil
+	case erlang.OtpErlangTuple:
+		if len(destination) != 2 {
+			return ErrDestination
+		}
+		name, ok := atomName(destination[0])
+		if !ok {
+			return ErrDestination
+		}
+		var node string
+		node, ok = atomName(destination[1])
+		if !ok {
+			return ErrDestination
+		}
+		if node != n.name {
+			return n.send(node, dist.RegSend{From: m.pid, ToName: name, Message: message})
+		}
+		return m.sendName(name, message)
+	default:
+		name, ok := atomName(destination)
+		if !ok {
+			return ErrDestination
+		}
+		return m.sendName(name, message)
+	}
+}
+
+func (m *Mailbox) sendName(name string, message interface{}) error {
+	n := m.node
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	target := n.names[name]
+	if target == nil {
+		return ErrNotRegistered
+	}
+	target.deliver(message)
+	return nil
+}
+
+// Link creates a link with the pid
+func (m *Mailbox) Link(pid erlang.OtpErlangPid) error {
+	n := m.node
+	node := pidNode(pid)
+	key := pidKey(pid)
+	n.mutex.Lock()
+	if m.closed {
+		n.mutex.Unlock()
+		return ErrMailboxClosed
+	}
+	if node == n.name {
+		target := n.mailboxes[key]
+		if target == nil {
+			m.deliver(exitMessage(pid, erlang.OtpErlangAtom("noproc")))
+		} else if target != m {
+			m.links[key] = pid
+			target.links[m.key] = m.pid
+		}
+		n.mutex.Unlock()
+		return nil
+	}
+	m.links[key] = pid
+	n.mutex.Unlock()
+	err := n.send(node, dist.Link{From: m.pid, To: pid})
+	if err != nil {
+		n.mutex.Lock()
+		delete(m.links, key)
+		m.deliver(exitMessage(pid, erlang.OtpErlangAtom("noconnection")))
+		n.mutex.Unlock()
+	}
+	return nil
+}
+
+// Unlink removes a link with the pid
+func (m *Mailbox) Unlink(pid erlang.OtpErlangPid) error {
+	n := m.node
+	node := pidNode(pid)
+	key := pidKey(pid)
+	n.mutex.Lock()
+	if m.closed {
+		n.mutex.Unlock()
+		return ErrMailboxClosed
+	}
+	_, exists := m.links[key]
+	delete(m.links, key)
+	if node == n.name {
+		target := n.mailboxes[key]
+		if target != nil {
+			delete(target.links, m.key)
+		}
+		n.mutex.Unlock()
+		return nil
+	}
+	n.refID += 1
+	id := n.refID
+	n.mutex.Unlock()
+	if !exists {
+		return nil
+	}
+	c, err := n.connection(node)
+	if err != nil {
+		return nil
+	}
+	if c.conn.Peer.Flags&dist.FlagUnlinkID != 0 {
+		return c.conn.Send(dist.UnlinkID{ID: id, From: m.pid, To: pid})
+	}
+	return c.conn.Send(dist.Unlink{From: m.pid, To: pid})
+}
+
+// Monitor monitors a pid, a local registered name or a {Name, Node} tuple
+func (m *Mailbox) Monitor(to interface{}) (erlang.OtpErlangReference, error) {
+	n := m.node
+	ref := n.makeRef()
+	var held monitor = monitor{ref: ref, to: to, object: to}
+	switch destination := to.(type) {
+	case erlang.OtpErlangPid:
+		held.pid = destination
+		held.node = pidNode(destination)
+	case erlang.OtpErlangTuple:
+		if len(destination) != 2 {
+			return ref, ErrDestination
+		}
+		name, ok := atomName(destination[0])
+		if !ok {
+			return ref, ErrDestination
+		}
+		var node string
+		node, ok = atomName(destination[1])
+		if !ok {
+			return ref, ErrDestination
+		}
+		held.to = erlang.OtpErlangAtom(name)
+		held.object = erlang.OtpErlangTuple{erlang.OtpErlangAtom(name), erlang.OtpErlangAtom(node)}
+		held.node = node
+	default:
+		name, ok := atomName(destination)
+		if !ok {
+			return ref, ErrDestination
+		}
+		held.to = erlang.OtpErlangAtom(name)
+		held.object = erlang.OtpErlangTuple{erlang.OtpErlangAtom(name), erlang.OtpErlangAtom(n.name)}
+		held.node = n.name
+	}
+	n.mutex.Lock()
+	if m.closed {
+		n.mutex.Unlock()
+		return ref, ErrMailboxClosed
+	}
+	if held.node == n.name {
+		target := n.monitorTarget(held.to)
+		if target == nil {
+			m.deliver(downMessage(ref, held.object, erlang.OtpErlangAtom("noproc")))
+		} else {
+			held.pid = target.pid
+			m.monitors[refKey(ref)] = held
+			target.monitoredBy[refKey(ref)] = monitor{ref: ref, pid: m.pid, to: held.to, object: held.object, node: n.name}
+		}
+		n.mutex.Unlock()
+		return ref, nil
+	}
+	m.monitors[refKey(ref)] = held
+	n.mutex.Unlock()
+	err := n.send(held.node, dist.MonitorP{From: m.pid, To: held.to, Ref: ref})
+	if err != nil {
+		n.mutex.Lock()
+		delete(m.monitors, refKey(ref))
+		m.deliver(downMessage(ref, held.object, erlang.OtpErlangAtom("noconnection")))
+		n.mutex.Unlock()
+	}
+	return ref, nil
+}
+
+// Demonitor removes a monitor
+func (m *Mailbox) Demonitor(ref erlang.OtpErlangReference) error {
+	n := m.node
+	key := refKey(ref)
+	n.mutex.Lock()
+	held, exists := m.monitors[key]
+	if !exists {
+		n.mutex.Unlock()
+		return nil
+	}
+	delete(m.monitors, key)
+	if held.node == n.name {
+		target := n.mailboxes[pidKey(held.pid)]
+		if target != nil {
+			delete(target.monitoredBy, key)
+		}
+		n.mutex.Unlock()
+		return nil
+	}
+	n.mutex.Unlock()
+	return n.send(held.node, dist.DemonitorP{From: m.pid, To: held.to, Ref: ref})
+}
+
+// Close exits the mailbox with the reason normal
+func (m *Mailbox) Close() error {
+	return m.Exit(erlang.OtpErlangAtom("normal"))
+}
+
+// Exit exits the mailbox, providing the reason to links and monitors
+func (m *Mailbox) Exit(reason interface{}) error {
+	n := m.node
+	n.mutex.Lock()
+	if m.closed {
+		n.mutex.Unlock()
+		return ErrMailboxClosed
+	}
+	m.closed = true
+	delete(n.mailboxes, m.key)
+	if m.name != "" {
+		delete(n.names, m.name)
+	}
+	var signals []signal
+	for key, pid := range m.links {
+		target := n.mailboxes[key]
+		if target != nil {
+			delete(target.links, m.key)
+			target.deliver(exitMessage(m.pid, reason))
+		} else if pidNode(pid) != n.name {
+			signals = append(signals, signal{to: pid, message: dist.Exit{From: m.pid, To: pid, Reason: reason}})
+		}
+	}
+	for key, watcher := range m.monitoredBy {
+		target := n.mailboxes[pidKey(watcher.pid)]
+		if target != nil {
+			delete(target.monitors, key)
+			target.deliver(downMessage(watcher.ref, watcher.object, reason))
+		} else if pidNode(watcher.pid) != n.name {
+			signals = append(signals, signal{to: watcher.pid, message: dist.MonitorPExit{From: watcher.to, To: watcher.pid, Ref: watcher.ref, Reason: reason}})
+		}
+	}
+	for key, held := range m.monitors {
+		if held.node == n.name {
+			target := n.mailboxes[pidKey(held.pid)]
+			if target != nil {
+				delete(target.monitoredBy, key)
+			}
+		} else {
+			signals = append(signals, signal{node: held.node, message: dist.DemonitorP{From: m.pid, To: held.to, Ref: held.ref}})
+		}
+	}
+	m.links = nil
+	m.monitors = nil
+	m.monitoredBy = nil
+	n.mutex.Unlock()
+	n.signals(signals)
+
+	m.queueMutex.Lock()
+	m.queueClosed = true
+	m.queueCond.Signal()
+	m.queueMutex.Unlock()
+	close(m.done)
+	return nil
+}
+
+// nodeDown handles the loss of a node connection
+// (the node mutex is locked)
+func (m *Mailbox) nodeDown(node string) {
+	noconnection := erlang.OtpErlangAtom("noconnection")
+	for key, pid := range m.links {
+		if pidNode(pid) == node {
+			delete(m.links, key)
+			m.deliver(exitMessage(pid, noconnection))
+		}
+	}
+	for key, held := range m.monitors {
+		if held.node == node {
+			delete(m.monitors, key)
+			m.deliver(downMessage(held.ref, held.object, noconnection))
+		}
+	}
+	for key, watcher := range m.monitoredBy {
+		if pidNode(watcher.pid) == node {
+			delete(m.monitoredBy, key)
+		}
+	}
+}
+
+// deliver adds a message to the queue
+func (m *Mailbox) deliver(message interface{}) {
+	m.queueMutex.Lock()
+	if !m.queueClosed {
+		m.queue = append(m.queue, message)
+		m.queueCond.Signal()
+	}
+	m.queueMutex.Unlock()
+}
+
+// pump provides queued messages to the receive channel
+// (the queue is unbounded like an Erlang process message queue)
+func (m *Mailbox) pump() {
+	for {
+		m.queueMutex.Lock()
+		for len(m.queue) == 0 && !m.queueClosed {
+			m.queueCond.Wait()
+		}
+		if m.queueClosed {
+			m.queue = nil
+			m.queueMutex.Unlock()
+			close(m.out)
+			return
+		}
+		message := m.queue[0]
+		m.queue[0] = nil
+		m.queue = m.queue[1:]
+		m.queueMutex.Unlock()
+		select {
+		case m.out <- message:
+		case <-m.done:
+			close(m.out)
+			return
+		}
+	}
+}
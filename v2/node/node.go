@@ -0,0 +1,589 @@
+// Package node provides an Erlang node with mailboxes for Go
+package node
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okeuday/erlang_go/v2/dist"
+	"github.com/okeuday/erlang_go/v2/epmd"
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+const (
+	tickTimeDefault  = 60 * time.Second
+	handshakeTimeout = 7 * time.Second // net_setuptime
+
+	tagSmallAtomUTF8Ext = 119
+)
+
+// Node errors
+var (
+	ErrConnect       = errors.New("node connection failed")
+	ErrMailboxClosed = errors.New("mailbox closed")
+	ErrName          = errors.New("invalid node name")
+	ErrNameInUse     = errors.New("name already registered")
+	ErrNotRegistered = errors.New("name not registered")
+	ErrStopped       = errors.New("node stopped")
+	ErrDestination   = errors.New("invalid destination")
+)
+
+// Config is the configuration of a Node
+type Config struct {
+	Name     string // "name@host", with the short hostname if "@host" is missing
+	Cookie   string
+	Hidden   bool
+	Flags    uint64        // 0 is dist.FlagsDefault
+	Listen   string        // listen address, "" is ":0"
+	EPMD     string        // EPMD address used for all hosts, "" is the node host EPMD
+	TickTime time.Duration // net_ticktime, 0 is 60 seconds
+}
+
+// Node is an Erlang node that owns Go mailboxes
+type Node struct {
+	config       Config
+	name         string
+	creation     uint32
+	listener     net.Listener
+	registration *epmd.Registration
+	mutex        sync.Mutex
+	mailboxes    map[string]*Mailbox
+	names        map[string]*Mailbox
+	conns        map[string]*connection
+	id           uint32
+	refID        uint64
+	stopped      bool
+}
+
+type connection struct {
+	conn *dist.Conn
+	name string
+	done chan struct{}
+}
+
+// Start starts a Node that is registered with EPMD
+func Start(config Config) (*Node, error) {
+	if config.Name == "" {
+		return nil, ErrName
+	}
+	if !strings.Contains(config.Name, "@") {
+		host, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		config.Name += "@" + strings.SplitN(host, ".", 2)[0]
+	}
+	alive, _, err := nodeSplit(config.Name)
+	if err != nil {
+		return nil, err
+	}
+	if config.TickTime <= 0 {
+		config.TickTime = tickTimeDefault
+	}
+	listen := config.Listen
+	if listen == "" {
+		listen = ":0"
+	}
+	n := &Node{
+		config:    config,
+		name:      config.Name,
+		mailboxes: make(map[string]*Mailbox),
+		names:     make(map[string]*Mailbox),
+		conns:     make(map[string]*connection),
+	}
+	n.listener, err = net.Listen("tcp", listen)
+	if err != nil {
+		return nil, err
+	}
+	nodeType := uint8(epmd.NodeTypeNormal)
+	if config.Hidden {
+		nodeType = epmd.NodeTypeHidden
+	}
+	n.registration, err = n.epmdClient("localhost").Register(epmd.Node{
+		Name:           alive,
+		Port:           uint16(n.listener.Addr().(*net.TCPAddr).Port),
+		NodeType:       nodeType,
+		Protocol:       epmd.ProtocolTCP,
+		HighestVersion: 6,
+		LowestVersion:  5,
+	})
+	if err != nil {
+		n.listener.Close()
+		return nil, err
+	}
+	n.creation = n.registration.Creation
+	go n.accept()
+	return n, nil
+}
+
+// Name provides the node name
+func (n *Node) Name() string {
+	return n.name
+}
+
+// Creation provides the node creation from EPMD
+func (n *Node) Creation() uint32 {
+	return n.creation
+}
+
+// Stop closes all connections and mailboxes before unregistering from EPMD
+func (n *Node) Stop() error {
+	n.mutex.Lock()
+	if n.stopped {
+		n.mutex.Unlock()
+		return ErrStopped
+	}
+	n.stopped = true
+	mailboxes := make([]*Mailbox, 0, len(n.mailboxes))
+	for _, mailbox := range n.mailboxes {
+		mailboxes = append(mailboxes, mailbox)
+	}
+	conns := make([]*connection, 0, len(n.conns))
+	for _, c := range n.conns {
+		conns = append(conns, c)
+	}
+	n.mutex.Unlock()
+	for _, mailbox := range mailboxes {
+		mailbox.Exit(erlang.OtpErlangAtom("shutdown"))
+	}
+	for _, c := range conns {
+		c.conn.Close()
+	}
+	n.listener.Close()
+	return n.registration.Close()
+}
+
+// Mailbox creates a mailbox, registered with the name if it is not empty
+func (n *Node) Mailbox(name string) (*Mailbox, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.stopped {
+		return nil, ErrStopped
+	}
+	if name != "" {
+		_, exists := n.names[name]
+		if exists {
+			return nil, ErrNameInUse
+		}
+	}
+	n.id += 1
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], n.id)
+	m := mailboxNew(n, erlang.OtpErlangPid{
+		NodeTag:  tagSmallAtomUTF8Ext,
+		Node:     n.nodeAtom(),
+		ID:       id[:],
+		Serial:   []byte{0, 0, 0, 0},
+		Creation: n.creationBytes(),
+	})
+	m.name = name
+	n.mailboxes[m.key] = m
+	if name != "" {
+		n.names[name] = m
+	}
+	return m, nil
+}
+
+// Whereis provides the pid of a registered name
+func (n *Node) Whereis(name string) (erlang.OtpErlangPid, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	m, exists := n.names[name]
+	if !exists {
+		return erlang.OtpErlangPid{}, false
+	}
+	return m.pid, true
+}
+
+// Connect connects to the node if it is not already connected
+func (n *Node) Connect(name string) error {
+	_, err := n.connection(name)
+	return err
+}
+
+// Nodes provides the names of connected nodes
+func (n *Node) Nodes() []string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	names := make([]string, 0, len(n.conns))
+	for name := range n.conns {
+		names = append(names, name)
+	}
+	return names
+}
+
+// nodeAtom provides the SMALL_ATOM_UTF8_EXT data of the node name
+// (nodeSplit limits the node name to 255 bytes)
+func (n *Node) nodeAtom() []byte {
+	return append([]byte{uint8(len(n.name))}, n.name...)
+}
+
+func (n *Node) creationBytes() []byte {
+	var creation [4]byte
+	binary.BigEndian.PutUint32(creation[:], n.creation)
+	return creation[:]
+}
+
+func (n *Node) makeRef() erlang.OtpErlangReference {
+	n.mutex.Lock()
+	n.refID += 1
+	id := n.refID
+	n.mutex.Unlock()
+	value := make([]byte, 12)
+	binary.BigEndian.PutUint32(value[0:4], uint32(id)&0x3ffff)
+	binary.BigEndian.PutUint32(value[4:8], uint32(id>>18))
+	binary.BigEndian.PutUint32(value[8:12], uint32(id>>50))
+	return erlang.OtpErlangReference{
+		NodeTag:  tagSmallAtomUTF8Ext,
+		Node:     n.nodeAtom(),
+		ID:       value,
+		Creation: n.creationBytes(),
+	}
+}
+
+func (n *Node) epmdClient(host string) *epmd.Client {
+	var client *epmd.Client
+	if n.config.EPMD != "" {
+		client = &epmd.Client{Address: n.config.EPMD}
+	} else {
+		client = epmd.NewClient(host)
+	}
+	client.Timeout = handshakeTimeout
+	return client
+}
+
+func (n *Node) distConfig() dist.Config {
+	return dist.Config{
+		Name:     n.name,
+		Cookie:   n.config.Cookie,
+		Flags:    n.config.Flags,
+		Creation: n.creation,
+		Hidden:   n.config.Hidden,
+	}
+}
+
+func (n *Node) accept() {
+	for {
+		conn, err := n.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			conn.SetDeadline(time.Now().Add(handshakeTimeout))
+			peer, err := dist.Accept(conn, n.distConfig())
+			if err != nil {
+				conn.Close()
+				return
+			}
+			conn.SetDeadline(time.Time{})
+			n.connectionUp(conn, peer)
+		}()
+	}
+}
+
+// connection provides the connection to the node, connecting if necessary
+func (n *Node) connection(name string) (*connection, error) {
+	n.mutex.Lock()
+	c, exists := n.conns[name]
+	stopped := n.stopped
+	n.mutex.Unlock()
+	if exists {
+		return c, nil
+	}
+	if stopped {
+		return nil, ErrStopped
+	}
+	if name == n.name {
+		return nil, ErrConnect
+	}
+	alive, host, err := nodeSplit(name)
+	if err != nil {
+		return nil, err
+	}
+	var node epmd.Node
+	node, err = n.epmdClient(host).PortPlease(alive)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	conn, err = net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(int(node.Port))), handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	config := n.distConfig()
+	if node.HighestVersion < 6 {
+		config.Version = 5
+	}
+	var peer dist.Peer
+	peer, err = dist.Connect(conn, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	if peer.Name != name {
+		conn.Close()
+		return nil, ErrConnect
+	}
+	c = n.connectionUp(conn, peer)
+	if c == nil {
+		return nil, ErrConnect
+	}
+	return c, nil
+}
+
+func (n *Node) connectionUp(conn net.Conn, peer dist.Peer) *connection {
+	c := &connection{
+		conn: dist.NewConn(conn, peer),
+		name: peer.Name,
+		done: make(chan struct{}),
+	}
+	c.conn.TickTimeout = n.config.TickTime
+	n.mutex.Lock()
+	existing, exists := n.conns[peer.Name]
+	if n.stopped || exists {
+		n.mutex.Unlock()
+		conn.Close()
+		return existing
+	}
+	n.conns[peer.Name] = c
+	n.mutex.Unlock()
+	go n.receive(c)
+	go n.tick(c)
+	return c
+}
+
+func (n *Node) connectionDown(c *connection) {
+	c.conn.Close()
+	n.mutex.Lock()
+	if n.conns[c.name] == c {
+		delete(n.conns, c.name)
+	}
+	for _, m := range n.mailboxes {
+		m.nodeDown(c.name)
+	}
+	n.mutex.Unlock()
+	close(c.done)
+}
+
+func (n *Node) tick(c *connection) {
+	ticker := time.NewTicker(n.config.TickTime / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := c.conn.Tick()
+			if err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (n *Node) receive(c *connection) {
+	for {
+		message, err := c.conn.Receive()
+		if err != nil {
+			break
+		}
+		n.dispatch(c, message)
+	}
+	n.connectionDown(c)
+}
+
+// dispatch handles a control message from a connection
+func (n *Node) dispatch(c *connection, messageI dist.Message) {
+	noproc := erlang.OtpErlangAtom("noproc")
+	var signals []signal
+	n.mutex.Lock()
+	switch message := messageI.(type) {
+	case dist.Send:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			m.deliver(message.Message)
+		}
+	case dist.SendSender:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			m.deliver(message.Message)
+		}
+	case dist.RegSend:
+		m := n.names[message.ToName]
+		if m != nil {
+			m.deliver(message.Message)
+		}
+	case dist.Link:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			m.links[pidKey(message.From)] = message.From
+		} else {
+			signals = append(signals, signal{to: message.From, message: dist.Exit{From: message.To, To: message.From, Reason: noproc}})
+		}
+	case dist.Unlink:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			delete(m.links, pidKey(message.From))
+		}
+	case dist.UnlinkID:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			delete(m.links, pidKey(message.From))
+		}
+		signals = append(signals, signal{to: message.From, message: dist.UnlinkIDAck{ID: message.ID, From: message.To, To: message.From}})
+	case dist.Exit:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			delete(m.links, pidKey(message.From))
+			m.deliver(exitMessage(message.From, message.Reason))
+		}
+	case dist.Exit2:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			m.deliver(exitMessage(message.From, message.Reason))
+		}
+	case dist.MonitorP:
+		m := n.monitorTarget(message.To)
+		if m != nil {
+			m.monitoredBy[refKey(message.Ref)] = monitor{ref: message.Ref, pid: message.From, to: message.To, object: message.To}
+		} else {
+			signals = append(signals, signal{to: message.From, message: dist.MonitorPExit{From: message.To, To: message.From, Ref: message.Ref, Reason: noproc}})
+		}
+	case dist.DemonitorP:
+		m := n.monitorTarget(message.To)
+		if m != nil {
+			delete(m.monitoredBy, refKey(message.Ref))
+		}
+	case dist.MonitorPExit:
+		m := n.mailboxes[pidKey(message.To)]
+		if m != nil {
+			held, exists := m.monitors[refKey(message.Ref)]
+			if exists {
+				delete(m.monitors, refKey(message.Ref))
+				m.deliver(downMessage(message.Ref, held.object, message.Reason))
+			}
+		}
+	case dist.SpawnRequest:
+		signals = append(signals, signal{to: message.From, message: dist.SpawnReply{ReqID: message.ReqID, To: message.From, Result: erlang.OtpErlangAtom("notsup")}})
+	}
+	n.mutex.Unlock()
+	n.signals(signals)
+}
+
+func (n *Node) monitorTarget(to interface{}) *Mailbox {
+	switch target := to.(type) {
+	case erlang.OtpErlangPid:
+		return n.mailboxes[pidKey(target)]
+	default:
+		name, ok := atomName(target)
+		if !ok {
+			return nil
+		}
+		return n.names[name]
+	}
+}
+
+// signal is a control message sent without the node mutex
+type signal struct {
+	to      erlang.OtpErlangPid
+	node    string // used when to is not a pid
+	message dist.Message
+}
+
+func (n *Node) signals(signals []signal) {
+	for _, s := range signals {
+		node := s.node
+		if node == "" {
+			node = pidNode(s.to)
+		}
+		n.send(node, s.message)
+	}
+}
+
+// send sends a control message to a remote node
+func (n *Node) send(node string, message dist.Message) error {
+	c, err := n.connection(node)
+	if err != nil {
+		return err
+	}
+	return c.conn.Send(message)
+}
+
+func exitMessage(from erlang.OtpErlangPid, reason interface{}) interface{} {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom("EXIT"), from, reason}
+}
+
+func downMessage(ref erlang.OtpErlangReference, object, reason interface{}) interface{} {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom("DOWN"), ref, erlang.OtpErlangAtom("process"), object, reason}
+}
+
+// nodeSplit provides the alive name and host of a node name
+// (the node name is an atom of at most 255 bytes)
+func nodeSplit(name string) (string, string, error) {
+	i := strings.IndexByte(name, '@')
+	if i <= 0 || i == len(name)-1 || len(name) > math.MaxUint8 {
+		return "", "", ErrName
+	}
+	return name[:i], name[i+1:], nil
+}
+
+// pidNode provides the node name of a pid
+func pidNode(pid erlang.OtpErlangPid) string {
+	return erlang.NodeName(pid.NodeTag, pid.Node)
+}
+
+// pidKey provides a map key for a pid that ignores the node atom encoding
+func pidKey(pid erlang.OtpErlangPid) string {
+	return pidNode(pid) + "\x00" + string(pid.ID) + string(pid.Serial) + string(pid.Creation)
+}
+
+func refKey(ref erlang.OtpErlangReference) string {
+	return erlang.NodeName(ref.NodeTag, ref.Node) + "\x00" + string(ref.ID) + string(ref.Creation)
+}
+
+func atomName(term interface{}) (string, bool) {
+	switch value := term.(type) {
+	case erlang.OtpErlangAtom:
+		return string(value), true
+	case erlang.OtpErlangAtomUTF8:
+		return string(value), true
+	case string:
+		return value, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,209 @@
+package node
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/okeuday/erlang_go/v2/epmd"
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func receive(t *testing.T, m *Mailbox) interface{} {
+	t.Helper()
+	select {
+	case message := <-m.Receive():
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive timeout")
+		return nil
+	}
+}
+
+func testNodes(t *testing.T) (*Node, *Node) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assertEqual(t, nil, err, "")
+	go epmd.NewServer().Serve(listener)
+	config := Config{
+		Cookie: "cookie",
+		Listen: "127.0.0.1:0",
+		EPMD:   listener.Addr().String(),
+	}
+	config.Name = "a@127.0.0.1"
+	a, err := Start(config)
+	assertEqual(t, nil, err, "")
+	config.Name = "b@127.0.0.1"
+	config.Hidden = true
+	b, err := Start(config)
+	assertEqual(t, nil, err, "")
+	return a, b
+}
+
+func TestNodeName(t *testing.T) {
+	// the node name must fit in SMALL_ATOM_UTF8_EXT
+	_, err := Start(Config{Name: strings.Repeat("a", 251) + "@host"})
+	assertEqual(t, ErrName, err, "")
+	_, err = Start(Config{Name: "a@"})
+	assertEqual(t, ErrName, err, "")
+}
+
+func TestNodeLocal(t *testing.T) {
+	a, b := testNodes(t)
+	defer b.Stop()
+	m1, err := a.Mailbox("")
+	assertEqual(t, nil, err, "")
+	m2, err := a.Mailbox("m2")
+	assertEqual(t, nil, err, "")
+	_, err = a.Mailbox("m2")
+	assertEqual(t, ErrNameInUse, err, "")
+	pid, ok := a.Whereis("m2")
+	assertEqual(t, true, ok, "")
+	assertEqual(t, m2.Pid(), pid, "")
+	assertEqual(t, "a@127.0.0.1", pidNode(pid), "")
+
+	assertEqual(t, nil, m1.Send(m2.Pid(), uint8(1)), "")
+	assertEqual(t, uint8(1), receive(t, m2), "")
+	assertEqual(t, nil, m1.Send("m2", uint8(2)), "")
+	assertEqual(t, uint8(2), receive(t, m2), "")
+	assertEqual(t, nil, m1.Send(erlang.OtpErlangTuple{erlang.OtpErlangAtom("m2"), erlang.OtpErlangAtom("a@127.0.0.1")}, uint8(3)), "")
+	assertEqual(t, uint8(3), receive(t, m2), "")
+	assertEqual(t, ErrNotRegistered, m1.Send("missing", uint8(4)), "")
+
+	assertEqual(t, nil, m1.Link(m2.Pid()), "")
+	ref, err := m1.Monitor("m2")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, m2.Exit(erlang.OtpErlangAtom("crash")), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("EXIT"), m2.Pid(), erlang.OtpErlangAtom("crash")}, receive(t, m1), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("DOWN"), ref, erlang.OtpErlangAtom("process"),
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("m2"), erlang.OtpErlangAtom("a@127.0.0.1")}, erlang.OtpErlangAtom("crash")}, receive(t, m1), "")
+	_, ok = a.Whereis("m2")
+	assertEqual(t, false, ok, "")
+	_, ok = <-m2.Receive()
+	assertEqual(t, false, ok, "")
+
+	assertEqual(t, nil, a.Stop(), "")
+	_, ok = <-m1.Receive()
+	assertEqual(t, false, ok, "")
+	assertEqual(t, ErrStopped, a.Stop(), "")
+}
+
+func TestNodeRemote(t *testing.T) {
+	a, b := testNodes(t)
+	defer a.Stop()
+	ma, err := a.Mailbox("")
+	assertEqual(t, nil, err, "")
+	mb, err := b.Mailbox("server")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "a@127.0.0.1", a.Name(), "")
+	assertEqual(t, true, a.Creation() != 0, "")
+
+	// send by name and pid
+	assertEqual(t, nil, ma.Send(erlang.OtpErlangTuple{erlang.OtpErlangAtom("server"), erlang.OtpErlangAtom("b@127.0.0.1")}, ma.Pid()), "")
+	pid := receive(t, mb).(erlang.OtpErlangPid)
+	assertEqual(t, ma.Pid(), pid, "")
+	assertEqual(t, nil, mb.Send(pid, []byte("reply")), "")
+	assertEqual(t, erlang.OtpErlangBinary{Value: []byte("reply"), Bits: 8}, receive(t, ma), "")
+	assertEqual(t, []string{"b@127.0.0.1"}, a.Nodes(), "")
+
+	// remote link, with a message to make sure the link arrived
+	mb2, err := b.Mailbox("")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, ma.Link(mb2.Pid()), "")
+	assertEqual(t, nil, ma.Send(mb2.Pid(), uint8(1)), "")
+	assertEqual(t, uint8(1), receive(t, mb2), "")
+	assertEqual(t, nil, mb2.Exit(erlang.OtpErlangAtom("crash")), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("EXIT"), mb2.Pid(), erlang.OtpErlangAtom("crash")}, receive(t, ma), "")
+
+	// remote monitors
+	ref, err := ma.Monitor(mb.Pid())
+	assertEqual(t, nil, err, "")
+	ref2, err := ma.Monitor(erlang.OtpErlangTuple{erlang.OtpErlangAtom("missing"), erlang.OtpErlangAtom("b@127.0.0.1")})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("DOWN"), ref2, erlang.OtpErlangAtom("process"),
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("missing"), erlang.OtpErlangAtom("b@127.0.0.1")}, erlang.OtpErlangAtom("noproc")}, receive(t, ma), "")
+	assertEqual(t, nil, mb.Close(), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("DOWN"), ref, erlang.OtpErlangAtom("process"),
+		mb.Pid(), erlang.OtpErlangAtom("normal")}, receive(t, ma), "")
+
+	// connection loss
+	mb3, err := b.Mailbox("")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, ma.Link(mb3.Pid()), "")
+	ref, err = ma.Monitor(mb3.Pid())
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, ma.Send(mb3.Pid(), uint8(1)), "")
+	assertEqual(t, uint8(1), receive(t, mb3), "")
+	assertEqual(t, nil, b.Stop(), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("EXIT"), mb3.Pid(), erlang.OtpErlangAtom("shutdown")}, receive(t, ma), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("DOWN"), ref, erlang.OtpErlangAtom("process"),
+		mb3.Pid(), erlang.OtpErlangAtom("shutdown")}, receive(t, ma), "")
+	mb4, err := a.Mailbox("")
+	assertEqual(t, nil, err, "")
+	ref, err = mb4.Monitor(mb3.Pid())
+	assertEqual(t, nil, err, "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("DOWN"), ref, erlang.OtpErlangAtom("process"),
+		mb3.Pid(), erlang.OtpErlangAtom("noconnection")}, receive(t, mb4), "")
+}
+
+func TestNodeConnectionLoss(t *testing.T) {
+	a, b := testNodes(t)
+	defer a.Stop()
+	defer b.Stop()
+	ma, err := a.Mailbox("")
+	assertEqual(t, nil, err, "")
+	mb, err := b.Mailbox("")
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, ma.Link(mb.Pid()), "")
+	ref, err := ma.Monitor(mb.Pid())
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, ma.Send(mb.Pid(), uint8(1)), "")
+	assertEqual(t, uint8(1), receive(t, mb), "")
+	a.mutex.Lock()
+	c := a.conns["b@127.0.0.1"]
+	a.mutex.Unlock()
+	c.conn.Close()
+	messages := []interface{}{receive(t, ma), receive(t, ma)}
+	exit := erlang.OtpErlangTuple{erlang.OtpErlangAtom("EXIT"), mb.Pid(), erlang.OtpErlangAtom("noconnection")}
+	down := erlang.OtpErlangTuple{erlang.OtpErlangAtom("DOWN"), ref, erlang.OtpErlangAtom("process"), mb.Pid(), erlang.OtpErlangAtom("noconnection")}
+	if !reflect.DeepEqual(messages, []interface{}{exit, down}) {
+		assertEqual(t, []interface{}{down, exit}, messages, "")
+	}
+}
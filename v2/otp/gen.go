@@ -0,0 +1,184 @@
+// Package otp provides the message formats of Erlang/OTP behaviours
+package otp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+const (
+	atomGenCall = "$gen_call"
+	atomGenCast = "$gen_cast"
+	atomSystem  = "system"
+	atomAlias   = "alias"
+)
+
+// From is the {Pid, Tag} of a gen_server call or system message
+// (Tag is a reference or [alias | Ref] since Erlang/OTP 24)
+type From struct {
+	Pid erlang.OtpErlangPid
+	Tag interface{}
+}
+
+// Term provides the {Pid, Tag} tuple
+func (f From) Term() erlang.OtpErlangTuple {
+	return erlang.OtpErlangTuple{f.Pid, f.Tag}
+}
+
+// ReplyTo provides the destination of a reply,
+// the alias reference or the pid
+func (f From) ReplyTo() interface{} {
+	alias, ok := TagAlias(f.Tag)
+	if ok {
+		return alias
+	}
+	return f.Pid
+}
+
+// AliasTag provides the [alias | Ref] call tag
+func AliasTag(alias erlang.OtpErlangReference) interface{} {
+	return erlang.OtpErlangList{
+		Value:    []interface{}{erlang.OtpErlangAtom(atomAlias), alias},
+		Improper: true,
+	}
+}
+
+// TagAlias provides the alias of an [alias | Ref] call tag
+func TagAlias(tag interface{}) (erlang.OtpErlangReference, bool) {
+	list, ok := tag.(erlang.OtpErlangList)
+	if !ok || !list.Improper || len(list.Value) != 2 || !isAtom(list.Value[0], atomAlias) {
+		return erlang.OtpErlangReference{}, false
+	}
+	var alias erlang.OtpErlangReference
+	alias, ok = list.Value[1].(erlang.OtpErlangReference)
+	return alias, ok
+}
+
+// Call provides the {'$gen_call', From, Request} message
+func Call(from From, request interface{}) erlang.OtpErlangTuple {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom(atomGenCall), from.Term(), request}
+}
+
+// Cast provides the {'$gen_cast', Request} message
+func Cast(request interface{}) erlang.OtpErlangTuple {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom(atomGenCast), request}
+}
+
+// System provides the {system, From, Request} message
+func System(from From, request interface{}) erlang.OtpErlangTuple {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom(atomSystem), from.Term(), request}
+}
+
+// Reply provides the {Tag, Reply} message sent to From.ReplyTo()
+func Reply(from From, reply interface{}) erlang.OtpErlangTuple {
+	return erlang.OtpErlangTuple{from.Tag, reply}
+}
+
+// ParseCall provides the From and Request of a '$gen_call' message
+func ParseCall(message interface{}) (From, interface{}, bool) {
+	return parseFromRequest(message, atomGenCall)
+}
+
+// ParseCast provides the Request of a '$gen_cast' message
+func ParseCast(message interface{}) (interface{}, bool) {
+	tuple, ok := message.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) != 2 || !isAtom(tuple[0], atomGenCast) {
+		return nil, false
+	}
+	return tuple[1], true
+}
+
+// ParseSystem provides the From and Request of a system message
+func ParseSystem(message interface{}) (From, interface{}, bool) {
+	return parseFromRequest(message, atomSystem)
+}
+
+// ParseReply provides the Reply of a {Tag, Reply} message
+// when it matches the call tag
+func ParseReply(message interface{}, tag interface{}) (interface{}, bool) {
+	tuple, ok := message.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) != 2 || !TagEqual(tuple[0], tag) {
+		return nil, false
+	}
+	return tuple[1], true
+}
+
+// TagEqual compares call tags, ignoring how the atoms were encoded
+func TagEqual(tag1, tag2 interface{}) bool {
+	alias1, ok1 := TagAlias(tag1)
+	alias2, ok2 := TagAlias(tag2)
+	if ok1 || ok2 {
+		return ok1 && ok2 && referenceEqual(alias1, alias2)
+	}
+	ref1, ok1 := tag1.(erlang.OtpErlangReference)
+	ref2, ok2 := tag2.(erlang.OtpErlangReference)
+	return ok1 && ok2 && referenceEqual(ref1, ref2)
+}
+
+func parseFromRequest(message interface{}, name string) (From, interface{}, bool) {
+	tuple, ok := message.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) != 3 || !isAtom(tuple[0], name) {
+		return From{}, nil, false
+	}
+	var from From
+	from, ok = parseFrom(tuple[1])
+	if !ok {
+		return From{}, nil, false
+	}
+	return from, tuple[2], true
+}
+
+func parseFrom(term interface{}) (From, bool) {
+	tuple, ok := term.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) != 2 {
+		return From{}, false
+	}
+	var pid erlang.OtpErlangPid
+	pid, ok = tuple[0].(erlang.OtpErlangPid)
+	if !ok {
+		return From{}, false
+	}
+	return From{Pid: pid, Tag: tuple[1]}, true
+}
+
+func isAtom(term interface{}, name string) bool {
+	switch value := term.(type) {
+	case erlang.OtpErlangAtom:
+		return string(value) == name
+	case erlang.OtpErlangAtomUTF8:
+		return string(value) == name
+	default:
+		return false
+	}
+}
+
+func referenceEqual(ref1, ref2 erlang.OtpErlangReference) bool {
+	return erlang.NodeName(ref1.NodeTag, ref1.Node) == erlang.NodeName(ref2.NodeTag, ref2.Node) &&
+		bytes.Equal(ref1.ID, ref2.ID) &&
+		bytes.Equal(ref1.Creation, ref2.Creation)
+}
@@ -0,0 +1,118 @@
+package otp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"reflect"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	term, err := erlang.BinaryToTerm([]byte(data))
+	assertEqual(t, nil, err, "")
+	return term
+}
+
+func roundTrip(t *testing.T, term interface{}) interface{} {
+	t.Helper()
+	data, err := erlang.TermToBinary(term, -1)
+	assertEqual(t, nil, err, "")
+	return decode(t, string(data))
+}
+
+const (
+	pidData = "Xw\x0dnonode@nohost\x00\x00\x00\x53\x00\x00\x00\x00\x00\x00\x00\x00"
+	refData = "Z\x00\x03w\x0dnonode@nohost\x00\x00\x00\x00\x00\x01\xac\x03\xc7\x00\x00\x04\xbb\xb2\xca\xee"
+)
+
+func TestCall(t *testing.T) {
+	pid := decode(t, "\x83"+pidData).(erlang.OtpErlangPid)
+	ref := decode(t, "\x83"+refData).(erlang.OtpErlangReference)
+
+	// {'$gen_call', {Pid, [alias | Ref]}, ping} from Erlang/OTP 24 or later
+	from, request, ok := ParseCall(decode(t, "\x83h\x03w\x09$gen_callh\x02"+pidData+"l\x00\x00\x00\x01w\x05alias"+refData+"w\x04ping"))
+	assertEqual(t, true, ok, "")
+	assertEqual(t, erlang.OtpErlangAtomUTF8("ping"), request, "")
+	assertEqual(t, pid, from.Pid, "")
+	assertEqual(t, ref, from.ReplyTo(), "")
+	assertEqual(t, true, TagEqual(AliasTag(ref), from.Tag), "")
+	assertEqual(t, false, TagEqual(ref, from.Tag), "")
+
+	from = From{Pid: pid, Tag: AliasTag(ref)}
+	from2, request, ok := ParseCall(roundTrip(t, Call(from, uint8(1))))
+	assertEqual(t, true, ok, "")
+	assertEqual(t, uint8(1), request, "")
+	assertEqual(t, true, TagEqual(from.Tag, from2.Tag), "")
+	reply, ok := ParseReply(roundTrip(t, Reply(from2, erlang.OtpErlangAtom("ok"))), from.Tag)
+	assertEqual(t, true, ok, "")
+	assertEqual(t, erlang.OtpErlangAtom("ok"), reply, "")
+
+	// a reference tag from Erlang/OTP 23 or earlier
+	from = From{Pid: pid, Tag: ref}
+	assertEqual(t, pid, from.ReplyTo(), "")
+	_, ok = ParseReply(erlang.OtpErlangTuple{ref, uint8(2)}, AliasTag(ref))
+	assertEqual(t, false, ok, "")
+	reply, ok = ParseReply(erlang.OtpErlangTuple{ref, uint8(2)}, ref)
+	assertEqual(t, true, ok, "")
+	assertEqual(t, uint8(2), reply, "")
+
+	_, _, ok = ParseCall(erlang.OtpErlangTuple{erlang.OtpErlangAtom("$gen_call"), pid, uint8(1)})
+	assertEqual(t, false, ok, "")
+	_, _, ok = ParseCall(Cast(uint8(1)))
+	assertEqual(t, false, ok, "")
+}
+
+func TestCastSystem(t *testing.T) {
+	pid := decode(t, "\x83"+pidData).(erlang.OtpErlangPid)
+	ref := decode(t, "\x83"+refData).(erlang.OtpErlangReference)
+	request, ok := ParseCast(roundTrip(t, Cast(uint8(1))))
+	assertEqual(t, true, ok, "")
+	assertEqual(t, uint8(1), request, "")
+	_, ok = ParseCast(uint8(1))
+	assertEqual(t, false, ok, "")
+
+	from := From{Pid: pid, Tag: AliasTag(ref)}
+	from2, request, ok := ParseSystem(roundTrip(t, System(from, erlang.OtpErlangAtom("get_state"))))
+	assertEqual(t, true, ok, "")
+	assertEqual(t, erlang.OtpErlangAtom("get_state"), request, "")
+	assertEqual(t, pid, from2.Pid, "")
+	_, _, ok = ParseSystem(Call(from, uint8(1)))
+	assertEqual(t, false, ok, "")
+}
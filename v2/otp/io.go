@@ -0,0 +1,827 @@
+package otp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+const (
+	atomIORequest = "io_request"
+	atomIOReply   = "io_reply"
+)
+
+var errFormat = errors.New("format")
+
+// IOServer is an Erlang I/O device (e.g., a group leader) that
+// writes output to a Go io.Writer and reads input from a Go io.Reader
+type IOServer struct {
+	output   io.Writer
+	input    *bufio.Reader
+	binary   bool
+	encoding string
+
+	// GetUntil handles get_until requests that are not io_lib functions
+	// (nil provides {error, enotsup})
+	GetUntil func(module, function string, args []interface{}, input *bufio.Reader) interface{}
+}
+
+// NewIOServer creates an IOServer (input may be nil)
+func NewIOServer(input io.Reader, output io.Writer) *IOServer {
+	s := &IOServer{output: output, encoding: "unicode"}
+	if input != nil {
+		s.input = bufio.NewReader(input)
+	}
+	return s
+}
+
+// ParseIORequest provides the parts of an
+// {io_request, From, ReplyAs, Request} message
+func ParseIORequest(message interface{}) (erlang.OtpErlangPid, interface{}, interface{}, bool) {
+	tuple, ok := message.(erlang.OtpErlangTuple)
+	if !ok || len(tuple) != 4 || !isAtom(tuple[0], atomIORequest) {
+		return erlang.OtpErlangPid{}, nil, nil, false
+	}
+	var from erlang.OtpErlangPid
+	from, ok = tuple[1].(erlang.OtpErlangPid)
+	if !ok {
+		return erlang.OtpErlangPid{}, nil, nil, false
+	}
+	return from, tuple[2], tuple[3], true
+}
+
+// IOReply provides the {io_reply, ReplyAs, Reply} message
+func IOReply(replyAs, reply interface{}) erlang.OtpErlangTuple {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom(atomIOReply), replyAs, reply}
+}
+
+// Handle processes an io_request message, providing the pid that
+// needs the io_reply message
+func (s *IOServer) Handle(message interface{}) (erlang.OtpErlangPid, erlang.OtpErlangTuple, bool) {
+	from, replyAs, request, ok := ParseIORequest(message)
+	if !ok {
+		return erlang.OtpErlangPid{}, nil, false
+	}
+	return from, IOReply(replyAs, s.Request(request)), true
+}
+
+// Request processes an I/O request, providing the reply
+func (s *IOServer) Request(requestI interface{}) interface{} {
+	if isAtom(requestI, "getopts") {
+		return s.getopts()
+	}
+	request, ok := requestI.(erlang.OtpErlangTuple)
+	if !ok || len(request) == 0 {
+		return ioError("request")
+	}
+	name, _ := atomString(request[0])
+	args := request[1:]
+	switch name {
+	case "put_chars":
+		return s.putChars(args)
+	case "get_line":
+		encoding, prompt, ok := s.getArgs(args, 0)
+		if !ok {
+			return ioError("request")
+		}
+		return s.getLine(encoding, prompt)
+	case "get_chars":
+		encoding, prompt, ok := s.getArgs(args, 1)
+		if !ok {
+			return ioError("request")
+		}
+		count, ok := integer(args[len(args)-1])
+		if !ok || count < 0 {
+			return ioError("request")
+		}
+		return s.getChars(encoding, prompt, int(count))
+	case "get_until":
+		encoding, prompt, ok := s.getArgs(args, 3)
+		if !ok {
+			return ioError("request")
+		}
+		return s.getUntil(encoding, prompt, args[len(args)-3:])
+	case "setopts":
+		if len(args) != 1 {
+			return ioError("request")
+		}
+		return s.setopts(args[0])
+	case "requests":
+		if len(args) != 1 {
+			return ioError("request")
+		}
+		requests, ok := listElements(args[0])
+		if !ok {
+			return ioError("request")
+		}
+		var reply interface{} = erlang.OtpErlangAtom("ok")
+		for _, element := range requests {
+			reply = s.Request(element)
+			if isError(reply) {
+				break
+			}
+		}
+		return reply
+	case "get_geometry":
+		return ioError("enotsup")
+	default:
+		return ioError("request")
+	}
+}
+
+func (s *IOServer) putChars(args []interface{}) interface{} {
+	encoding := "latin1"
+	if len(args) == 2 || len(args) == 4 {
+		var ok bool
+		encoding, ok = atomString(args[0])
+		if !ok {
+			return ioError("request")
+		}
+		args = args[1:]
+	}
+	var text string
+	switch len(args) {
+	case 1:
+		var ok bool
+		text, ok = chardata(args[0], encoding)
+		if !ok {
+			return ioError("put_chars")
+		}
+	case 3:
+		module, _ := atomString(args[0])
+		function, _ := atomString(args[1])
+		if module != "io_lib" || function != "format" {
+			return ioError("request")
+		}
+		formatArgs, ok := listElements(args[2])
+		if !ok || len(formatArgs) != 2 {
+			return ioError("request")
+		}
+		var err error
+		text, err = Format(formatArgs[0], formatArgs[1])
+		if err != nil {
+			return ioError("format")
+		}
+	default:
+		return ioError("request")
+	}
+	_, err := io.WriteString(s.output, text)
+	if err != nil {
+		return ioError("terminated")
+	}
+	return erlang.OtpErlangAtom("ok")
+}
+
+// getArgs provides the encoding and prompt of a get request
+// with count other arguments
+func (s *IOServer) getArgs(args []interface{}, count int) (string, interface{}, bool) {
+	switch len(args) {
+	case count + 1:
+		return "latin1", args[0], true
+	case count + 2:
+		encoding, ok := atomString(args[0])
+		return encoding, args[1], ok
+	default:
+		return "", nil, false
+	}
+}
+
+func (s *IOServer) prompt(prompt interface{}) {
+	text, ok := chardata(prompt, "unicode")
+	if !ok {
+		if atom, isAtom := atomString(prompt); isAtom {
+			text = atom
+		}
+	}
+	if text != "" {
+		io.WriteString(s.output, text)
+	}
+}
+
+func (s *IOServer) getLine(encoding string, prompt interface{}) interface{} {
+	if s.input == nil {
+		return erlang.OtpErlangAtom("eof")
+	}
+	s.prompt(prompt)
+	line, err := s.input.ReadString('\n')
+	if err != nil && line == "" {
+		if err == io.EOF {
+			return erlang.OtpErlangAtom("eof")
+		}
+		return ioError("terminated")
+	}
+	return s.characters(line, encoding)
+}
+
+func (s *IOServer) getChars(encoding string, prompt interface{}, count int) interface{} {
+	if s.input == nil {
+		return erlang.OtpErlangAtom("eof")
+	}
+	s.prompt(prompt)
+	var text strings.Builder
+	for i := 0; i < count; i++ {
+		character, _, err := s.input.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return ioError("terminated")
+		}
+		text.WriteRune(character)
+	}
+	if text.Len() == 0 && count > 0 {
+		return erlang.OtpErlangAtom("eof")
+	}
+	return s.characters(text.String(), encoding)
+}
+
+func (s *IOServer) getUntil(encoding string, prompt interface{}, mfa []interface{}) interface{} {
+	module, _ := atomString(mfa[0])
+	function, _ := atomString(mfa[1])
+	args, ok := listElements(mfa[2])
+	if !ok {
+		return ioError("request")
+	}
+	if module == "io_lib" {
+		switch function {
+		case "collect_line":
+			return s.getLine(encoding, prompt)
+		case "collect_chars":
+			if len(args) == 1 {
+				count, ok := integer(args[0])
+				if ok && count >= 0 {
+					return s.getChars(encoding, prompt, int(count))
+				}
+			}
+			return ioError("request")
+		}
+	}
+	if s.GetUntil == nil || s.input == nil {
+		return ioError("enotsup")
+	}
+	s.prompt(prompt)
+	return s.GetUntil(module, function, args, s.input)
+}
+
+func (s *IOServer) setopts(optsI interface{}) interface{} {
+	opts, ok := listElements(optsI)
+	if !ok {
+		return ioError("request")
+	}
+	binary := s.binary
+	encoding := s.encoding
+	for _, opt := range opts {
+		if name, isAtom := atomString(opt); isAtom {
+			switch name {
+			case "binary":
+				binary = true
+			case "list":
+				binary = false
+			default:
+				return ioError("enotsup")
+			}
+			continue
+		}
+		tuple, isTuple := opt.(erlang.OtpErlangTuple)
+		if !isTuple || len(tuple) != 2 {
+			return ioError("enotsup")
+		}
+		name, _ := atomString(tuple[0])
+		switch name {
+		case "binary":
+			value, isBool := tuple[1].(bool)
+			if !isBool {
+				return ioError("enotsup")
+			}
+			binary = value
+		case "encoding":
+			value, _ := atomString(tuple[1])
+			if value == "utf8" {
+				value = "unicode"
+			}
+			if value != "unicode" && value != "latin1" {
+				return ioError("enotsup")
+			}
+			encoding = value
+		default:
+			return ioError("enotsup")
+		}
+	}
+	s.binary = binary
+	s.encoding = encoding
+	return erlang.OtpErlangAtom("ok")
+}
+
+func (s *IOServer) getopts() interface{} {
+	return erlang.OtpErlangList{Value: []interface{}{
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("binary"), s.binary},
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("encoding"), erlang.OtpErlangAtom(s.encoding)},
+	}}
+}
+
+// characters provides input data as a binary or a list,
+// depending on the binary option
+func (s *IOServer) characters(text, encoding string) interface{} {
+	if s.binary {
+		if encoding == "unicode" {
+			return erlang.OtpErlangBinary{Value: []byte(text), Bits: 8}
+		}
+		value := make([]byte, 0, len(text))
+		for _, character := range text {
+			if character > 0xff {
+				return ioError("no_translation")
+			}
+			value = append(value, byte(character))
+		}
+		return erlang.OtpErlangBinary{Value: value, Bits: 8}
+	}
+	ascii := true
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return text
+	}
+	var list []interface{}
+	for _, character := range text {
+		if encoding != "unicode" && character > 0xff {
+			return ioError("no_translation")
+		}
+		list = append(list, int(character))
+	}
+	return erlang.OtpErlangList{Value: list}
+}
+
+func ioError(reason string) interface{} {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"), erlang.OtpErlangAtom(reason)}
+}
+
+func isError(reply interface{}) bool {
+	tuple, ok := reply.(erlang.OtpErlangTuple)
+	return ok && len(tuple) == 2 && isAtom(tuple[0], "error")
+}
+
+func atomString(term interface{}) (string, bool) {
+	switch value := term.(type) {
+	case erlang.OtpErlangAtom:
+		return string(value), true
+	case erlang.OtpErlangAtomUTF8:
+		return string(value), true
+	case bool:
+		if value {
+			return "true", true
+		}
+		return "false", true
+	default:
+		return "", false
+	}
+}
+
+// listElements provides the elements of a proper list
+// (a string is a list of bytes)
+func listElements(term interface{}) ([]interface{}, bool) {
+	switch value := term.(type) {
+	case erlang.OtpErlangList:
+		if value.Improper {
+			return nil, false
+		}
+		return value.Value, true
+	case string:
+		elements := make([]interface{}, len(value))
+		for i := 0; i < len(value); i++ {
+			elements[i] = value[i]
+		}
+		return elements, true
+	default:
+		return nil, false
+	}
+}
+
+func integer(term interface{}) (int64, bool) {
+	switch value := term.(type) {
+	case uint8:
+		return int64(value), true
+	case int32:
+		return int64(value), true
+	case int:
+		return int64(value), true
+	case *big.Int:
+		if !value.IsInt64() {
+			return 0, false
+		}
+		return value.Int64(), true
+	default:
+		return 0, false
+	}
+}
+
+// chardata provides the text of Erlang chardata
+// (a binary, a string or a list of characters, binaries and lists)
+func chardata(term interface{}, encoding string) (string, bool) {
+	var text strings.Builder
+	if !chardataAppend(&text, term, encoding) {
+		return "", false
+	}
+	return text.String(), true
+}
+
+func chardataAppend(text *strings.Builder, termI interface{}, encoding string) bool {
+	switch term := termI.(type) {
+	case erlang.OtpErlangBinary:
+		if term.Bits != 8 {
+			return false
+		}
+		if encoding == "unicode" {
+			text.Write(term.Value)
+		} else {
+			for _, character := range term.Value {
+				text.WriteRune(rune(character))
+			}
+		}
+		return true
+	case string:
+		// STRING_EXT contains latin1 characters
+		for i := 0; i < len(term); i++ {
+			text.WriteRune(rune(term[i]))
+		}
+		return true
+	case erlang.OtpErlangList:
+		for _, element := range term.Value {
+			if !chardataAppend(text, element, encoding) {
+				return false
+			}
+		}
+		return true
+	default:
+		character, ok := integer(termI)
+		if !ok || character < 0 || character > utf8.MaxRune {
+			return false
+		}
+		text.WriteRune(rune(character))
+		return true
+	}
+}
+
+// Format provides the text of io_lib:format/2 for the common
+// control sequences (~c ~s ~w ~p ~W ~P ~b ~B ~x ~X ~# ~+ ~d ~e ~f ~g ~i ~n ~~)
+func Format(format interface{}, args interface{}) (string, error) {
+	formatText, ok := chardata(format, "unicode")
+	if !ok {
+		atom, isAtom := atomString(format)
+		if !isAtom {
+			return "", errFormat
+		}
+		formatText = atom
+	}
+	var argsList []interface{}
+	argsList, ok = listElements(args)
+	if !ok {
+		return "", errFormat
+	}
+	var text strings.Builder
+	characters := []rune(formatText)
+	for i := 0; i < len(characters); i++ {
+		if characters[i] != '~' {
+			text.WriteRune(characters[i])
+			continue
+		}
+		i += 1
+		var control formatControl
+		var err error
+		i, argsList, err = control.parse(characters, i, argsList)
+		if err != nil {
+			return "", err
+		}
+		argsList, err = control.write(&text, argsList)
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(argsList) > 0 {
+		return "", errFormat
+	}
+	return text.String(), nil
+}
+
+type formatControl struct {
+	width     int
+	precision int
+	pad       rune
+	left      bool
+	character rune
+}
+
+func (c *formatControl) parse(characters []rune, i int, args []interface{}) (int, []interface{}, error) {
+	c.pad = ' '
+	c.precision = -1
+	// field width, precision and padding character
+	for field := 0; field < 3 && i < len(characters); field++ {
+		if field == 2 {
+			if characters[i] == '*' {
+				return i, args, errFormat
+			}
+			c.pad = characters[i]
+			i += 1
+		} else {
+			value := -1
+			if characters[i] == '*' {
+				if len(args) == 0 {
+					return i, args, errFormat
+				}
+				number, ok := integer(args[0])
+				if !ok {
+					return i, args, errFormat
+				}
+				args = args[1:]
+				value = int(number)
+				i += 1
+			} else {
+				negative := false
+				if field == 0 && characters[i] == '-' {
+					negative = true
+					i += 1
+				}
+				start := i
+				for i < len(characters) && characters[i] >= '0' && characters[i] <= '9' {
+					i += 1
+				}
+				if i > start {
+					value, _ = strconv.Atoi(string(characters[start:i]))
+					if negative {
+						value = -value
+					}
+				}
+			}
+			if field == 0 {
+				if value < 0 && value != -1 {
+					c.left = true
+					value = -value
+				}
+				c.width = value
+			} else {
+				c.precision = value
+			}
+		}
+		if i >= len(characters) || characters[i] != '.' {
+			break
+		}
+		i += 1
+	}
+	// modifiers
+	for i < len(characters) && (characters[i] == 't' || characters[i] == 'l' || characters[i] == 'k') {
+		i += 1
+	}
+	if i >= len(characters) {
+		return i, args, errFormat
+	}
+	c.character = characters[i]
+	return i, args, nil
+}
+
+func (c *formatControl) write(text *strings.Builder, args []interface{}) ([]interface{}, error) {
+	var value string
+	arg := func() (interface{}, bool) {
+		if len(args) == 0 {
+			return nil, false
+		}
+		next := args[0]
+		args = args[1:]
+		return next, true
+	}
+	switch c.character {
+	case '~':
+		value = "~"
+	case 'n':
+		value = "\n"
+	case 'c':
+		term, ok := arg()
+		character, isInteger := integer(term)
+		if !ok || !isInteger {
+			return args, errFormat
+		}
+		// the count defaults to the field width
+		count := c.precision
+		if count < 0 {
+			count = c.width
+		}
+		if count <= 0 {
+			count = 1
+		}
+		value = strings.Repeat(string(rune(character)), count)
+	case 's':
+		term, ok := arg()
+		if !ok {
+			return args, errFormat
+		}
+		if atom, isAtom := atomString(term); isAtom {
+			value = atom
+		} else if value, ok = chardata(term, "unicode"); !ok {
+			return args, errFormat
+		}
+		if c.precision >= 0 && utf8.RuneCountInString(value) > c.precision {
+			value = string([]rune(value)[:c.precision])
+		}
+	case 'w', 'p':
+		term, ok := arg()
+		if !ok {
+			return args, errFormat
+		}
+		value = erlang.TermString(term)
+	case 'W', 'P':
+		term, ok := arg()
+		if !ok {
+			return args, errFormat
+		}
+		if _, ok = arg(); !ok {
+			return args, errFormat
+		}
+		value = erlang.TermString(term)
+	case 'i':
+		if _, ok := arg(); !ok {
+			return args, errFormat
+		}
+	case 'd', 'b', 'B', 'x', 'X', '#', '+':
+		term, ok := arg()
+		number := bigInteger(term)
+		if !ok || number == nil {
+			return args, errFormat
+		}
+		base := 10
+		if c.character != 'd' && c.precision > 0 {
+			base = c.precision
+		}
+		if base < 2 || base > 36 {
+			return args, errFormat
+		}
+		digits := number.Text(base)
+		switch c.character {
+		case 'B', '#':
+			digits = strings.ToUpper(digits)
+		}
+		if c.character == 'x' || c.character == 'X' {
+			prefix, ok := arg()
+			if !ok {
+				return args, errFormat
+			}
+			prefixText, isText := chardata(prefix, "unicode")
+			if atom, isAtom := atomString(prefix); isAtom {
+				prefixText, isText = atom, true
+			}
+			if !isText {
+				return args, errFormat
+			}
+			if c.character == 'X' {
+				digits = strings.ToUpper(digits)
+			}
+			digits = insertPrefix(digits, prefixText)
+		} else if c.character == '#' || c.character == '+' {
+			digits = insertPrefix(digits, strconv.Itoa(base)+"#")
+		}
+		value = digits
+	case 'e', 'f', 'g':
+		term, ok := arg()
+		var number float64
+		switch v := term.(type) {
+		case float64:
+			number = v
+		case float32:
+			number = float64(v)
+		default:
+			ok = false
+		}
+		if !ok {
+			return args, errFormat
+		}
+		precision := c.precision
+		if precision < 0 {
+			precision = 6
+		}
+		switch c.character {
+		case 'e':
+			value = floatE(number, precision)
+		case 'f':
+			value = strconv.FormatFloat(number, 'f', precision, 64)
+		default:
+			value = floatG(number, precision)
+		}
+	default:
+		return args, errFormat
+	}
+	length := utf8.RuneCountInString(value)
+	if c.width > 0 && length < c.width {
+		padding := strings.Repeat(string(c.pad), c.width-length)
+		if c.left {
+			value += padding
+		} else {
+			value = padding + value
+		}
+	}
+	text.WriteString(value)
+	return args, nil
+}
+
+func bigInteger(term interface{}) *big.Int {
+	if value, ok := term.(*big.Int); ok {
+		return value
+	}
+	value, ok := integer(term)
+	if !ok {
+		return nil
+	}
+	return big.NewInt(value)
+}
+
+// insertPrefix adds the prefix after the sign
+func insertPrefix(digits, prefix string) string {
+	if strings.HasPrefix(digits, "-") {
+		return "-" + prefix + digits[1:]
+	}
+	return prefix + digits
+}
+
+// floatE provides the ~e format with precision significant digits
+// (the exponent has no leading zeros, e.g., 1.00000e+0)
+func floatE(number float64, precision int) string {
+	if precision < 2 {
+		precision = 2
+	}
+	value := strconv.FormatFloat(number, 'e', precision-1, 64)
+	i := strings.LastIndexAny(value, "+-") + 1
+	exponent := strings.TrimLeft(value[i:], "0")
+	if len(exponent) == 0 {
+		exponent = "0"
+	}
+	return value[:i] + exponent
+}
+
+// floatG provides the ~g format, the ~f format if 0.1 <= abs(number) < 10000
+// with precision significant digits, otherwise the ~e format
+func floatG(number float64, precision int) string {
+	magnitude := abs(number)
+	var exponent int
+	switch {
+	case magnitude < 1.0e-1:
+		exponent = -2
+	case magnitude < 1.0e0:
+		exponent = -1
+	case magnitude < 1.0e1:
+		exponent = 0
+	case magnitude < 1.0e2:
+		exponent = 1
+	case magnitude < 1.0e3:
+		exponent = 2
+	case magnitude < 1.0e4:
+		exponent = 3
+	default:
+		return floatE(number, precision)
+	}
+	switch {
+	case (precision <= 1 && exponent == -1) ||
+		(precision-1 > exponent && exponent >= -1):
+		return strconv.FormatFloat(number, 'f', precision-1-exponent, 64)
+	case precision <= 1:
+		return floatE(number, 2)
+	default:
+		return floatE(number, precision)
+	}
+}
+
+func abs(value float64) float64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,176 @@
+package otp
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func TestIOServerPutChars(t *testing.T) {
+	var output bytes.Buffer
+	s := NewIOServer(nil, &output)
+	pid := erlang.OtpErlangPid{NodeTag: 100, Node: []byte("\x00\x0dnonode@nohost"),
+		ID: []byte{0, 0, 0, 83}, Serial: []byte{0, 0, 0, 0}, Creation: []byte{0}}
+	replyAs := erlang.OtpErlangReference{NodeTag: 100, Node: []byte("\x00\x0dnonode@nohost"),
+		ID: []byte{0, 0, 0, 1}, Creation: []byte{0}}
+	request := erlang.OtpErlangTuple{erlang.OtpErlangAtom("io_request"), pid, replyAs,
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("put_chars"),
+			erlang.OtpErlangAtom("unicode"),
+			erlang.OtpErlangBinary{Value: []byte("h\xc3\xa9llo\n"), Bits: 8}}}
+	to, reply, ok := s.Handle(roundTrip(t, request))
+	assertEqual(t, true, ok, "")
+	assertEqual(t, pid, to, "")
+	assertEqual(t, IOReply(replyAs, erlang.OtpErlangAtom("ok")), reply, "")
+	assertEqual(t, "héllo\n", output.String(), "")
+
+	// latin1 binary and the old request form
+	output.Reset()
+	assertEqual(t, erlang.OtpErlangAtom("ok"), s.Request(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("put_chars"),
+		erlang.OtpErlangBinary{Value: []byte("\xe9"), Bits: 8}}), "")
+	assertEqual(t, "é", output.String(), "")
+
+	// io:format("~s ~w ~5.2f|~-4b|~c~n", ["abc", {ok, 1}, 3.14159, 5, $x])
+	output.Reset()
+	format := erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("put_chars"), erlang.OtpErlangAtom("unicode"),
+		erlang.OtpErlangAtom("io_lib"), erlang.OtpErlangAtom("format"),
+		erlang.OtpErlangList{Value: []interface{}{
+			"~s ~w ~5.2f|~-4b|~c~n",
+			erlang.OtpErlangList{Value: []interface{}{
+				"abc",
+				erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), uint8(1)},
+				3.14159, uint8(5), uint8('x')}}}}}
+	assertEqual(t, erlang.OtpErlangAtom("ok"), s.Request(roundTrip(t, format)), "")
+	assertEqual(t, "abc {ok,1}  3.14|5   |x\n", output.String(), "")
+
+	// bad format arguments
+	format[4] = erlang.OtpErlangList{Value: []interface{}{"~s ~s", "a"}}
+	assertEqual(t, ioError("format"), s.Request(format), "")
+	assertEqual(t, ioError("request"),
+		s.Request(erlang.OtpErlangTuple{erlang.OtpErlangAtom("unknown")}), "")
+}
+
+func TestIOServerGet(t *testing.T) {
+	var output bytes.Buffer
+	s := NewIOServer(strings.NewReader("line one\nl\xc3\xafne two\nabc"), &output)
+	prompt := erlang.OtpErlangAtom("> ")
+	assertEqual(t, "line one\n", s.Request(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("get_line"), erlang.OtpErlangAtom("unicode"), prompt}), "")
+	assertEqual(t, "> ", output.String(), "")
+	assertEqual(t, erlang.OtpErlangAtom("ok"), s.Request(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("setopts"), erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangAtom("binary")}}}), "")
+	assertEqual(t, erlang.OtpErlangList{Value: []interface{}{
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("binary"), true},
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("encoding"), erlang.OtpErlangAtom("unicode")},
+	}}, s.Request(erlang.OtpErlangAtom("getopts")), "")
+	assertEqual(t, erlang.OtpErlangBinary{Value: []byte("l\xc3\xafne two\n"), Bits: 8},
+		s.Request(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("get_until"), erlang.OtpErlangAtom("unicode"), prompt,
+			erlang.OtpErlangAtom("io_lib"), erlang.OtpErlangAtom("collect_line"),
+			erlang.OtpErlangList{}}), "")
+	assertEqual(t, erlang.OtpErlangBinary{Value: []byte("ab"), Bits: 8},
+		s.Request(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("get_chars"), erlang.OtpErlangAtom("unicode"),
+			prompt, uint8(2)}), "")
+	assertEqual(t, ioError("enotsup"), s.Request(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("get_until"), erlang.OtpErlangAtom("unicode"), prompt,
+		erlang.OtpErlangAtom("erl_scan"), erlang.OtpErlangAtom("tokens"),
+		erlang.OtpErlangList{Value: []interface{}{uint8(1)}}}), "")
+	s.GetUntil = func(module, function string, args []interface{}, input *bufio.Reader) interface{} {
+		character, _ := input.ReadByte()
+		return erlang.OtpErlangTuple{erlang.OtpErlangAtom(module + ":" + function), character}
+	}
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("erl_scan:tokens"), byte('c')},
+		s.Request(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("get_until"), erlang.OtpErlangAtom("unicode"), prompt,
+			erlang.OtpErlangAtom("erl_scan"), erlang.OtpErlangAtom("tokens"),
+			erlang.OtpErlangList{Value: []interface{}{uint8(1)}}}), "")
+	assertEqual(t, erlang.OtpErlangAtom("eof"), s.Request(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("get_line"), prompt}), "")
+}
+
+func TestIOServerRequests(t *testing.T) {
+	var output bytes.Buffer
+	s := NewIOServer(nil, &output)
+	putChars := erlang.OtpErlangTuple{erlang.OtpErlangAtom("put_chars"),
+		erlang.OtpErlangAtom("latin1"), "ab"}
+	assertEqual(t, erlang.OtpErlangAtom("ok"), s.Request(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("requests"),
+		erlang.OtpErlangList{Value: []interface{}{putChars, putChars}}}), "")
+	assertEqual(t, "abab", output.String(), "")
+	output.Reset()
+	assertEqual(t, ioError("enotsup"), s.Request(erlang.OtpErlangTuple{
+		erlang.OtpErlangAtom("requests"),
+		erlang.OtpErlangList{Value: []interface{}{
+			putChars,
+			erlang.OtpErlangTuple{erlang.OtpErlangAtom("setopts"),
+				erlang.OtpErlangList{Value: []interface{}{erlang.OtpErlangAtom("echo")}}},
+			putChars}}}), "")
+	assertEqual(t, "ab", output.String(), "")
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		args   []interface{}
+		expect string
+	}{
+		{"~~ ~10.3.0f", []interface{}{1.5}, "~ 000001.500"},
+		{"~.16B ~.16b ~.16# ~.16X", []interface{}{uint8(255), int32(-255), uint8(255), uint8(255), "0x"},
+			"FF -ff 16#FF 0xFF"},
+		{"~4.2c|~-6s|~.2s", []interface{}{uint8('a'), erlang.OtpErlangAtom("ok"), "abc"},
+			"  aa|ok    |ab"},
+		{"~p ~i~w", []interface{}{erlang.OtpErlangList{Value: []interface{}{uint8(1)}}, uint8(2), nil},
+			"[1] undefined"},
+		{"~*.*.xs", []interface{}{uint8(5), uint8(2), "abc"}, "xxxab"},
+		// ~+ is ~# with lowercase digits
+		{"~.16+ ~+", []interface{}{uint8(255), int32(-10)}, "16#ff -10#10"},
+		// the ~c count defaults to the field width
+		{"~5c|~-3c|~c", []interface{}{uint8('a'), uint8('b'), uint8('c')},
+			"aaaaa|bbb|c"},
+		// the ~e exponent has no leading zeros
+		{"~e ~.3e ~e ~e", []interface{}{1.0, 12345.678, 1.0e-10, -2.5e100},
+			"1.00000e+0 1.23e+4 1.00000e-10 -2.50000e+100"},
+		{"~g ~g ~g ~g", []interface{}{0.05, 0.5, 123.0, 12345.0},
+			"5.00000e-2 0.500000 123.000 1.23450e+4"},
+	}
+	for _, test := range tests {
+		args := erlang.OtpErlangList{Value: test.args}
+		result, err := Format(test.format, args)
+		assertEqual(t, nil, err, test.format)
+		assertEqual(t, test.expect, result, test.format)
+	}
+	_, err := Format("~z", erlang.OtpErlangList{})
+	assertEqual(t, errFormat, err, "")
+	_, err = Format("~w", erlang.OtpErlangList{})
+	assertEqual(t, errFormat, err, "")
+}
@@ -0,0 +1,276 @@
+// Package port provides the runtime of an Erlang port program that uses
+// open_port/2 with the {packet, N} and binary options
+package port
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+//
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// Port errors
+var (
+	ErrPacket     = errors.New("packet in [1, 2, 4]")
+	ErrPacketSize = errors.New("packet size overflow")
+)
+
+// Error provides an Erlang term as the reason of an error reply
+type Error struct {
+	Reason interface{}
+}
+
+func (e *Error) Error() string {
+	return "port error"
+}
+
+// Handler processes a request term and provides the reply term
+type Handler func(request interface{}) (interface{}, error)
+
+// Port handles requests from an Erlang port, with each request
+// providing a single reply
+type Port struct {
+	reader        io.Reader
+	writer        io.Writer
+	packet        int
+	handlers      map[string]Handler
+	handlersMutex sync.Mutex
+	mutex         sync.Mutex
+}
+
+// New creates a Port using the {packet, N} length header size
+func New(reader io.Reader, writer io.Writer, packet int) (*Port, error) {
+	err := packetCheck(packet)
+	if err != nil {
+		return nil, err
+	}
+	return &Port{
+		reader:   reader,
+		writer:   writer,
+		packet:   packet,
+		handlers: make(map[string]Handler),
+	}, nil
+}
+
+// NewStdio creates a Port that uses stdin and stdout
+func NewStdio(packet int) (*Port, error) {
+	return New(os.Stdin, os.Stdout, packet)
+}
+
+// NewNouseStdio creates a Port that uses file descriptors 3 and 4
+// (for the open_port/2 nouse_stdio option)
+func NewNouseStdio(packet int) (*Port, error) {
+	return New(os.NewFile(3, "port_input"), os.NewFile(4, "port_output"), packet)
+}
+
+// Handle registers the handler for requests that are the atom name
+// or a tuple with the atom name as the first element
+func (p *Port) Handle(name string, handler Handler) {
+	p.handlersMutex.Lock()
+	defer p.handlersMutex.Unlock()
+	p.handlers[name] = handler
+}
+
+// Serve handles requests until the Erlang port is closed
+// (nil is returned when the input ends between requests)
+//
+// A reply is {ok, Reply} with the handler reply or {error, Reason}:
+//   - {badarg, Message} when the request is not a valid term
+//   - {undef, Name} when no handler is registered
+//   - Error.Reason or the error message when the handler fails
+func (p *Port) Serve() error {
+	for {
+		data, err := ReadPacket(p.reader, p.packet)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		err = p.Send(p.request(data))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Send provides a term to the Erlang port owner
+func (p *Port) Send(term interface{}) error {
+	data, err := erlang.TermToBinary(term, -1)
+	if err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return WritePacket(p.writer, p.packet, data)
+}
+
+func (p *Port) request(data []byte) interface{} {
+	request, err := erlang.BinaryToTerm(data)
+	if err != nil {
+		return ReplyError(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("badarg"), []byte(err.Error())})
+	}
+	name, ok := RequestName(request)
+	if !ok {
+		return ReplyError(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("badarg"), []byte("invalid request")})
+	}
+	p.handlersMutex.Lock()
+	handler, ok := p.handlers[name]
+	p.handlersMutex.Unlock()
+	if !ok {
+		return ReplyError(erlang.OtpErlangTuple{
+			erlang.OtpErlangAtom("undef"), erlang.OtpErlangAtom(name)})
+	}
+	return Reply(handler, request)
+}
+
+// ReadPacket reads the data of a single packet with a packet byte
+// length header
+func ReadPacket(reader io.Reader, packet int) ([]byte, error) {
+	err := packetCheck(packet)
+	if err != nil {
+		return nil, err
+	}
+	var header [4]byte
+	_, err = io.ReadFull(reader, header[:packet])
+	if err != nil {
+		return nil, err
+	}
+	var length uint32
+	switch packet {
+	case 1:
+		length = uint32(header[0])
+	case 2:
+		length = uint32(binary.BigEndian.Uint16(header[:2]))
+	case 4:
+		length = binary.BigEndian.Uint32(header[:4])
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(reader, data)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// WritePacket writes the data as a single packet with a packet byte
+// length header
+func WritePacket(writer io.Writer, packet int, data []byte) error {
+	err := packetCheck(packet)
+	if err != nil {
+		return err
+	}
+	length := uint64(len(data))
+	var header [4]byte
+	switch packet {
+	case 1:
+		if length > math.MaxUint8 {
+			return ErrPacketSize
+		}
+		header[0] = uint8(length)
+	case 2:
+		if length > math.MaxUint16 {
+			return ErrPacketSize
+		}
+		binary.BigEndian.PutUint16(header[:2], uint16(length))
+	case 4:
+		if length > math.MaxUint32 {
+			return ErrPacketSize
+		}
+		binary.BigEndian.PutUint32(header[:4], uint32(length))
+	}
+	_, err = writer.Write(append(header[:packet], data...))
+	return err
+}
+
+// RequestName provides the atom name of a request that is the atom
+// or a tuple with the atom as the first element
+func RequestName(request interface{}) (string, bool) {
+	if tuple, ok := request.(erlang.OtpErlangTuple); ok {
+		if len(tuple) == 0 {
+			return "", false
+		}
+		request = tuple[0]
+	}
+	return atomName(request)
+}
+
+func atomName(term interface{}) (string, bool) {
+	switch name := term.(type) {
+	case erlang.OtpErlangAtom:
+		return string(name), true
+	case erlang.OtpErlangAtomUTF8:
+		return string(name), true
+	case bool:
+		if name {
+			return "true", true
+		}
+		return "false", true
+	default:
+		return "", false
+	}
+}
+
+// Reply provides the {ok, Reply} term of the handler reply or
+// the {error, Reason} term of the handler error
+// (Error.Reason or the error message)
+func Reply(handler Handler, request interface{}) interface{} {
+	reply, err := handler(request)
+	if err != nil {
+		var reason *Error
+		if errors.As(err, &reason) {
+			return ReplyError(reason.Reason)
+		}
+		return ReplyError([]byte(err.Error()))
+	}
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), reply}
+}
+
+// ReplyError provides the {error, Reason} term
+func ReplyError(reason interface{}) interface{} {
+	return erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"), reason}
+}
+
+func packetCheck(packet int) error {
+	switch packet {
+	case 1, 2, 4:
+		return nil
+	default:
+		return ErrPacket
+	}
+}
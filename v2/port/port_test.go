@@ -0,0 +1,139 @@
+package port
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func TestPacket(t *testing.T) {
+	for _, packet := range []int{1, 2, 4} {
+		buffer := new(bytes.Buffer)
+		assertEqual(t, nil, WritePacket(buffer, packet, []byte("abc")), "")
+		assertEqual(t, packet+3, buffer.Len(), "")
+		data, err := ReadPacket(buffer, packet)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, []byte("abc"), data, "")
+		_, err = ReadPacket(buffer, packet)
+		assertEqual(t, io.EOF, err, "")
+	}
+	_, err := ReadPacket(bytes.NewReader([]byte("\x00\x05abc")), 2)
+	assertEqual(t, io.ErrUnexpectedEOF, err, "")
+	assertEqual(t, ErrPacketSize, WritePacket(new(bytes.Buffer), 1, make([]byte, 256)), "")
+	assertEqual(t, ErrPacket, WritePacket(new(bytes.Buffer), 3, nil), "")
+	_, err = New(nil, nil, 8)
+	assertEqual(t, ErrPacket, err, "")
+}
+
+func TestPortServe(t *testing.T) {
+	requestReader, requestWriter, err := os.Pipe()
+	assertEqual(t, nil, err, "")
+	replyReader, replyWriter, err := os.Pipe()
+	assertEqual(t, nil, err, "")
+	p, err := New(requestReader, replyWriter, 4)
+	assertEqual(t, nil, err, "")
+	p.Handle("add", func(request interface{}) (interface{}, error) {
+		args := request.(erlang.OtpErlangTuple)
+		return int(args[1].(uint8)) + int(args[2].(uint8)), nil
+	})
+	p.Handle("fail", func(request interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	p.Handle("reason", func(request interface{}) (interface{}, error) {
+		return nil, &Error{Reason: erlang.OtpErlangAtom("einval")}
+	})
+	done := make(chan error)
+	go func() {
+		done <- p.Serve()
+	}()
+	call := func(request interface{}) interface{} {
+		data, err := erlang.TermToBinary(request, -1)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, nil, WritePacket(requestWriter, 4, data), "")
+		data, err = ReadPacket(replyReader, 4)
+		assertEqual(t, nil, err, "")
+		reply, err := erlang.BinaryToTerm(data)
+		assertEqual(t, nil, err, "")
+		return reply
+	}
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("ok"), uint8(5)},
+		call(erlang.OtpErlangTuple{erlang.OtpErlangAtom("add"), 2, 3}), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"), erlang.OtpErlangBinary{Value: []byte("failed"), Bits: 8}},
+		call(erlang.OtpErlangAtom("fail")), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"), erlang.OtpErlangAtom("einval")},
+		call(erlang.OtpErlangTuple{erlang.OtpErlangAtom("reason")}), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"), erlang.OtpErlangTuple{erlang.OtpErlangAtom("undef"), erlang.OtpErlangAtom("missing")}},
+		call(erlang.OtpErlangAtom("missing")), "")
+	assertEqual(t, erlang.OtpErlangTuple{erlang.OtpErlangAtom("error"), erlang.OtpErlangTuple{erlang.OtpErlangAtom("badarg"), erlang.OtpErlangBinary{Value: []byte("invalid request"), Bits: 8}}},
+		call(uint8(1)), "")
+	assertEqual(t, nil, p.Send(erlang.OtpErlangAtom("event")), "")
+	data, err := ReadPacket(replyReader, 4)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x83s\x05event", string(data), "")
+	requestWriter.Close()
+	assertEqual(t, nil, <-done, "")
+	replyWriter.Close()
+	requestReader.Close()
+	replyReader.Close()
+}
+
+func TestRequestName(t *testing.T) {
+	name, ok := RequestName(erlang.OtpErlangTuple{erlang.OtpErlangAtom("add"), uint8(1)})
+	assertEqual(t, "add", name, "")
+	assertEqual(t, true, ok, "")
+	name, ok = RequestName(erlang.OtpErlangAtomUTF8("add"))
+	assertEqual(t, "add", name, "")
+	assertEqual(t, true, ok, "")
+	_, ok = RequestName(erlang.OtpErlangTuple{})
+	assertEqual(t, false, ok, "")
+	_, ok = RequestName("add")
+	assertEqual(t, false, ok, "")
+	// only an atom directly at element 0 names the request
+	_, ok = RequestName(erlang.OtpErlangTuple{
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("add")}, uint8(1)})
+	assertEqual(t, false, ok, "")
+	_, ok = RequestName(erlang.OtpErlangTuple{erlang.OtpErlangTuple{
+		erlang.OtpErlangTuple{erlang.OtpErlangAtom("add")}}})
+	assertEqual(t, false, ok, "")
+}
@@ -0,0 +1,408 @@
+package sext
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"reflect"
+	"unicode/utf8"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+type decoder struct {
+	data []byte
+}
+
+func (d *decoder) byte() (byte, error) {
+	if len(d.data) == 0 {
+		return 0, ErrInvalid
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b, nil
+}
+
+func (d *decoder) bytes(size int) ([]byte, error) {
+	if len(d.data) < size {
+		return nil, ErrInvalid
+	}
+	value := d.data[:size:size]
+	d.data = d.data[size:]
+	return value, nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	value, err := d.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(value), nil
+}
+
+func (d *decoder) term() (interface{}, error) {
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case tagPos4, tagNeg4:
+		value, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		integer := big.NewInt(int64(int32(value) >> 1))
+		if tag == tagPos4 {
+			integer.SetInt64(int64(value >> 1))
+		}
+		return d.number(integer, value&1 == 1)
+	case tagPosBig, tagNegBig:
+		return d.bignum(tag == tagNegBig)
+	case tagAtom:
+		name, err := d.binary()
+		if err != nil {
+			return nil, err
+		}
+		return atom(string(name)), nil
+	case tagReference:
+		node, err := d.node()
+		if err != nil {
+			return nil, err
+		}
+		creation, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		id, err := d.binary()
+		if err != nil {
+			return nil, err
+		}
+		if len(id)%4 != 0 {
+			return nil, ErrInvalid
+		}
+		return erlang.OtpErlangReference{NodeTag: tagSmallAtomUTF8Ext,
+			Node: node, ID: id, Creation: creation}, nil
+	case tagPort:
+		node, err := d.node()
+		if err != nil {
+			return nil, err
+		}
+		id, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		creation, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return erlang.OtpErlangPort{NodeTag: tagSmallAtomUTF8Ext,
+			Node: node, ID: id, Creation: creation}, nil
+	case tagPid:
+		node, err := d.node()
+		if err != nil {
+			return nil, err
+		}
+		values, err := d.bytes(12)
+		if err != nil {
+			return nil, err
+		}
+		return erlang.OtpErlangPid{NodeTag: tagSmallAtomUTF8Ext,
+			Node: node, ID: values[0:4:4], Serial: values[4:8:8],
+			Creation: values[8:12:12]}, nil
+	case tagTuple:
+		size, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(size) > uint64(len(d.data)) {
+			return nil, ErrInvalid
+		}
+		tuple := make(erlang.OtpErlangTuple, size)
+		for i := range tuple {
+			tuple[i], err = d.term()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return tuple, nil
+	case tagMap:
+		size, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(size) > uint64(len(d.data)) {
+			return nil, ErrInvalid
+		}
+		pairs := make(erlang.OtpErlangMap, size)
+		for i := uint32(0); i < size; i++ {
+			key, err := d.term()
+			if err != nil {
+				return nil, err
+			}
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				return nil, ErrTerm
+			}
+			value, err := d.term()
+			if err != nil {
+				return nil, err
+			}
+			pairs[key] = value
+		}
+		return pairs, nil
+	case tagNil:
+		return erlang.OtpErlangList{Value: []interface{}{}}, nil
+	case tagList:
+		return d.list()
+	case tagBinary:
+		value, err := d.binary()
+		if err != nil {
+			return nil, err
+		}
+		return erlang.OtpErlangBinary{Value: value, Bits: 8}, nil
+	default:
+		return nil, ErrInvalid
+	}
+}
+
+func (d *decoder) list() (interface{}, error) {
+	var elements []interface{}
+	for {
+		if len(d.data) == 0 {
+			return nil, ErrInvalid
+		}
+		switch d.data[0] {
+		case listEnd:
+			d.data = d.data[1:]
+			if len(elements) == 0 {
+				return nil, ErrInvalid
+			}
+			if len(elements) <= math.MaxUint16 {
+				// lists of bytes are STRING_EXT, as with erlang.BinaryToTerm
+				characters := make([]byte, len(elements))
+				for i, element := range elements {
+					character, ok := element.(uint8)
+					if !ok {
+						return erlang.OtpErlangList{Value: elements}, nil
+					}
+					characters[i] = character
+				}
+				return string(characters), nil
+			}
+			return erlang.OtpErlangList{Value: elements}, nil
+		case listImproper, tagBinTail:
+			if len(elements) == 0 {
+				return nil, ErrInvalid
+			}
+			var tail interface{}
+			if d.data[0] == listImproper {
+				d.data = d.data[1:]
+				var err error
+				tail, err = d.term()
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := tail.(erlang.OtpErlangList); ok {
+					return nil, ErrInvalid
+				}
+			} else {
+				d.data = d.data[1:]
+				value, err := d.binary()
+				if err != nil {
+					return nil, err
+				}
+				tail = erlang.OtpErlangBinary{Value: value, Bits: 8}
+			}
+			return erlang.OtpErlangList{Value: append(elements, tail),
+				Improper: true}, nil
+		default:
+			element, err := d.term()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+	}
+}
+
+// binary reads bytes with a 1 bit prefix, the zero padding and the end byte
+func (d *decoder) binary() ([]byte, error) {
+	if len(d.data) > 0 && d.data[0] == binaryEnd {
+		d.data = d.data[1:]
+		return []byte{}, nil
+	}
+	var value []byte
+	i := 0         // byte index
+	bit := uint(0) // bit index in the byte (from the most significant bit)
+	readBit := func() (byte, bool) {
+		if i >= len(d.data) {
+			return 0, false
+		}
+		b := (d.data[i] >> (7 - bit)) & 1
+		bit += 1
+		if bit == 8 {
+			bit = 0
+			i += 1
+		}
+		return b, true
+	}
+	for {
+		flag, ok := readBit()
+		if !ok {
+			return nil, ErrInvalid
+		}
+		if flag == 0 {
+			break
+		}
+		var b byte
+		for j := 0; j < 8; j++ {
+			next, ok := readBit()
+			if !ok {
+				return nil, ErrInvalid
+			}
+			b = b<<1 | next
+		}
+		value = append(value, b)
+	}
+	if len(value) == 0 {
+		return nil, ErrInvalid
+	}
+	for bit != 0 {
+		pad, ok := readBit()
+		if !ok || pad != 0 {
+			return nil, ErrInvalid
+		}
+	}
+	if i >= len(d.data) || d.data[i] != binaryEnd {
+		return nil, ErrInvalid
+	}
+	d.data = d.data[i+1:]
+	return value, nil
+}
+
+// node reads the node atom as SMALL_ATOM_UTF8_EXT data
+func (d *decoder) node() ([]byte, error) {
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	if tag != tagAtom {
+		return nil, ErrInvalid
+	}
+	name, err := d.binary()
+	if err != nil {
+		return nil, err
+	}
+	if len(name) > math.MaxUint8 {
+		return nil, ErrInvalid
+	}
+	return append([]byte{byte(len(name))}, name...), nil
+}
+
+func (d *decoder) bignum(negative bool) (interface{}, error) {
+	var invert byte
+	if negative {
+		invert = 0xff
+	}
+	first, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	size := int(first ^ invert)
+	if size > 127 {
+		rest, err := d.bytes(3)
+		if err != nil {
+			return nil, err
+		}
+		size = int(binary.BigEndian.Uint32([]byte{
+			(first ^ invert) & 0x7f, rest[0] ^ invert,
+			rest[1] ^ invert, rest[2] ^ invert}))
+	}
+	magnitude, err := d.bytes(size)
+	if err != nil {
+		return nil, err
+	}
+	value := make([]byte, size)
+	for i, b := range magnitude {
+		value[i] = b ^ invert
+	}
+	integer := new(big.Int).SetBytes(value)
+	if negative {
+		integer.Neg(integer)
+	}
+	flag, err := d.byte()
+	if err != nil || flag > 1 {
+		return nil, ErrInvalid
+	}
+	return d.number(integer, flag == 1)
+}
+
+// number provides the integer (with the same Go types as
+// erlang.BinaryToTerm) or the float with the fraction that follows
+func (d *decoder) number(integer *big.Int, float bool) (interface{}, error) {
+	if !float {
+		if integer.IsInt64() {
+			value := integer.Int64()
+			switch {
+			case value >= 0 && value <= math.MaxUint8:
+				return uint8(value), nil
+			case value >= math.MinInt32 && value <= math.MaxInt32:
+				return int32(value), nil
+			}
+		}
+		return integer, nil
+	}
+	fraction, err := d.binary()
+	if err != nil {
+		return nil, err
+	}
+	value := new(big.Float).SetPrec(2048).SetInt(integer)
+	if len(fraction) > 0 {
+		numerator := new(big.Float).SetPrec(2048).SetInt(
+			new(big.Int).SetBytes(fraction))
+		numerator.SetMantExp(numerator, -8*len(fraction))
+		value.Add(value, numerator)
+	}
+	result, _ := value.Float64()
+	return result, nil
+}
+
+func atom(name string) interface{} {
+	switch name {
+	case "true":
+		return true
+	case "false":
+		return false
+	case erlang.Undefined():
+		return nil
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] >= utf8.RuneSelf {
+			return erlang.OtpErlangAtomUTF8(name)
+		}
+	}
+	return erlang.OtpErlangAtom(name)
+}
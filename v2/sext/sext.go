@@ -0,0 +1,526 @@
+// Package sext provides a sortable external term encoding based on
+// the design of the Erlang sext library
+// (the encoding preserves the Erlang term order but has not been verified
+// to be byte-compatible with sext:encode/1)
+package sext
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+// sext tags in Erlang term order
+const (
+	tagNegBig    = 8
+	tagNeg4      = 9
+	tagPos4      = 10
+	tagPosBig    = 11
+	tagAtom      = 12
+	tagReference = 13
+	tagPort      = 14
+	tagPid       = 15
+	tagTuple     = 16
+	tagMap       = 17
+	tagNil       = 18
+	tagList      = 19
+	tagBinary    = 20
+	tagBinTail   = 21
+	listEnd      = 2
+	listImproper = 1
+	binaryEnd    = 8
+
+	tagSmallAtomUTF8Ext = 119
+)
+
+// sext errors
+var (
+	ErrTerm    = errors.New("term not supported")
+	ErrInvalid = errors.New("sext data invalid")
+)
+
+// Encode provides the sext encoding of a term
+// (the byte order of encoded terms is the Erlang term order)
+func Encode(term interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := encode(term, &buffer)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Prefix provides the sext encoding of a term up to the first wildcard
+// ('_' or '$N' atoms) for use as a range scan prefix,
+// similar to sext:prefix/1
+func Prefix(term interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	_, err := prefix(term, &buffer)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decode provides the term of sext data, with the same Go types
+// as erlang.BinaryToTerm
+func Decode(data []byte) (interface{}, error) {
+	term, rest, err := DecodeNext(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, ErrInvalid
+	}
+	return term, nil
+}
+
+// DecodeNext provides the first term of sext data and the remaining data
+func DecodeNext(data []byte) (interface{}, []byte, error) {
+	d := decoder{data: data}
+	term, err := d.term()
+	if err != nil {
+		return nil, nil, err
+	}
+	return term, d.data, nil
+}
+
+// Encode implementation functions
+
+func encode(termI interface{}, buffer *bytes.Buffer) error {
+	switch term := termI.(type) {
+	case nil:
+		encodeAtom(erlang.Undefined(), buffer)
+	case bool:
+		encodeAtom(strconv.FormatBool(term), buffer)
+	case erlang.OtpErlangAtom:
+		encodeAtom(string(term), buffer)
+	case erlang.OtpErlangAtomUTF8:
+		encodeAtom(string(term), buffer)
+	case uint8:
+		encodeInteger(big.NewInt(int64(term)), nil, buffer)
+	case uint16:
+		encodeInteger(big.NewInt(int64(term)), nil, buffer)
+	case uint32:
+		encodeInteger(big.NewInt(int64(term)), nil, buffer)
+	case uint64:
+		encodeInteger(new(big.Int).SetUint64(term), nil, buffer)
+	case int8:
+		encodeInteger(big.NewInt(int64(term)), nil, buffer)
+	case int16:
+		encodeInteger(big.NewInt(int64(term)), nil, buffer)
+	case int32:
+		encodeInteger(big.NewInt(int64(term)), nil, buffer)
+	case int64:
+		encodeInteger(big.NewInt(term), nil, buffer)
+	case int:
+		encodeInteger(big.NewInt(int64(term)), nil, buffer)
+	case *big.Int:
+		encodeInteger(term, nil, buffer)
+	case float32:
+		return encodeFloat(float64(term), buffer)
+	case float64:
+		return encodeFloat(term, buffer)
+	case []byte:
+		buffer.WriteByte(tagBinary)
+		encodeBinary(term, buffer)
+	case erlang.OtpErlangBinary:
+		if term.Bits != 8 {
+			return ErrTerm
+		}
+		buffer.WriteByte(tagBinary)
+		encodeBinary(term.Value, buffer)
+	case string:
+		if len(term) == 0 {
+			buffer.WriteByte(tagNil)
+			return nil
+		}
+		buffer.WriteByte(tagList)
+		for i := 0; i < len(term); i++ {
+			encodeInteger(big.NewInt(int64(term[i])), nil, buffer)
+		}
+		buffer.WriteByte(listEnd)
+	case erlang.OtpErlangTuple:
+		return encodeTuple(term, buffer)
+	case []interface{}:
+		return encodeTuple(term, buffer)
+	case erlang.OtpErlangList:
+		return encodeList(term, buffer)
+	case erlang.OtpErlangMap:
+		return encodeMap(term, buffer)
+	case map[interface{}]interface{}:
+		return encodeMap(term, buffer)
+	case erlang.OtpErlangPid:
+		buffer.WriteByte(tagPid)
+		encodeAtom(erlang.NodeName(term.NodeTag, term.Node), buffer)
+		if len(term.ID) != 4 || len(term.Serial) != 4 {
+			return ErrTerm
+		}
+		buffer.Write(term.ID)
+		buffer.Write(term.Serial)
+		return encodeCreation(term.Creation, buffer)
+	case erlang.OtpErlangPort:
+		buffer.WriteByte(tagPort)
+		encodeAtom(erlang.NodeName(term.NodeTag, term.Node), buffer)
+		switch len(term.ID) {
+		case 4:
+			buffer.Write([]byte{0, 0, 0, 0})
+			buffer.Write(term.ID)
+		case 8:
+			buffer.Write(term.ID)
+		default:
+			return ErrTerm
+		}
+		return encodeCreation(term.Creation, buffer)
+	case erlang.OtpErlangReference:
+		buffer.WriteByte(tagReference)
+		encodeAtom(erlang.NodeName(term.NodeTag, term.Node), buffer)
+		err := encodeCreation(term.Creation, buffer)
+		if err != nil {
+			return err
+		}
+		encodeBinary(term.ID, buffer)
+	default:
+		return ErrTerm
+	}
+	return nil
+}
+
+func encodeAtom(name string, buffer *bytes.Buffer) {
+	buffer.WriteByte(tagAtom)
+	encodeBinary([]byte(name), buffer)
+}
+
+// encodeBinary writes each byte with a 1 bit prefix, then zero bits to
+// the next byte boundary (at least 1 bit) and the end byte
+func encodeBinary(data []byte, buffer *bytes.Buffer) {
+	var bits uint16
+	var count uint
+	for _, b := range data {
+		bits = bits<<9 | 0x100 | uint16(b)
+		count += 9
+		for count >= 8 {
+			count -= 8
+			buffer.WriteByte(byte(bits >> count))
+		}
+		bits &= 1<<count - 1
+	}
+	if len(data) > 0 {
+		// zero padding of 8 - (len(data) rem 8) bits
+		buffer.WriteByte(byte(bits << (8 - count)))
+	}
+	buffer.WriteByte(binaryEnd)
+}
+
+func encodeCreation(creation []byte, buffer *bytes.Buffer) error {
+	switch len(creation) {
+	case 1:
+		buffer.Write([]byte{0, 0, 0, creation[0]})
+	case 4:
+		buffer.Write(creation)
+	default:
+		return ErrTerm
+	}
+	return nil
+}
+
+func encodeTuple(elements []interface{}, buffer *bytes.Buffer) error {
+	buffer.WriteByte(tagTuple)
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(elements)))
+	buffer.Write(size[:])
+	for _, element := range elements {
+		err := encode(element, buffer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeList(list erlang.OtpErlangList, buffer *bytes.Buffer) error {
+	if len(list.Value) == 0 {
+		buffer.WriteByte(tagNil)
+		return nil
+	}
+	buffer.WriteByte(tagList)
+	elements := list.Value
+	if list.Improper {
+		elements = elements[:len(elements)-1]
+	}
+	for _, element := range elements {
+		err := encode(element, buffer)
+		if err != nil {
+			return err
+		}
+	}
+	if !list.Improper {
+		buffer.WriteByte(listEnd)
+		return nil
+	}
+	tail := list.Value[len(list.Value)-1]
+	if encodeBinaryTail(tail, buffer) {
+		return nil
+	}
+	buffer.WriteByte(listImproper)
+	return encode(tail, buffer)
+}
+
+// encodeBinaryTail writes the bin_tail of an improper list with a
+// binary tail, which is greater than a list tail in Erlang term order
+func encodeBinaryTail(tail interface{}, buffer *bytes.Buffer) bool {
+	switch term := tail.(type) {
+	case []byte:
+		buffer.WriteByte(tagBinTail)
+		encodeBinary(term, buffer)
+		return true
+	case erlang.OtpErlangBinary:
+		if term.Bits != 8 {
+			return false
+		}
+		buffer.WriteByte(tagBinTail)
+		encodeBinary(term.Value, buffer)
+		return true
+	default:
+		return false
+	}
+}
+
+func encodeMap(pairs map[interface{}]interface{}, buffer *bytes.Buffer) error {
+	type pair struct {
+		key   []byte
+		value interface{}
+	}
+	sorted := make([]pair, 0, len(pairs))
+	for key, value := range pairs {
+		encoded, err := Encode(key)
+		if err != nil {
+			return err
+		}
+		sorted = append(sorted, pair{key: encoded, value: value})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].key, sorted[j].key) < 0
+	})
+	buffer.WriteByte(tagMap)
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(sorted)))
+	buffer.Write(size[:])
+	for _, p := range sorted {
+		buffer.Write(p.key)
+		err := encode(p.value, buffer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeInteger writes an integer with an optional fraction
+// (the fraction bits of a float, or an empty fraction for an integral float)
+//
+// pos4/neg4 contain the 31 bit two's complement integer with a fraction bit,
+// posbig/negbig contain the size and magnitude bytes with a fraction byte
+// (inverted for negbig)
+func encodeInteger(integer *big.Int, fraction []byte, buffer *bytes.Buffer) {
+	var fractionFlag uint32
+	if fraction != nil {
+		fractionFlag = 1
+	}
+	switch {
+	case integer.Sign() >= 0 && integer.Cmp(big.NewInt(math.MaxInt32)) <= 0:
+		buffer.WriteByte(tagPos4)
+		var value [4]byte
+		binary.BigEndian.PutUint32(value[:], uint32(integer.Int64())<<1|fractionFlag)
+		buffer.Write(value[:])
+	case integer.Sign() < 0 && integer.Cmp(big.NewInt(-math.MaxInt32)) >= 0:
+		buffer.WriteByte(tagNeg4)
+		var value [4]byte
+		binary.BigEndian.PutUint32(value[:], uint32(integer.Int64())<<1|fractionFlag)
+		buffer.Write(value[:])
+	case integer.Sign() > 0:
+		buffer.WriteByte(tagPosBig)
+		magnitude := integer.Bytes()
+		encodeSize(len(magnitude), buffer)
+		buffer.Write(magnitude)
+		buffer.WriteByte(byte(fractionFlag))
+	default:
+		buffer.WriteByte(tagNegBig)
+		magnitude := new(big.Int).Neg(integer).Bytes()
+		start := buffer.Len()
+		encodeSize(len(magnitude), buffer)
+		buffer.Write(magnitude)
+		data := buffer.Bytes()
+		for i := start; i < len(data); i++ {
+			data[i] = ^data[i]
+		}
+		buffer.WriteByte(byte(fractionFlag))
+	}
+	if fraction != nil {
+		encodeBinary(fraction, buffer)
+	}
+}
+
+func encodeSize(size int, buffer *bytes.Buffer) {
+	if size > 127 {
+		var value [4]byte
+		binary.BigEndian.PutUint32(value[:], uint32(size)|0x80000000)
+		buffer.Write(value[:])
+	} else {
+		buffer.WriteByte(byte(size))
+	}
+}
+
+// encodeFloat writes the floor of the float as an integer with
+// the remaining fraction bits
+// (both are taken exactly from the IEEE 754 mantissa and exponent)
+func encodeFloat(value float64, buffer *bytes.Buffer) error {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return ErrTerm
+	}
+	bits := math.Float64bits(value)
+	exponent := int((bits >> 52) & 0x7ff)
+	mantissa := bits & (1<<52 - 1)
+	if exponent == 0 {
+		exponent = 1
+	} else {
+		mantissa |= 1 << 52
+	}
+	exponent -= 1075
+	integer := new(big.Int).SetUint64(mantissa)
+	if bits>>63 == 1 {
+		integer.Neg(integer)
+	}
+	fraction := []byte{}
+	if exponent >= 0 {
+		integer.Lsh(integer, uint(exponent))
+	} else {
+		// value is integer / 2^scale with an arithmetic shift as the floor
+		scale := uint(-exponent)
+		remainder := new(big.Int).Set(integer)
+		integer.Rsh(integer, scale)
+		remainder.Sub(remainder, new(big.Int).Lsh(integer, scale))
+		if remainder.Sign() != 0 {
+			size := (scale + 7) / 8
+			remainder.Lsh(remainder, size*8-scale)
+			fraction = remainder.FillBytes(make([]byte, size))
+			fraction = bytes.TrimRight(fraction, "\x00")
+		}
+	}
+	encodeInteger(integer, fraction, buffer)
+	return nil
+}
+
+// prefix writes the encoding up to the first wildcard
+// (false is returned when a wildcard was found)
+func prefix(termI interface{}, buffer *bytes.Buffer) (bool, error) {
+	if isWildcard(termI) {
+		return false, nil
+	}
+	switch term := termI.(type) {
+	case erlang.OtpErlangTuple:
+		return prefixTuple(term, buffer)
+	case []interface{}:
+		return prefixTuple(term, buffer)
+	case erlang.OtpErlangList:
+		if len(term.Value) == 0 {
+			buffer.WriteByte(tagNil)
+			return true, nil
+		}
+		buffer.WriteByte(tagList)
+		elements := term.Value
+		if term.Improper {
+			elements = elements[:len(elements)-1]
+		}
+		for _, element := range elements {
+			complete, err := prefix(element, buffer)
+			if err != nil || !complete {
+				return complete, err
+			}
+		}
+		if !term.Improper {
+			buffer.WriteByte(listEnd)
+			return true, nil
+		}
+		tail := term.Value[len(term.Value)-1]
+		if isWildcard(tail) {
+			return false, nil
+		}
+		if encodeBinaryTail(tail, buffer) {
+			return true, nil
+		}
+		buffer.WriteByte(listImproper)
+		return prefix(tail, buffer)
+	default:
+		return true, encode(termI, buffer)
+	}
+}
+
+func prefixTuple(elements []interface{}, buffer *bytes.Buffer) (bool, error) {
+	buffer.WriteByte(tagTuple)
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(elements)))
+	buffer.Write(size[:])
+	for _, element := range elements {
+		complete, err := prefix(element, buffer)
+		if err != nil || !complete {
+			return complete, err
+		}
+	}
+	return true, nil
+}
+
+// isWildcard checks for the '_' atom or a '$N' atom
+func isWildcard(term interface{}) bool {
+	var name string
+	switch atom := term.(type) {
+	case erlang.OtpErlangAtom:
+		name = string(atom)
+	case erlang.OtpErlangAtomUTF8:
+		name = string(atom)
+	default:
+		return false
+	}
+	if name == "_" {
+		return true
+	}
+	if len(name) < 2 || name[0] != '$' {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if name[i] < '0' || name[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
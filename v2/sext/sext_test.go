@@ -0,0 +1,223 @@
+package sext
+
+//-*-Mode:Go;coding:utf-8;tab-width:4;c-basic-offset:4-*-
+// ex: set ft=go fenc=utf-8 sts=4 ts=4 sw=4 noet nomod:
+//
+// MIT License
+//
+// Copyright (c) 2026 Michael Truog <mjtruog at protonmail dot com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING
+// FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER
+// DEALINGS IN THE SOFTWARE.
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/okeuday/erlang_go/v2/erlang"
+)
+
+func assertEqual(t *testing.T, expect interface{}, result interface{}, message string) {
+	if reflect.DeepEqual(expect, result) {
+		return
+	}
+	t.Helper()
+	if len(message) == 0 {
+		t.Fatalf("%#v != %#v", expect, result)
+	} else {
+		t.Fatalf("%#v != %#v (%s)", expect, result, message)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		term   interface{}
+		expect string
+	}{
+		{uint8(1), "\x0a\x00\x00\x00\x02"},
+		{int32(-1), "\x09\xff\xff\xff\xfe"},
+		{erlang.OtpErlangAtom("a"), "\x0c\xb0\x80\x08"},
+		{erlang.OtpErlangBinary{Value: []byte("a"), Bits: 8}, "\x14\xb0\x80\x08"},
+		{[]byte{}, "\x14\x08"},
+		{[]byte("abcdefgh"), "\x14\xb0\xd8\xac\x76\x4b\x2d\x9a\xcf\x68\x00\x08"},
+		{erlang.OtpErlangTuple{uint8(1), uint8(2)},
+			"\x10\x00\x00\x00\x02\x0a\x00\x00\x00\x02\x0a\x00\x00\x00\x04"},
+		{erlang.OtpErlangList{Value: []interface{}{}}, "\x12"},
+		{"a", "\x13\x0a\x00\x00\x00\xc2\x02"},
+		{erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangAtom("a"), erlang.OtpErlangAtom("b")}, Improper: true},
+			"\x13\x0c\xb0\x80\x08\x01\x0c\xb1\x00\x08"},
+		{1.5, "\x0a\x00\x00\x00\x03\xc0\x00\x08"},
+	}
+	for _, test := range tests {
+		data, err := Encode(test.term)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, test.expect, string(data), erlang.TermString(test.term))
+	}
+}
+
+func TestOrder(t *testing.T) {
+	bignum, _ := new(big.Int).SetString("100000000000000000000", 10)
+	bignumNegative := new(big.Int).Neg(bignum)
+	pid := func(id byte) erlang.OtpErlangPid {
+		return erlang.OtpErlangPid{NodeTag: 119, Node: []byte("\x0dnonode@nohost"),
+			ID: []byte{0, 0, 0, id}, Serial: []byte{0, 0, 0, 0},
+			Creation: []byte{0, 0, 0, 0}}
+	}
+	// in Erlang term order
+	terms := []interface{}{
+		new(big.Int).Mul(bignumNegative, big.NewInt(256)),
+		bignumNegative,
+		-1.0e20 + 0.5e5,
+		int32(-3),
+		-2.5,
+		int32(-2),
+		-1.5,
+		int32(-1),
+		-0.25,
+		-0.1,
+		-1.0e-10,
+		-1.0e-300,
+		-5.0e-324,
+		uint8(0),
+		5.0e-324,
+		1.0e-300,
+		0.1,
+		0.25,
+		uint8(1),
+		1.5,
+		int32(2147483647),
+		bignum,
+		erlang.OtpErlangAtom("a"),
+		erlang.OtpErlangAtom("ab"),
+		erlang.OtpErlangAtom("b"),
+		erlang.OtpErlangReference{NodeTag: 119, Node: []byte("\x0dnonode@nohost"),
+			ID: []byte{0, 0, 0, 1}, Creation: []byte{0, 0, 0, 0}},
+		erlang.OtpErlangPort{NodeTag: 119, Node: []byte("\x0dnonode@nohost"),
+			ID: []byte{0, 0, 0, 0, 0, 0, 0, 1}, Creation: []byte{0, 0, 0, 0}},
+		pid(1),
+		pid(2),
+		erlang.OtpErlangTuple{},
+		erlang.OtpErlangTuple{uint8(2)},
+		erlang.OtpErlangTuple{uint8(1), uint8(2)},
+		erlang.OtpErlangMap{},
+		erlang.OtpErlangMap{erlang.OtpErlangAtom("a"): uint8(2)},
+		erlang.OtpErlangList{Value: []interface{}{}},
+		"abc",
+		erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangAtom("a"), erlang.OtpErlangAtom("b")}, Improper: true},
+		erlang.OtpErlangList{Value: []interface{}{erlang.OtpErlangAtom("a")}},
+		erlang.OtpErlangList{Value: []interface{}{
+			erlang.OtpErlangAtom("a"), erlang.OtpErlangAtom("b")}},
+		erlang.OtpErlangList{Value: []interface{}{erlang.OtpErlangAtom("a"),
+			erlang.OtpErlangBinary{Value: []byte{}, Bits: 8}}, Improper: true},
+		erlang.OtpErlangBinary{Value: []byte{}, Bits: 8},
+		erlang.OtpErlangBinary{Value: []byte("a"), Bits: 8},
+		erlang.OtpErlangBinary{Value: []byte("a\x00"), Bits: 8},
+		erlang.OtpErlangBinary{Value: []byte("abcdefghi"), Bits: 8},
+		erlang.OtpErlangBinary{Value: []byte("b"), Bits: 8},
+	}
+	var previous []byte
+	for i, term := range terms {
+		data, err := Encode(term)
+		assertEqual(t, nil, err, erlang.TermString(term))
+		if previous != nil && bytes.Compare(previous, data) >= 0 {
+			t.Fatalf("%s <= %s", erlang.TermString(terms[i-1]),
+				erlang.TermString(term))
+		}
+		previous = data
+		result, err := Decode(data)
+		assertEqual(t, nil, err, erlang.TermString(term))
+		assertEqual(t, term, result, erlang.TermString(term))
+	}
+}
+
+func TestFloat(t *testing.T) {
+	values := []float64{
+		0.0, -0.1, 0.1, -1.0e-300, 1.0e-300, -5.0e-324, 5.0e-324,
+		-2.2250738585072014e-308, 1.0 / 3.0, -1.0 / 3.0, 1.0e300, -1.0e300,
+		-123456.789, 1.7976931348623157e308, -1.7976931348623157e308,
+	}
+	for _, value := range values {
+		data, err := Encode(value)
+		assertEqual(t, nil, err, "")
+		result, err := Decode(data)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, value, result, "")
+	}
+}
+
+func TestUndefined(t *testing.T) {
+	erlang.SetUndefined("nil")
+	defer erlang.SetUndefined("undefined")
+	data, err := Encode(nil)
+	assertEqual(t, nil, err, "")
+	expect, err := Encode(erlang.OtpErlangAtom("nil"))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, expect, data, "")
+	result, err := Decode(data)
+	assertEqual(t, nil, err, "")
+	assertEqual(t, nil, result, "")
+}
+
+func TestPrefix(t *testing.T) {
+	key := func(table string, id interface{}) interface{} {
+		return erlang.OtpErlangTuple{erlang.OtpErlangAtom(table), id}
+	}
+	prefix, err := Prefix(key("users", erlang.OtpErlangAtom("_")))
+	assertEqual(t, nil, err, "")
+	for _, term := range []interface{}{
+		key("users", uint8(1)), key("users", "name"),
+		key("users", erlang.OtpErlangTuple{uint8(1)})} {
+		data, err := Encode(term)
+		assertEqual(t, nil, err, "")
+		assertEqual(t, true, bytes.HasPrefix(data, prefix), "")
+	}
+	data, err := Encode(key("user", uint8(1)))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, false, bytes.HasPrefix(data, prefix), "")
+
+	// a list prefix has no end
+	prefix, err = Prefix(erlang.OtpErlangList{Value: []interface{}{
+		uint8(1), erlang.OtpErlangAtom("$1")}})
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x13\x0a\x00\x00\x00\x02", string(prefix), "")
+	prefix, err = Prefix(uint8(1))
+	assertEqual(t, nil, err, "")
+	assertEqual(t, "\x0a\x00\x00\x00\x02", string(prefix), "")
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"\x07",
+		"\x0a\x00\x00",
+		"\x14\xb0\x80",
+		"\x14\xb0\x81\x08",
+		"\x13\x02",
+		"\x13\x0a\x00\x00\x00\x02",
+		"\x10\xff\xff\xff\xff",
+		"\x0a\x00\x00\x00\x02\x00",
+	} {
+		_, err := Decode([]byte(data))
+		assertEqual(t, ErrInvalid, err, data)
+	}
+	_, err := Encode(erlang.OtpErlangFunction{})
+	assertEqual(t, ErrTerm, err, "")
+}